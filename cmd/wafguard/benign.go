@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"wafguard/internal/benign"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/logger"
+	"wafguard/internal/reporter"
+	"wafguard/internal/runner"
+	"wafguard/internal/validator"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benignCorpusFormat string
+	benignTarget       string
+	benignBlockStatus  []int
+	benignConcurrent   int
+	benignRPS          float64
+	benignMaxRate      float64
+	benignFormat       string
+	benignOutput       string
+)
+
+func newBenignCmd() *cobra.Command {
+	benignCmd := &cobra.Command{
+		Use:   "benign [corpus]",
+		Short: "Measure false positives by replaying legitimate traffic",
+		Long: `Measure false positives by replaying legitimate traffic.
+
+The corpus is a HAR capture, a common or combined format access log, or a
+list of URLs (one per line, optionally preceded by a method). Every request
+is expected to be allowed. The report shows the false-positive rate overall
+and per endpoint (method and path, with IDs collapsed to {id}), and for each
+blocked request the status, identifying response headers, rule IDs found
+in the block page and an excerpt of it.
+
+The command fails when the false-positive rate exceeds --max-fp-rate.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runBenign,
+	}
+
+	benignCmd.Flags().StringVar(&benignCorpusFormat, "corpus-format", benign.FormatAuto, "Corpus format (auto, har, log, urls)")
+	benignCmd.Flags().StringVarP(&benignTarget, "target", "t", "", "Base URL to replay against (required for access logs)")
+	benignCmd.Flags().IntSliceVar(&benignBlockStatus, "block-status", nil, "Statuses that mean the WAF blocked a request (default 403)")
+	benignCmd.Flags().IntVarP(&benignConcurrent, "concurrent", "c", 1, "Number of concurrent requests")
+	benignCmd.Flags().Float64Var(&benignRPS, "rps", 0, "Maximum requests per second per target host (0 means no limit)")
+	benignCmd.Flags().Float64Var(&benignMaxRate, "max-fp-rate", 0, "Highest acceptable false-positive rate in percent")
+	benignCmd.Flags().StringVarP(&benignFormat, "format", "F", "text", "Output format (json, text)")
+	benignCmd.Flags().StringVarP(&benignOutput, "output", "o", "", "Also save the report as JSON to this file")
+	benignCmd.Flags().StringVarP(&logLevel, "log-level", "l", "warn", "Log level (debug, info, warn, error)")
+	benignCmd.Flags().StringVarP(&logFormat, "log-format", "f", "text", "Log format (json, text)")
+
+	return benignCmd
+}
+
+func runBenign(cmd *cobra.Command, args []string) error {
+	setupReportLogger(cmd, benignFormat)
+
+	result, err := benign.LoadCorpus(args[0], benignCorpusFormat, benignTarget)
+	if err != nil {
+		return err
+	}
+	for _, note := range result.Unsupported {
+		logger.Warn("Skipped: " + note)
+	}
+	if result.Suite == nil {
+		return fmt.Errorf("no requests found in %s", args[0])
+	}
+
+	target := result.Suite.Spec.Target
+	if len(benignBlockStatus) > 0 {
+		target.BlockStatus = benignBlockStatus
+	}
+
+	logger.WithFields(logrus.Fields{
+		"corpus":   args[0],
+		"requests": len(result.Suite.Spec.Tests),
+		"target":   target.BaseURL,
+	}).Info("Replaying benign traffic")
+
+	rateLimiters := executor.NewRateLimiters()
+	if benignRPS > 0 {
+		rateLimiters.SetDefault(config.RateLimit{RPS: benignRPS})
+	}
+	testRunner := runner.NewRunner(
+		executor.NewHTTPExecutor(target.Timeout, executor.WithRateLimiters(rateLimiters)),
+		validator.NewResponseValidator(),
+		reporter.NewReporter("text", ""),
+	)
+
+	reports := replayBenign(cmd.Context(), testRunner, result.Suite.Spec.Tests, target, benignConcurrent)
+	report := benign.Analyze(reports, validator.NewResponseValidator().WithBlockStatus(target.BlockStatus))
+
+	if err := printBenignReport(cmd.OutOrStdout(), report); err != nil {
+		return err
+	}
+
+	if benignOutput != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		if err := os.WriteFile(benignOutput, data, 0644); err != nil {
+			return fmt.Errorf("failed to write report to file: %w", err)
+		}
+	}
+
+	if cmd.Context().Err() != nil {
+		return fmt.Errorf("interrupted after %d of %d requests", report.Requests+report.Errors, len(result.Suite.Spec.Tests))
+	}
+	if report.Rate > benignMaxRate {
+		return fmt.Errorf("false-positive rate %.2f%% exceeds %.2f%%", report.Rate, benignMaxRate)
+	}
+	return nil
+}
+
+// replayBenign runs the tests with up to concurrent workers and returns
+// their reports in test order. Requests that fail get a report without a
+// response.
+func replayBenign(ctx context.Context, testRunner *runner.Runner, tests []config.Test, target config.Target, concurrent int) []reporter.TestReport {
+	if concurrent < 1 {
+		concurrent = 1
+	}
+
+	reports := make([]reporter.TestReport, len(tests))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrent; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				test := &tests[i]
+				report, err := testRunner.RunTest(ctx, test, target)
+				if err != nil {
+					logger.WithFields(logrus.Fields{
+						"test_name": test.Name,
+						"error":     err.Error(),
+					}).Warn("Benign request failed")
+					report = &reporter.TestReport{TestName: test.Name, Status: reporter.StatusFail, Request: &test.Request}
+				}
+				reports[i] = *report
+			}
+		}()
+	}
+
+	sent := 0
+	for i := range tests {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+		sent++
+	}
+	close(jobs)
+	wg.Wait()
+
+	return reports[:sent]
+}
+
+func printBenignReport(w io.Writer, report *benign.Report) error {
+	if benignFormat == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	fmt.Fprintf(w, "Requests: %d\n", report.Requests)
+	fmt.Fprintf(w, "Blocked: %d\n", report.Blocked)
+	if report.Errors > 0 {
+		fmt.Fprintf(w, "Request Errors: %d\n", report.Errors)
+	}
+	fmt.Fprintf(w, "False-Positive Rate: %.2f%%\n", report.Rate)
+	fmt.Fprintln(w, "====================================")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENDPOINT\tREQUESTS\tBLOCKED\tFP RATE")
+	for _, endpoint := range report.Endpoints {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f%%\n", endpoint.Endpoint, endpoint.Requests, endpoint.Blocked, endpoint.Rate)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, fp := range report.FalsePositives {
+		fmt.Fprintln(w, "---")
+		fmt.Fprintf(w, "False Positive: %s %s\n", fp.Request.Method, fp.Request.Path)
+		fmt.Fprintf(w, "Status: %d\n", fp.StatusCode)
+		names := make([]string, 0, len(fp.Headers))
+		for name := range fp.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "Header: %s: %s\n", name, fp.Headers[name])
+		}
+		if len(fp.RuleIDs) > 0 {
+			fmt.Fprintf(w, "Rule IDs: %s\n", strings.Join(fp.RuleIDs, ", "))
+		}
+		if fp.Body != "" {
+			fmt.Fprintf(w, "Body: %s\n", fp.Body)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"wafguard/internal/importer"
+	"wafguard/internal/logger"
+	"wafguard/internal/parser"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertOutput string
+	convertTarget string
+	convertName   string
+	convertExpect string
+	harExpect     string
+	harHost       string
+)
+
+func newConvertCmd() *cobra.Command {
+	convertCmd := &cobra.Command{
+		Use:   "convert",
+		Short: "Convert requests into SentinelTest YAML",
+		Long:  "Convert requests captured by other tools into a SentinelTest YAML file",
+	}
+
+	curlCmd := &cobra.Command{
+		Use:   "curl [command]",
+		Short: "Convert curl command lines",
+		Long: `Convert one or more curl command lines into a SentinelTest suite.
+
+The command can be passed as a single quoted argument, after "--", or on
+stdin (one command per line, backslash continuations allowed):
+
+  wafguard convert curl "curl -d 'id=1 OR 1=1' https://example.com/login"
+  wafguard convert curl -- curl -H 'X-Api: 1' https://example.com/
+  pbpaste | wafguard convert curl -o finding.yaml`,
+		RunE: convertCurl,
+	}
+
+	harCmd := &cobra.Command{
+		Use:   "har [file]",
+		Short: "Convert HAR captures",
+		Long: `Convert the requests in a HAR capture (e.g. exported from browser devtools)
+into a SentinelTest suite, one test per entry.
+
+By default every test expects the request to be allowed, which suits
+false-positive testing with real traffic. Use --expect recorded to expect
+the status recorded in the capture instead.`,
+		Args: cobra.ExactArgs(1),
+		RunE: convertHAR,
+	}
+
+	convertCmd.PersistentFlags().StringVarP(&convertOutput, "output", "o", "", "File to write the SentinelTest YAML to (defaults to stdout)")
+	convertCmd.PersistentFlags().StringVarP(&convertTarget, "target", "t", "", "Base URL for the converted tests (defaults to the URL in the source)")
+	convertCmd.PersistentFlags().StringVarP(&convertName, "name", "n", "", "Suite name (defaults to one derived from the first request)")
+	convertCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Log level (debug, info, warn, error)")
+	convertCmd.PersistentFlags().StringVarP(&logFormat, "log-format", "f", "text", "Log format (json, text)")
+
+	curlCmd.Flags().StringVarP(&convertExpect, "expect", "e", "blocked", "Expectation for every test: blocked, allowed, or comma-separated status codes")
+
+	harCmd.Flags().StringVarP(&harExpect, "expect", "e", "allowed", "Expectation for every test: allowed, blocked, recorded, or comma-separated status codes")
+	harCmd.Flags().StringVar(&harHost, "host", "", "Only convert requests to this host")
+
+	convertCmd.AddCommand(curlCmd)
+	convertCmd.AddCommand(harCmd)
+
+	return convertCmd
+}
+
+func convertCurl(cmd *cobra.Command, args []string) error {
+	setupLogger()
+
+	expected, err := importer.ParseExpectation(convertExpect)
+	if err != nil {
+		return err
+	}
+
+	input, err := curlInput(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	result, err := importer.ImportCurl(input, importer.CurlOptions{
+		Name:     convertName,
+		BaseURL:  convertTarget,
+		Expected: expected,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to convert curl command: %w", err)
+	}
+
+	return writeConvertResult(cmd, result)
+}
+
+func convertHAR(cmd *cobra.Command, args []string) error {
+	setupLogger()
+
+	opts := importer.HAROptions{
+		Name:    convertName,
+		BaseURL: convertTarget,
+		Host:    harHost,
+	}
+
+	if harExpect == "recorded" {
+		opts.UseRecordedStatus = true
+	} else {
+		expected, err := importer.ParseExpectation(harExpect)
+		if err != nil {
+			return err
+		}
+		opts.Expected = expected
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", args[0], err)
+	}
+
+	results, err := importer.ImportHAR(data, args[0], opts)
+	if err != nil {
+		return err
+	}
+
+	if len(results) > 1 {
+		origins := make([]string, len(results))
+		for i, result := range results {
+			origins[i] = result.Suite.Spec.Target.BaseURL
+		}
+		return fmt.Errorf("capture contains requests to %d origins (%s): use --host to pick one or --target to send all of them to one target",
+			len(results), strings.Join(origins, ", "))
+	}
+
+	return writeConvertResult(cmd, results[0])
+}
+
+// curlInput returns the curl command(s) from the arguments, or stdin when
+// there are none.
+func curlInput(cmd *cobra.Command, args []string) (string, error) {
+	switch {
+	case len(args) == 0 || (len(args) == 1 && args[0] == "-"):
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return string(data), nil
+	case len(args) == 1:
+		return args[0], nil
+	default:
+		// Already split by the shell, re-quote so the words survive
+		// splitting again.
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		return strings.Join(quoted, " "), nil
+	}
+}
+
+// writeConvertResult validates a converted suite and writes it to
+// convertOutput, or stdout. Skipped input is reported on stderr so it never
+// mixes with YAML written to stdout.
+func writeConvertResult(cmd *cobra.Command, result *importer.Result) error {
+	for _, note := range result.Unsupported {
+		fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s\n", note)
+	}
+
+	if result.Suite == nil {
+		return fmt.Errorf("nothing to convert in %s", result.Source)
+	}
+
+	data, err := importer.Marshal(result.Suite)
+	if err != nil {
+		return err
+	}
+	if _, err := parser.NewParser().ParseYAML(data); err != nil {
+		return fmt.Errorf("converted suite is invalid: %w", err)
+	}
+
+	if convertOutput == "" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(convertOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", convertOutput, err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"file":  convertOutput,
+		"tests": len(result.Suite.Spec.Tests),
+	}).Info("Converted tests written to file")
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/fuzz"
+	"wafguard/internal/logger"
+	"wafguard/internal/parser"
+	"wafguard/internal/validator"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fuzzTest        string
+	fuzzSeed        int64
+	fuzzBudget      int
+	fuzzDepth       int
+	fuzzTransforms  []string
+	fuzzTarget      string
+	fuzzBlockStatus []int
+	fuzzFormat      string
+	fuzzRPS         float64
+)
+
+func newFuzzCmd() *cobra.Command {
+	fuzzCmd := &cobra.Command{
+		Use:   "fuzz [file or directory]",
+		Short: "Search for WAF bypasses of blocked injection tests",
+		Long: `Search for WAF bypasses of blocked injection tests.
+
+Only tests expanded from an inject block are fuzzed, since the payload and
+its location are what the transforms re-encode; other tests in the file or
+directory are skipped. To fuzz a value of a plain test, move it into an
+inject block with that value as the only payload.
+
+Each selected test is sent once as written; if the WAF blocks it, its payload is re-encoded with chains of
+evasion transforms, shortest chains first, until the WAF lets a variant
+through or the request budget is spent. Every bypass is reported with the
+shortest chain that achieved it. The same --seed tries chains in the same
+order, and a reported chain reproduces exactly when added to the test's
+transforms.
+
+The command fails when a bypass is found.`,
+		Args: cobra.ExactArgs(1),
+		RunE: fuzzTests,
+	}
+
+	fuzzCmd.Flags().StringVarP(&fuzzTest, "test", "T", "", "Only fuzz this test, by expanded name or inject test name (default all injected tests)")
+	fuzzCmd.Flags().Int64Var(&fuzzSeed, "seed", 1, "Seed for the order in which transform chains are tried")
+	fuzzCmd.Flags().IntVar(&fuzzBudget, "budget", fuzz.DefaultBudget, "Maximum requests per test, including the unmodified one")
+	fuzzCmd.Flags().IntVar(&fuzzDepth, "depth", fuzz.DefaultMaxDepth, "Maximum number of transforms in a chain")
+	fuzzCmd.Flags().StringSliceVar(&fuzzTransforms, "transforms", nil, "Transforms to combine (default all)")
+	fuzzCmd.Flags().StringVarP(&fuzzTarget, "target", "t", "", "Base URL to fuzz, overriding spec.target.baseUrl")
+	fuzzCmd.Flags().IntSliceVar(&fuzzBlockStatus, "block-status", nil, "Statuses that mean the WAF blocked a request, overriding spec.target.blockStatus (default 403)")
+	fuzzCmd.Flags().StringVarP(&fuzzFormat, "format", "F", "text", "Output format (json, text)")
+	fuzzCmd.Flags().Float64Var(&fuzzRPS, "rps", 0, "Maximum requests per second per target host (0 means no limit)")
+	fuzzCmd.Flags().StringVarP(&logLevel, "log-level", "l", "warn", "Log level (debug, info, warn, error)")
+	fuzzCmd.Flags().StringVarP(&logFormat, "log-format", "f", "text", "Log format (json, text)")
+
+	return fuzzCmd
+}
+
+func fuzzTests(cmd *cobra.Command, args []string) error {
+	setupReportLogger(cmd, fuzzFormat)
+
+	p := parser.NewParser()
+	var suites []*config.SentinelTest
+	if isDirectory(args[0]) {
+		parsed, err := p.ParseDirectory(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse tests: %w", err)
+		}
+		suites = parsed
+	} else {
+		suite, err := p.ParseFile(args[0])
+		if err != nil {
+			return err
+		}
+		suites = []*config.SentinelTest{suite}
+	}
+
+	// Shared by every suite, as for run, so suites on one host share its
+	// request budget
+	rateLimiters := executor.NewRateLimiters()
+	if fuzzRPS > 0 {
+		rateLimiters.SetDefault(config.RateLimit{RPS: fuzzRPS})
+	}
+
+	var results []*fuzz.Result
+	bypasses := 0
+	for _, suite := range suites {
+		target := suite.Spec.Target
+		if fuzzTarget != "" {
+			target.BaseURL = fuzzTarget
+		}
+		if len(fuzzBlockStatus) > 0 {
+			target.BlockStatus = fuzzBlockStatus
+		}
+		if err := rateLimiters.Configure(target); err != nil {
+			return fmt.Errorf("failed to configure rate limit for %s: %w", suite.Metadata.Name, err)
+		}
+
+		tests, err := selectFuzzTests(suite.Spec.Tests, fuzzTest)
+		if err != nil {
+			return err
+		}
+		if skipped := len(suite.Spec.Tests) - countInjected(suite.Spec.Tests); fuzzTest == "" && skipped > 0 {
+			logger.WithFields(logrus.Fields{
+				"suite":   suite.Metadata.Name,
+				"skipped": skipped,
+			}).Info("Skipping tests without an inject block")
+		}
+		if len(tests) == 0 {
+			continue
+		}
+
+		httpExecutor := executor.NewHTTPExecutor(target.Timeout, executor.WithRateLimiters(rateLimiters))
+		fuzzer := fuzz.NewFuzzer(httpExecutor, validator.NewResponseValidator(), fuzz.Options{
+			Seed:       fuzzSeed,
+			Budget:     fuzzBudget,
+			MaxDepth:   fuzzDepth,
+			Transforms: fuzzTransforms,
+		})
+
+		for i := range tests {
+			logger.WithFields(logrus.Fields{
+				"test_name": tests[i].Name,
+				"budget":    fuzzBudget,
+				"seed":      fuzzSeed,
+			}).Info("Fuzzing test")
+
+			result, err := fuzzer.Fuzz(cmd.Context(), &tests[i], target)
+			if err != nil {
+				return fmt.Errorf("test %s: %w", tests[i].Name, err)
+			}
+			results = append(results, result)
+			bypasses += len(result.Bypasses)
+
+			if cmd.Context().Err() != nil {
+				break
+			}
+		}
+	}
+
+	if len(results) == 0 {
+		if fuzzTest != "" {
+			return fmt.Errorf("no injected test named %s", fuzzTest)
+		}
+		return fmt.Errorf("no injected tests found; fuzz needs tests with an inject block")
+	}
+
+	if err := printFuzzResults(cmd.OutOrStdout(), results); err != nil {
+		return err
+	}
+	if bypasses > 0 {
+		return fmt.Errorf("found %d bypass(es)", bypasses)
+	}
+	return nil
+}
+
+// selectFuzzTests returns the tests matching name, either by their own
+// name or by the name of the inject test they were expanded from. With an
+// empty name every injected test is returned.
+func selectFuzzTests(tests []config.Test, name string) ([]config.Test, error) {
+	var selected []config.Test
+	for _, test := range tests {
+		if name == "" {
+			if test.Injection != nil {
+				selected = append(selected, test)
+			}
+			continue
+		}
+
+		if test.Name == name && test.Injection == nil {
+			return nil, fmt.Errorf("test %s has no inject block; fuzz needs to know where the payload is", name)
+		}
+		if test.Name == name || (test.Injection != nil && test.Injection.Test == name) {
+			selected = append(selected, test)
+		}
+	}
+	return selected, nil
+}
+
+func countInjected(tests []config.Test) int {
+	count := 0
+	for _, test := range tests {
+		if test.Injection != nil {
+			count++
+		}
+	}
+	return count
+}
+
+func printFuzzResults(w io.Writer, results []*fuzz.Result) error {
+	if fuzzFormat == "json" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal fuzz results: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	for _, result := range results {
+		fmt.Fprintf(w, "Test: %s\n", result.Test)
+		fmt.Fprintf(w, "Payload: %q at %s\n", result.Payload, result.Location)
+		if !result.Blocked {
+			fmt.Fprintf(w, "Baseline: not blocked (status %d), nothing to fuzz\n", result.BaselineStatus)
+			fmt.Fprintln(w, "---")
+			continue
+		}
+
+		fmt.Fprintf(w, "Baseline: blocked (status %d)\n", result.BaselineStatus)
+		fmt.Fprintf(w, "Requests: %d (chains up to %d transforms)\n", result.Requests, result.Depth)
+		if result.Errors > 0 {
+			fmt.Fprintf(w, "Request Errors: %d\n", result.Errors)
+		}
+		if len(result.Bypasses) == 0 {
+			fmt.Fprintln(w, "Bypasses: none found")
+		}
+		for _, bypass := range result.Bypasses {
+			fmt.Fprintf(w, "Bypass: %s (status %d)\n", strings.Join(bypass.Chain, " | "), bypass.StatusCode)
+			fmt.Fprintf(w, "  Encoded: %q\n", bypass.Encoded)
+			fmt.Fprintf(w, "  Request: %s %s\n", bypass.Request.Method, bypass.Request.Path)
+		}
+		fmt.Fprintln(w, "---")
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"wafguard/internal/importer"
+	"wafguard/internal/logger"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generateOutputDir string
+	generateTarget    string
+	generatePayloads  string
+	generateExpect    string
+)
+
+func newGenerateCmd() *cobra.Command {
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate tests from API descriptions",
+		Long:  "Generate SentinelTest YAML files by injecting payloads into API descriptions",
+	}
+
+	openapiCmd := &cobra.Command{
+		Use:   "openapi [spec]",
+		Short: "Generate injection tests from an OpenAPI 3 document",
+		Long: `Generate injection tests from an OpenAPI 3 document (YAML or JSON).
+
+Every payload is placed into every path, query, header and cookie parameter
+and every string field of JSON request bodies, one at a time, with valid
+baseline values everywhere else. One suite is written per API path, and
+each test is tagged with the location it injects into (e.g. query:q).`,
+		Args: cobra.ExactArgs(1),
+		RunE: generateOpenAPI,
+	}
+
+	generateCmd.PersistentFlags().StringVarP(&generateOutputDir, "output-dir", "o", "generated", "Directory to write generated SentinelTest files to")
+	generateCmd.PersistentFlags().StringVarP(&generateTarget, "target", "t", "", "Base URL for the generated tests (defaults to the first server in the document)")
+	generateCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Log level (debug, info, warn, error)")
+	generateCmd.PersistentFlags().StringVarP(&logFormat, "log-format", "f", "text", "Log format (json, text)")
+
+	openapiCmd.Flags().StringVarP(&generatePayloads, "payloads", "p", "", "File with one payload per line (required)")
+	openapiCmd.Flags().StringVarP(&generateExpect, "expect", "e", "blocked", "Expectation for every test: blocked, allowed, or comma-separated status codes")
+	_ = openapiCmd.MarkFlagRequired("payloads")
+
+	generateCmd.AddCommand(openapiCmd)
+
+	return generateCmd
+}
+
+func generateOpenAPI(cmd *cobra.Command, args []string) error {
+	setupLogger()
+
+	expected, err := importer.ParseExpectation(generateExpect)
+	if err != nil {
+		return err
+	}
+
+	payloads, err := importer.ReadPayloadFile(generatePayloads)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", args[0], err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"spec":       args[0],
+		"payloads":   len(payloads),
+		"output_dir": generateOutputDir,
+	}).Info("Generating injection tests from OpenAPI document")
+
+	results, err := importer.GenerateOpenAPI(data, args[0], payloads, importer.OpenAPIOptions{
+		BaseURL:  generateTarget,
+		Expected: expected,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeSuites(generateOutputDir, results, func(result *importer.Result) string {
+		return result.Suite.Metadata.Name + ".yaml"
+	})
+}
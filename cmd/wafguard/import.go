@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"wafguard/internal/importer"
+	"wafguard/internal/logger"
+	"wafguard/internal/parser"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importOutputDir    string
+	importTarget       string
+	accessLogFormat    string
+	accessLogExpect    string
+	accessLogKeepDupes bool
+	nucleiExpect       string
+	ftwLogAsBlocked    bool
+)
+
+func newImportCmd() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import tests from other formats",
+		Long:  "Convert third-party test corpora into SentinelTest YAML files",
+	}
+
+	ftwCmd := &cobra.Command{
+		Use:   "ftw [directory]",
+		Short: "Import go-ftw / OWASP CRS regression tests",
+		Long: `Convert go-ftw regression test files (as shipped with the OWASP Core Rule Set) into SentinelTest YAML files.
+
+Stages that only expect a rule to show up in the WAF log (log_contains,
+expect_ids) are skipped by default: under anomaly scoring a single rule
+match does not block the request. --log-as-blocked imports them as
+blocked: true, for WAFs that block on every match.`,
+		Args: cobra.ExactArgs(1),
+		RunE: importFTW,
+	}
+	ftwCmd.Flags().BoolVar(&ftwLogAsBlocked, "log-as-blocked", false, "Import stages that expect rule hits in the WAF log as blocked: true")
+
+	accessLogCmd := &cobra.Command{
+		Use:   "accesslog [file...]",
+		Short: "Import benign requests from web server access logs",
+		Long: `Import benign requests from nginx or Apache access logs.
+
+Common and combined format lines are recognised by default. Other layouts
+are read with --line-format, given as an nginx log_format string
+('$remote_addr [$time_local] "$request" $status "$http_user_agent"') or an
+Apache LogFormat string ('%h %l %u %t "%r" %>s %b'). Logged request headers
+($http_*, %{Name}i) are replayed with each request.
+
+Requests are deduplicated by method, path shape (numeric, UUID and token
+segments collapsed) and query parameter names, keeping the first of each.
+Every test expects the request to be allowed. One suite is written per log
+file; --target is required because logs do not record the host.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: importAccessLog,
+	}
+	accessLogCmd.Flags().StringVar(&accessLogFormat, "line-format", "", "Log format: common, combined, or an nginx or Apache format string (default common or combined)")
+	accessLogCmd.Flags().StringVarP(&accessLogExpect, "expect", "e", "allowed", "Expectation for every test: blocked, allowed, or comma-separated status codes")
+	accessLogCmd.Flags().BoolVar(&accessLogKeepDupes, "keep-duplicates", false, "Keep every request instead of one per method and path shape")
+
+	nucleiCmd := &cobra.Command{
+		Use:   "nuclei [file or directory]",
+		Short: "Import the HTTP requests of nuclei templates",
+		Long: `Convert nuclei templates into SentinelTest YAML files, one per template.
+
+Structured (method/path) and raw requests are converted, with inline payload
+lists expanded using the template's attack type. Status, word and regex
+matchers on the response body become the test's expectation, so a test
+passes when the template would have matched; use --expect blocked to assert
+that the WAF stops the requests instead.
+
+Templates are converted as far as possible: other protocols, DSL helpers,
+interactsh URLs, extractors, payload files and unsupported matchers are
+listed per template in the log. --target is required because templates do
+not name a host.`,
+		Args: cobra.ExactArgs(1),
+		RunE: importNuclei,
+	}
+	nucleiCmd.Flags().StringVarP(&nucleiExpect, "expect", "e", "matchers", "Expectation for every test: matchers, blocked, allowed, or comma-separated status codes")
+
+	importCmd.PersistentFlags().StringVarP(&importOutputDir, "output-dir", "o", "imported", "Directory to write converted SentinelTest files to")
+	importCmd.PersistentFlags().StringVarP(&importTarget, "target", "t", "", "Base URL for the converted tests (defaults to the address in the source files)")
+	importCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Log level (debug, info, warn, error)")
+	importCmd.PersistentFlags().StringVarP(&logFormat, "log-format", "f", "text", "Log format (json, text)")
+
+	importCmd.AddCommand(ftwCmd)
+	importCmd.AddCommand(accessLogCmd)
+	importCmd.AddCommand(nucleiCmd)
+
+	return importCmd
+}
+
+func importFTW(cmd *cobra.Command, args []string) error {
+	setupLogger()
+
+	dir := args[0]
+	logger.WithFields(logrus.Fields{
+		"path":       dir,
+		"output_dir": importOutputDir,
+	}).Info("Importing go-ftw tests")
+
+	results, err := importer.ImportFTWDir(dir, importer.FTWOptions{
+		BaseURL:      importTarget,
+		LogAsBlocked: ftwLogAsBlocked,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Mirror the layout of the source directory
+	return writeSuites(importOutputDir, results, func(result *importer.Result) string {
+		rel, err := filepath.Rel(dir, result.Source)
+		if err != nil {
+			rel = filepath.Base(result.Source)
+		}
+		return strings.TrimSuffix(rel, filepath.Ext(rel)) + ".yaml"
+	})
+}
+
+func importAccessLog(cmd *cobra.Command, args []string) error {
+	setupLogger()
+
+	if importTarget == "" {
+		return fmt.Errorf("--target is required, access logs do not record the host")
+	}
+
+	expected, err := importer.ParseExpectation(accessLogExpect)
+	if err != nil {
+		return err
+	}
+
+	var results []*importer.Result
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+
+		logger.WithFields(logrus.Fields{
+			"path":       path,
+			"output_dir": importOutputDir,
+		}).Info("Importing access log")
+
+		result, err := importer.ImportAccessLog(data, path, importer.AccessLogOptions{
+			BaseURL:  importTarget,
+			Format:   accessLogFormat,
+			Expected: expected,
+			Dedupe:   !accessLogKeepDupes,
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		results = append(results, result)
+	}
+
+	return writeSuites(importOutputDir, results, func(result *importer.Result) string {
+		base := filepath.Base(result.Source)
+		return strings.TrimSuffix(base, filepath.Ext(base)) + ".yaml"
+	})
+}
+
+func importNuclei(cmd *cobra.Command, args []string) error {
+	setupLogger()
+
+	if importTarget == "" {
+		return fmt.Errorf("--target is required, nuclei templates do not name a host")
+	}
+
+	opts := importer.NucleiOptions{BaseURL: importTarget}
+	if nucleiExpect != "matchers" {
+		expected, err := importer.ParseExpectation(nucleiExpect)
+		if err != nil {
+			return err
+		}
+		opts.Expected = &expected
+	}
+
+	path := args[0]
+	logger.WithFields(logrus.Fields{
+		"path":       path,
+		"output_dir": importOutputDir,
+	}).Info("Importing nuclei templates")
+
+	if !isDirectory(path) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+		result, err := importer.ImportNuclei(data, path, opts)
+		if err != nil {
+			return err
+		}
+		return writeSuites(importOutputDir, []*importer.Result{result}, func(result *importer.Result) string {
+			base := filepath.Base(result.Source)
+			return strings.TrimSuffix(base, filepath.Ext(base)) + ".yaml"
+		})
+	}
+
+	results, err := importer.ImportNucleiDir(path, opts)
+	if err != nil {
+		return err
+	}
+
+	// Mirror the layout of the template directory
+	return writeSuites(importOutputDir, results, func(result *importer.Result) string {
+		rel, err := filepath.Rel(path, result.Source)
+		if err != nil {
+			rel = filepath.Base(result.Source)
+		}
+		return strings.TrimSuffix(rel, filepath.Ext(rel)) + ".yaml"
+	})
+}
+
+// writeSuites validates each converted suite and writes it to outputDir
+// under the relative path returned by fileFor. Skipped content is logged.
+func writeSuites(outputDir string, results []*importer.Result, fileFor func(*importer.Result) string) error {
+	p := parser.NewParser()
+	written := 0
+	tests := 0
+	unsupported := 0
+
+	for _, result := range results {
+		for _, note := range result.Unsupported {
+			logger.WithFields(logrus.Fields{
+				"source": result.Source,
+				"reason": note,
+			}).Warn("Skipped unsupported content")
+		}
+		unsupported += len(result.Unsupported)
+
+		if result.Suite == nil {
+			continue
+		}
+
+		data, err := importer.Marshal(result.Suite)
+		if err != nil {
+			return err
+		}
+		if _, err := p.ParseYAML(data); err != nil {
+			return fmt.Errorf("converted suite from %s is invalid: %w", result.Source, err)
+		}
+
+		path := filepath.Join(outputDir, fileFor(result))
+		if err := importer.WriteFile(path, result.Suite); err != nil {
+			return err
+		}
+
+		written++
+		tests += len(result.Suite.Spec.Tests)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"files":       written,
+		"tests":       tests,
+		"unsupported": unsupported,
+		"output_dir":  outputDir,
+	}).Info("Suites written")
+
+	return nil
+}
@@ -2,15 +2,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 	"wafguard/internal/logger"
 	"wafguard/internal/core/config"
 	"wafguard/internal/executor"
+	"wafguard/internal/packs"
 	"wafguard/internal/parser"
 	"wafguard/internal/reporter"
+	"wafguard/internal/runner"
 	"wafguard/internal/validator"
 
 	"github.com/sirupsen/logrus"
@@ -18,13 +23,27 @@ import (
 )
 
 var (
-	logLevel   string
-	logFormat  string
-	outputFile string
-	format     string
-	concurrent int
+	logLevel     string
+	logFormat    string
+	outputFile   string
+	format       string
+	concurrent   int
+	failFast     bool
+	maxFailures  int
+	rps          float64
+	targetURL    string
+	blockStatus  []int
+	recordDir    string
+	replayDir    string
+	replayLive   bool
+	dumpAll      bool
+	dumpDir      string
+	dumpMaxSize  int
+	maxBodySize  int64
 )
 
+var errFailureLimitReached = errors.New("failure limit reached")
+
 func main() {
 	var rootCmd = &cobra.Command{
 		Use:   "wafguard",
@@ -33,9 +52,9 @@ func main() {
 	}
 
 	var runCmd = &cobra.Command{
-		Use:   "run [file or directory]",
+		Use:   "run [file, directory or pack:name]",
 		Short: "Run WAF tests",
-		Long:  "Run WAF tests from a YAML file or directory containing YAML files",
+		Long:  "Run WAF tests from a YAML file, a directory containing YAML files, or built-in packs (pack:sqli, pack:sqli,xss or pack:all)",
 		Args:  cobra.ExactArgs(1),
 		RunE:  runTests,
 	}
@@ -51,23 +70,54 @@ func main() {
 	runCmd.Flags().StringVarP(&logLevel, "log-level", "l", "info", "Log level (debug, info, warn, error)")
 	runCmd.Flags().StringVarP(&logFormat, "log-format", "f", "text", "Log format (json, text)")
 	runCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for test results")
-	runCmd.Flags().StringVarP(&format, "format", "F", "text", "Output format (json, text)")
+	runCmd.Flags().StringVarP(&format, "format", "F", "text", "Output format (json, text, har)")
 	runCmd.Flags().IntVarP(&concurrent, "concurrent", "c", 1, "Number of concurrent test executions")
+	runCmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop the run after the first failing test")
+	runCmd.Flags().IntVar(&maxFailures, "max-failures", 0, "Stop the run after N failing tests (0 means no limit)")
+	runCmd.Flags().StringVarP(&targetURL, "target", "t", "", "Base URL to run against, overriding spec.target.baseUrl (required for packs)")
+	runCmd.Flags().IntSliceVar(&blockStatus, "block-status", nil, "Statuses that mean the WAF blocked a request, overriding spec.target.blockStatus (default 403)")
+	runCmd.Flags().Float64Var(&rps, "rps", 0, "Maximum requests per second per target host, overriding spec.target.rateLimit (0 means no limit)")
+	runCmd.Flags().StringVar(&recordDir, "record", "", "Record every response into this directory for later replay")
+	runCmd.Flags().StringVar(&replayDir, "replay", "", "Serve responses recorded with --record from this directory instead of sending requests")
+	runCmd.Flags().BoolVar(&replayLive, "replay-live", false, "With --replay, send requests that were not recorded over the network instead of failing them")
+	runCmd.Flags().Int64Var(&maxBodySize, "max-body-size", 0, "Bytes of each response body kept for validation, overriding spec.target.maxBodySize (default 10 MiB)")
+	runCmd.Flags().BoolVar(&dumpAll, "dump", false, "Capture the raw request and response of every test into the report (tests with debug: true are always captured)")
+	runCmd.Flags().StringVar(&dumpDir, "dump-dir", "", "Also write each captured exchange to <test name>.http in this directory")
+	runCmd.Flags().IntVar(&dumpMaxSize, "dump-max-size", executor.DefaultDumpSize, "Maximum bytes captured of each request and response")
+	runCmd.MarkFlagsMutuallyExclusive("record", "replay")
 
 	validateCmd.Flags().StringVarP(&logLevel, "log-level", "l", "info", "Log level (debug, info, warn, error)")
 	validateCmd.Flags().StringVarP(&logFormat, "log-format", "f", "text", "Log format (json, text)")
 
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(validateCmd)
-
-	if err := rootCmd.Execute(); err != nil {
+	rootCmd.AddCommand(newImportCmd())
+	rootCmd.AddCommand(newConvertCmd())
+	rootCmd.AddCommand(newGenerateCmd())
+	rootCmd.AddCommand(newPacksCmd())
+	rootCmd.AddCommand(newFuzzCmd())
+	rootCmd.AddCommand(newMinimizeCmd())
+	rootCmd.AddCommand(newBenignCmd())
+	rootCmd.AddCommand(newServeCmd())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Restore default signal handling after the first signal so that a
+	// second Ctrl-C terminates immediately instead of waiting for cleanup.
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
 func runTests(cmd *cobra.Command, args []string) error {
-	setupLogger()
+	setupReportLogger(cmd, format)
 	
 	path := args[0]
 	logger.WithFields(logrus.Fields{
@@ -80,7 +130,15 @@ func runTests(cmd *cobra.Command, args []string) error {
 	var tests []*config.SentinelTest
 	var err error
 
-	if isDirectory(path) {
+	if packNames, isPack, packErr := packs.ParseRef(path); isPack {
+		if packErr != nil {
+			return packErr
+		}
+		if targetURL == "" {
+			return fmt.Errorf("--target is required when running packs")
+		}
+		tests, err = loadPacks(packNames)
+	} else if isDirectory(path) {
 		tests, err = p.ParseDirectory(path)
 	} else {
 		test, parseErr := p.ParseFile(path)
@@ -99,7 +157,19 @@ func runTests(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	return executeTests(tests)
+	for _, test := range tests {
+		if targetURL != "" {
+			test.Spec.Target.BaseURL = targetURL
+		}
+		if len(blockStatus) > 0 {
+			test.Spec.Target.BlockStatus = blockStatus
+		}
+		if maxBodySize > 0 {
+			test.Spec.Target.MaxBodySize = maxBodySize
+		}
+	}
+
+	return executeTests(cmd.Context(), tests)
 }
 
 func validateTests(cmd *cobra.Command, args []string) error {
@@ -135,9 +205,38 @@ func validateTests(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func executeTests(tests []*config.SentinelTest) error {
+func executeTests(ctx context.Context, tests []*config.SentinelTest) error {
 	rep := reporter.NewReporter(format, outputFile)
 	start := time.Now()
+
+	limit := maxFailures
+	if failFast {
+		limit = 1
+	}
+	runCtx, cancelRun := context.WithCancelCause(ctx)
+	defer cancelRun(nil)
+	failures := newFailureLimiter(limit, cancelRun)
+
+	// One set of limiters for the whole run, so suites that share a host
+	// also share its request budget.
+	rateLimiters := executor.NewRateLimiters()
+	if rps > 0 {
+		rateLimiters.SetDefault(config.RateLimit{RPS: rps})
+	}
+	for _, test := range tests {
+		if err := rateLimiters.Configure(test.Spec.Target); err != nil {
+			return fmt.Errorf("failed to configure rate limit for %s: %w", test.Metadata.Name, err)
+		}
+	}
+
+	cassette, err := newCassette()
+	if err != nil {
+		return err
+	}
+	executorOptions := []executor.Option{executor.WithRateLimiters(rateLimiters)}
+	if cassette != nil {
+		executorOptions = append(executorOptions, executor.WithCassette(cassette))
+	}
 	
 	var allReports []reporter.TestReport
 	var mu sync.Mutex
@@ -148,16 +247,16 @@ func executeTests(tests []*config.SentinelTest) error {
 			"test_count": len(test.Spec.Tests),
 		}).Info("Executing test suite")
 
-		httpExecutor := executor.NewHTTPExecutor(test.Spec.Target.Timeout)
+		httpExecutor := executor.NewHTTPExecutor(test.Spec.Target.Timeout, executorOptions...)
 		responseValidator := validator.NewResponseValidator()
 
 		if concurrent <= 1 {
-			reports := executeTestsSequentially(test, httpExecutor, responseValidator, rep)
+			reports := executeTestsSequentially(runCtx, test, httpExecutor, responseValidator, rep, failures)
 			mu.Lock()
 			allReports = append(allReports, reports...)
 			mu.Unlock()
 		} else {
-			reports := executeTestsConcurrently(test, httpExecutor, responseValidator, rep, concurrent)
+			reports := executeTestsConcurrently(runCtx, test, httpExecutor, responseValidator, rep, failures, concurrent)
 			mu.Lock()
 			allReports = append(allReports, reports...)
 			mu.Unlock()
@@ -166,6 +265,19 @@ func executeTests(tests []*config.SentinelTest) error {
 
 	duration := time.Since(start)
 	suiteReport := rep.GenerateSuiteReport("All Tests", allReports, duration)
+	suiteReport.Interrupted = ctx.Err() != nil
+	suiteReport.StoppedEarly = errors.Is(context.Cause(runCtx), errFailureLimitReached)
+
+	if suiteReport.Interrupted {
+		logger.WithFields(logrus.Fields{
+			"skipped_tests": suiteReport.SkippedTests,
+		}).Warn("Test run interrupted, reporting partial results")
+	} else if suiteReport.StoppedEarly {
+		logger.WithFields(logrus.Fields{
+			"max_failures":  limit,
+			"skipped_tests": suiteReport.SkippedTests,
+		}).Warn("Failure limit reached, remaining tests were skipped")
+	}
 	
 	rep.PrintSuiteReport(suiteReport)
 	
@@ -173,6 +285,14 @@ func executeTests(tests []*config.SentinelTest) error {
 		logger.Error("Failed to save report:", err)
 	}
 
+	if replayDir != "" && !replayLive && cassette.Misses() > 0 {
+		return fmt.Errorf("%d requests had no recorded response in %s", cassette.Misses(), replayDir)
+	}
+
+	if suiteReport.Interrupted {
+		os.Exit(130)
+	}
+
 	if suiteReport.FailedTests > 0 {
 		os.Exit(1)
 	}
@@ -180,70 +300,114 @@ func executeTests(tests []*config.SentinelTest) error {
 	return nil
 }
 
-func executeTestsSequentially(sentinelTest *config.SentinelTest, httpExecutor *executor.HTTPExecutor, responseValidator *validator.ResponseValidator, rep *reporter.Reporter) []reporter.TestReport {
+// newCassette returns the cassette selected by --record or --replay, or nil.
+func newCassette() (*executor.Cassette, error) {
+	switch {
+	case recordDir != "":
+		return executor.NewRecordingCassette(recordDir)
+	case replayDir != "":
+		return executor.LoadCassette(replayDir, !replayLive)
+	case replayLive:
+		return nil, fmt.Errorf("--replay-live requires --replay")
+	}
+	return nil, nil
+}
+
+// dumpOptions returns the exchange capture selected by the --dump flags.
+func dumpOptions() runner.DumpOptions {
+	return runner.DumpOptions{
+		All:     dumpAll,
+		Dir:     dumpDir,
+		MaxSize: dumpMaxSize,
+	}
+}
+
+func executeTestsSequentially(ctx context.Context, sentinelTest *config.SentinelTest, httpExecutor executor.Executor, responseValidator *validator.ResponseValidator, rep *reporter.Reporter, failures *failureLimiter) []reporter.TestReport {
 	var reports []reporter.TestReport
+	testRunner := runner.NewRunner(httpExecutor, responseValidator, rep).WithDump(dumpOptions())
 
 	for _, test := range sentinelTest.Spec.Tests {
-		start := time.Now()
-		
-		response, err := httpExecutor.ExecuteTest(&test, sentinelTest.Spec.Target.BaseURL)
+		if ctx.Err() != nil {
+			reports = append(reports, *rep.GenerateSkippedTestReport(test.Name, &test.Request, skipReason(ctx)+" before test started"))
+			continue
+		}
+
+		testStart := time.Now()
+		report, err := testRunner.RunTest(ctx, &test, sentinelTest.Spec.Target)
 		if err != nil {
+			if ctx.Err() != nil {
+				reports = append(reports, *rep.GenerateSkippedTestReport(test.Name, &test.Request, skipReason(ctx)+" while request was in flight"))
+				continue
+			}
 			logger.WithFields(logrus.Fields{
 				"test_name": test.Name,
 				"error":     err,
 			}).Error("Failed to execute test")
-			continue
+			// Count it like any other failure, as --fail-fast expects
+			report = rep.GenerateErrorTestReport(test.Name, &test.Request, err, time.Since(testStart))
 		}
 
-		validation := responseValidator.Validate(response, &test.Expected, test.Name)
-		duration := time.Since(start)
-
-		report := rep.GenerateTestReport(test.Name, &test.Request, response, validation, duration)
 		reports = append(reports, *report)
 		
 		rep.PrintTestReport(report)
+		failures.record(report)
 	}
 
 	return reports
 }
 
-func executeTestsConcurrently(sentinelTest *config.SentinelTest, httpExecutor *executor.HTTPExecutor, responseValidator *validator.ResponseValidator, rep *reporter.Reporter, maxConcurrent int) []reporter.TestReport {
+func executeTestsConcurrently(ctx context.Context, sentinelTest *config.SentinelTest, httpExecutor executor.Executor, responseValidator *validator.ResponseValidator, rep *reporter.Reporter, failures *failureLimiter, maxConcurrent int) []reporter.TestReport {
 	var reports []reporter.TestReport
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
 	semaphore := make(chan struct{}, maxConcurrent)
-	ctx := context.Background()
+	testRunner := runner.NewRunner(httpExecutor, responseValidator, rep).WithDump(dumpOptions())
+
+	skip := func(t config.Test, reason string) {
+		mu.Lock()
+		reports = append(reports, *rep.GenerateSkippedTestReport(t.Name, &t.Request, reason))
+		mu.Unlock()
+	}
 
 	for _, test := range sentinelTest.Spec.Tests {
 		wg.Add(1)
 		go func(t config.Test) {
 			defer wg.Done()
 			
-			semaphore <- struct{}{}
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				skip(t, skipReason(ctx)+" before test started")
+				return
+			}
 			defer func() { <-semaphore }()
 
-			start := time.Now()
-			
-			response, err := httpExecutor.ExecuteTestWithContext(ctx, &t, sentinelTest.Spec.Target.BaseURL)
+			if ctx.Err() != nil {
+				skip(t, skipReason(ctx)+" before test started")
+				return
+			}
+
+			testStart := time.Now()
+			report, err := testRunner.RunTest(ctx, &t, sentinelTest.Spec.Target)
 			if err != nil {
+				if ctx.Err() != nil {
+					skip(t, skipReason(ctx)+" while request was in flight")
+					return
+				}
 				logger.WithFields(logrus.Fields{
 					"test_name": t.Name,
 					"error":     err,
 				}).Error("Failed to execute test")
-				return
+				report = rep.GenerateErrorTestReport(t.Name, &t.Request, err, time.Since(testStart))
 			}
-
-			validation := responseValidator.Validate(response, &t.Expected, t.Name)
-			duration := time.Since(start)
-
-			report := rep.GenerateTestReport(t.Name, &t.Request, response, validation, duration)
 			
 			mu.Lock()
 			reports = append(reports, *report)
 			mu.Unlock()
 			
 			rep.PrintTestReport(report)
+			failures.record(report)
 		}(test)
 	}
 
@@ -251,11 +415,65 @@ func executeTestsConcurrently(sentinelTest *config.SentinelTest, httpExecutor *e
 	return reports
 }
 
+// failureLimiter cancels the run once the number of failed tests reaches
+// the configured limit, counting tests whose request could not be executed
+// as failed. A limit of zero disables it.
+type failureLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	count  int
+	cancel context.CancelCauseFunc
+}
+
+func newFailureLimiter(limit int, cancel context.CancelCauseFunc) *failureLimiter {
+	return &failureLimiter{
+		limit:  limit,
+		cancel: cancel,
+	}
+}
+
+func (f *failureLimiter) record(report *reporter.TestReport) {
+	if f == nil || f.limit <= 0 || report.Status != reporter.StatusFail {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.count++
+	if f.count == f.limit {
+		logger.WithFields(logrus.Fields{
+			"test_name":    report.TestName,
+			"max_failures": f.limit,
+		}).Warn("Failure limit reached, cancelling remaining tests")
+		f.cancel(errFailureLimitReached)
+	}
+}
+
+// skipReason describes why ctx was cancelled, for use in skipped test reports.
+func skipReason(ctx context.Context) string {
+	if errors.Is(context.Cause(ctx), errFailureLimitReached) {
+		return "run stopped after reaching the failure limit"
+	}
+	return "run interrupted"
+}
+
 func setupLogger() {
+	logger.SetOutput(os.Stdout)
 	logger.SetLevel(logLevel)
 	logger.SetFormatter(logFormat)
 }
 
+// setupReportLogger sets up logging for a command printing a report in
+// format, and moves logs to stderr when the report is a JSON or HAR
+// document so stdout stays parseable.
+func setupReportLogger(cmd *cobra.Command, format string) {
+	setupLogger()
+	if format == "json" || format == "har" {
+		logger.SetOutput(cmd.ErrOrStderr())
+	}
+}
+
 func isDirectory(path string) bool {
 	info, err := os.Stat(path)
 	if err != nil {
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -9,6 +14,16 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/logger"
+	"wafguard/internal/mockwaf"
+	"wafguard/internal/parser"
+	"wafguard/internal/reporter"
+	"wafguard/internal/runner"
+	"wafguard/internal/validator"
+
+	"github.com/spf13/cobra"
 )
 
 func TestIsDirectory(t *testing.T) {
@@ -200,6 +215,26 @@ func TestSetupLogger(t *testing.T) {
 	}
 }
 
+func TestSetupReportLogger(t *testing.T) {
+	defer setupLogger()
+
+	for _, format := range []string{"text", "json", "har"} {
+		t.Run(format, func(t *testing.T) {
+			var stderr bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetErr(&stderr)
+
+			setupReportLogger(cmd, format)
+			logger.Error("logged")
+
+			toStderr := strings.Contains(stderr.String(), "logged")
+			if want := format != "text"; toStderr != want {
+				t.Errorf("log written to stderr = %v, want %v", toStderr, want)
+			}
+		})
+	}
+}
+
 func TestMainPackageIntegration(t *testing.T) {
 	// Test end-to-end integration with real files
 	tmpDir, err := os.MkdirTemp("", "integration_test")
@@ -527,6 +562,10 @@ func TestCommandLineFlags(t *testing.T) {
 		{"output file", "output"},
 		{"format", "format"},
 		{"concurrent", "concurrent"},
+		{"fail fast", "fail-fast"},
+		{"max failures", "max-failures"},
+		{"target", "target"},
+		{"block status", "block-status"},
 	}
 
 	for _, tt := range flagTests {
@@ -542,4 +581,737 @@ func TestCommandLineFlags(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestInterruptedRunSkipsRemainingTests(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	sentinelTest := &config.SentinelTest{
+		Metadata: config.Metadata{Name: "interrupted"},
+		Spec: config.Spec{
+			Target: config.Target{BaseURL: server.URL},
+			Tests: []config.Test{
+				{Name: "test1", Request: config.Request{Method: "GET", Path: "/1"}, Expected: config.Expected{Status: []int{200}}},
+				{Name: "test2", Request: config.Request{Method: "GET", Path: "/2"}, Expected: config.Expected{Status: []int{200}}},
+				{Name: "test3", Request: config.Request{Method: "GET", Path: "/3"}, Expected: config.Expected{Status: []int{200}}},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rep := reporter.NewReporter("text", "")
+	httpExecutor := executor.NewHTTPExecutor(time.Second)
+	responseValidator := validator.NewResponseValidator()
+
+	runners := map[string]func() []reporter.TestReport{
+		"sequential": func() []reporter.TestReport {
+			return executeTestsSequentially(ctx, sentinelTest, httpExecutor, responseValidator, rep, nil)
+		},
+		"concurrent": func() []reporter.TestReport {
+			return executeTestsConcurrently(ctx, sentinelTest, httpExecutor, responseValidator, rep, nil, 2)
+		},
+	}
+
+	for name, run := range runners {
+		t.Run(name, func(t *testing.T) {
+			reports := run()
+
+			if len(reports) != len(sentinelTest.Spec.Tests) {
+				t.Fatalf("got %d reports, want %d", len(reports), len(sentinelTest.Spec.Tests))
+			}
+
+			for _, report := range reports {
+				if report.Status != reporter.StatusSkipped {
+					t.Errorf("test %s status = %s, want %s", report.TestName, report.Status, reporter.StatusSkipped)
+				}
+			}
+		})
+	}
+
+	if requestCount != 0 {
+		t.Errorf("server received %d requests after cancellation, want 0", requestCount)
+	}
+}
+
+func TestFailureLimitSkipsRemainingTests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	// Every test expects a block, so every test fails against this server
+	sentinelTest := &config.SentinelTest{
+		Metadata: config.Metadata{Name: "fail-fast"},
+		Spec: config.Spec{
+			Target: config.Target{BaseURL: server.URL},
+			Tests: []config.Test{
+				{Name: "test1", Request: config.Request{Method: "GET", Path: "/1"}, Expected: config.Expected{Status: []int{403}}},
+				{Name: "test2", Request: config.Request{Method: "GET", Path: "/2"}, Expected: config.Expected{Status: []int{403}}},
+				{Name: "test3", Request: config.Request{Method: "GET", Path: "/3"}, Expected: config.Expected{Status: []int{403}}},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	rep := reporter.NewReporter("text", "")
+	reports := executeTestsSequentially(ctx, sentinelTest, executor.NewHTTPExecutor(time.Second), validator.NewResponseValidator(), rep, newFailureLimiter(1, cancel))
+
+	suiteReport := rep.GenerateSuiteReport("fail-fast", reports, 0)
+	if suiteReport.FailedTests != 1 {
+		t.Errorf("FailedTests = %d, want 1", suiteReport.FailedTests)
+	}
+	if suiteReport.SkippedTests != 2 {
+		t.Errorf("SkippedTests = %d, want 2", suiteReport.SkippedTests)
+	}
+	if reason := skipReason(ctx); !strings.Contains(reason, "failure limit") {
+		t.Errorf("skipReason() = %q, want mention of failure limit", reason)
+	}
+}
+
+func TestFailureLimitCountsExecutionErrors(t *testing.T) {
+	// Nothing listens on the target, so every request fails to execute
+	sentinelTest := &config.SentinelTest{
+		Metadata: config.Metadata{Name: "fail-fast"},
+		Spec: config.Spec{
+			Target: config.Target{BaseURL: "http://localhost:99999"},
+			Tests: []config.Test{
+				{Name: "test1", Request: config.Request{Method: "GET", Path: "/1"}, Expected: config.Expected{Status: []int{403}}},
+				{Name: "test2", Request: config.Request{Method: "GET", Path: "/2"}, Expected: config.Expected{Status: []int{403}}},
+				{Name: "test3", Request: config.Request{Method: "GET", Path: "/3"}, Expected: config.Expected{Status: []int{403}}},
+			},
+		},
+	}
+
+	runners := []struct {
+		name string
+		run  func(context.Context, *reporter.Reporter, *failureLimiter) []reporter.TestReport
+	}{
+		{"sequential", func(ctx context.Context, rep *reporter.Reporter, failures *failureLimiter) []reporter.TestReport {
+			return executeTestsSequentially(ctx, sentinelTest, executor.NewHTTPExecutor(time.Second), validator.NewResponseValidator(), rep, failures)
+		}},
+		{"concurrent", func(ctx context.Context, rep *reporter.Reporter, failures *failureLimiter) []reporter.TestReport {
+			return executeTestsConcurrently(ctx, sentinelTest, executor.NewHTTPExecutor(time.Second), validator.NewResponseValidator(), rep, failures, 1)
+		}},
+	}
+
+	for _, tc := range runners {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+
+			rep := reporter.NewReporter("text", "")
+			reports := tc.run(ctx, rep, newFailureLimiter(1, cancel))
+
+			suiteReport := rep.GenerateSuiteReport("fail-fast", reports, 0)
+			if suiteReport.FailedTests != 1 || suiteReport.SkippedTests != 2 {
+				t.Errorf("FailedTests = %d, SkippedTests = %d, want 1 and 2", suiteReport.FailedTests, suiteReport.SkippedTests)
+			}
+			if reason := skipReason(ctx); !strings.Contains(reason, "failure limit") {
+				t.Errorf("skipReason() = %q, want mention of failure limit", reason)
+			}
+		})
+	}
+}
+
+func TestReplayIsOfflineUnlessLive(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := executor.NewRecordingCassette(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := &config.Test{Name: "recorded", Request: config.Request{Method: "GET", Path: "/recorded"}}
+	if _, err := executor.NewHTTPExecutor(time.Second, executor.WithCassette(recorder)).ExecuteTest(context.Background(), recorded, server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { replayDir, replayLive = "", false }()
+	unrecorded := &config.Test{Name: "unrecorded", Request: config.Request{Method: "GET", Path: "/unrecorded"}}
+
+	replayDir, replayLive = dir, false
+	cassette, err := newCassette()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits = 0
+	_, err = executor.NewHTTPExecutor(time.Second, executor.WithCassette(cassette)).ExecuteTest(context.Background(), unrecorded, server.URL)
+	if !errors.Is(err, executor.ErrNotRecorded) || hits != 0 {
+		t.Errorf("replay sent %d requests, err = %v, want ErrNotRecorded and no requests", hits, err)
+	}
+
+	replayLive = true
+	cassette, err = newCassette()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := executor.NewHTTPExecutor(time.Second, executor.WithCassette(cassette)).ExecuteTest(context.Background(), unrecorded, server.URL); err != nil || hits != 1 {
+		t.Errorf("--replay-live sent %d requests, err = %v, want 1", hits, err)
+	}
+
+	replayDir = ""
+	if _, err := newCassette(); err == nil {
+		t.Error("newCassette() accepted --replay-live without --replay")
+	}
+}
+
+func TestImportFTWCommand(t *testing.T) {
+	sourceDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	ruleDir := filepath.Join(sourceDir, "REQUEST-942-APPLICATION-ATTACK-SQLI")
+	if err := os.MkdirAll(ruleDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ftw := `
+rule_id: 942100
+tests:
+- test_id: 1
+  stages:
+  - input:
+      uri: "/?id=1' or '1'='1"
+    output:
+      log:
+        expect_ids: [942100]
+`
+	if err := os.WriteFile(filepath.Join(ruleDir, "942100.yaml"), []byte(ftw), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newImportCmd()
+	cmd.SetArgs([]string{"ftw", sourceDir, "--output-dir", outputDir, "--target", "https://waf.example.com", "--log-as-blocked"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("import ftw failed: %v", err)
+	}
+
+	imported := filepath.Join(outputDir, "REQUEST-942-APPLICATION-ATTACK-SQLI", "942100.yaml")
+	suite, err := parser.NewParser().ParseFile(imported)
+	if err != nil {
+		t.Fatalf("imported file is not valid: %v", err)
+	}
+
+	if suite.Spec.Target.BaseURL != "https://waf.example.com" {
+		t.Errorf("BaseURL = %q, want %q", suite.Spec.Target.BaseURL, "https://waf.example.com")
+	}
+	if len(suite.Spec.Tests) != 1 || suite.Spec.Tests[0].Tags[0] != "942100" {
+		t.Errorf("unexpected imported tests: %+v", suite.Spec.Tests)
+	}
+}
+
+func TestImportAccessLogCommand(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := t.TempDir()
+
+	log := `10.0.0.1 [10/Oct/2026:13:55:36 +0000] "GET /products/42?ref=home HTTP/1.1" 200 "Mozilla/5.0"
+10.0.0.2 [10/Oct/2026:13:55:37 +0000] "GET /products/7?ref=mail HTTP/1.1" 200 "curl/8.0"
+10.0.0.3 [10/Oct/2026:13:55:38 +0000] "POST /login HTTP/1.1" 302 "Mozilla/5.0"
+`
+	source := filepath.Join(dir, "access.log")
+	if err := os.WriteFile(source, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newImportCmd()
+	cmd.SetArgs([]string{"accesslog", source,
+		"--line-format", `$remote_addr [$time_local] "$request" $status "$http_user_agent"`,
+		"--output-dir", outputDir, "--target", "https://staging.example.com"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("import accesslog failed: %v", err)
+	}
+
+	suite, err := parser.NewParser().ParseFile(filepath.Join(outputDir, "access.yaml"))
+	if err != nil {
+		t.Fatalf("imported file is not valid: %v", err)
+	}
+
+	if suite.Spec.Target.BaseURL != "https://staging.example.com" {
+		t.Errorf("BaseURL = %q, want %q", suite.Spec.Target.BaseURL, "https://staging.example.com")
+	}
+	// The second request has the same shape as the first
+	if len(suite.Spec.Tests) != 2 {
+		t.Fatalf("got %d tests, want 2: %+v", len(suite.Spec.Tests), suite.Spec.Tests)
+	}
+	first := suite.Spec.Tests[0]
+	if first.Request.Path != "/products/42?ref=home" || first.Request.Headers["User-Agent"] != "Mozilla/5.0" {
+		t.Errorf("unexpected first request: %+v", first.Request)
+	}
+	if first.Expected.Blocked == nil || *first.Expected.Blocked {
+		t.Errorf("expected the request to be allowed, got %+v", first.Expected)
+	}
+
+	cmd = newImportCmd()
+	cmd.SetArgs([]string{"accesslog", source, "--output-dir", outputDir})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected an error without --target")
+	}
+}
+
+func TestImportNucleiCommand(t *testing.T) {
+	sourceDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	template := `
+id: lfi-passwd
+info:
+  name: Path traversal to /etc/passwd
+  tags: lfi
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/download?file=../../../../etc/passwd"
+    matchers:
+      - type: regex
+        regex: ["root:.*:0:0:"]
+  - method: GET
+    path:
+      - "{{BaseURL}}/?u={{interactsh-url}}"
+`
+	if err := os.MkdirAll(filepath.Join(sourceDir, "lfi"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "lfi", "passwd.yaml"), []byte(template), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newImportCmd()
+	cmd.SetArgs([]string{"nuclei", sourceDir, "--output-dir", outputDir, "--target", "https://waf.example.com", "--expect", "blocked"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("import nuclei failed: %v", err)
+	}
+
+	suite, err := parser.NewParser().ParseFile(filepath.Join(outputDir, "lfi", "passwd.yaml"))
+	if err != nil {
+		t.Fatalf("imported file is not valid: %v", err)
+	}
+
+	if len(suite.Spec.Tests) != 1 {
+		t.Fatalf("got %d tests, want 1: %+v", len(suite.Spec.Tests), suite.Spec.Tests)
+	}
+	test := suite.Spec.Tests[0]
+	if test.Request.Path != "/download?file=../../../../etc/passwd" {
+		t.Errorf("Path = %q", test.Request.Path)
+	}
+	if test.Expected.Blocked == nil || !*test.Expected.Blocked {
+		t.Errorf("expected the request to be blocked, got %+v", test.Expected)
+	}
+}
+
+func TestConvertCurlCommand(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "finding.yaml")
+
+	cmd := newConvertCmd()
+	cmd.SetArgs([]string{"curl", "--output", output, "--expect", "403", "--", "curl", "-H", "X-Test: it's", "-d", "id=1 OR 1=1", "https://example.com/login"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("convert curl failed: %v", err)
+	}
+
+	suite, err := parser.NewParser().ParseFile(output)
+	if err != nil {
+		t.Fatalf("converted file is not valid: %v", err)
+	}
+
+	request := suite.Spec.Tests[0].Request
+	if request.Method != "POST" || request.Path != "/login" || request.Body != "id=1 OR 1=1" {
+		t.Errorf("unexpected request: %+v", request)
+	}
+	if request.Headers["X-Test"] != "it's" {
+		t.Errorf("X-Test header = %q, want %q", request.Headers["X-Test"], "it's")
+	}
+	if len(suite.Spec.Tests[0].Expected.Status) != 1 || suite.Spec.Tests[0].Expected.Status[0] != 403 {
+		t.Errorf("Expected.Status = %v, want [403]", suite.Spec.Tests[0].Expected.Status)
+	}
+}
+
+func TestConvertHARCommand(t *testing.T) {
+	dir := t.TempDir()
+	capture := filepath.Join(dir, "capture.har")
+	output := filepath.Join(dir, "benign.yaml")
+
+	har := `{"log":{"entries":[
+		{"request":{"method":"GET","url":"https://a.example.com/","headers":[]},"response":{"status":200}},
+		{"request":{"method":"GET","url":"https://b.example.com/","headers":[]},"response":{"status":404}}
+	]}}`
+	if err := os.WriteFile(capture, []byte(har), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newConvertCmd()
+	cmd.SetArgs([]string{"har", capture, "--output", output})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "2 origins") {
+		t.Fatalf("expected an error about multiple origins, got %v", err)
+	}
+
+	cmd = newConvertCmd()
+	cmd.SetArgs([]string{"har", capture, "--output", output, "--host", "b.example.com", "--expect", "recorded"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("convert har failed: %v", err)
+	}
+
+	suite, err := parser.NewParser().ParseFile(output)
+	if err != nil {
+		t.Fatalf("converted file is not valid: %v", err)
+	}
+	if len(suite.Spec.Tests) != 1 || suite.Spec.Tests[0].Expected.Status[0] != 404 {
+		t.Errorf("unexpected converted tests: %+v", suite.Spec.Tests)
+	}
+}
+
+func TestGenerateOpenAPICommand(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.yaml")
+	payloads := filepath.Join(dir, "sqli.txt")
+	outputDir := filepath.Join(dir, "generated")
+
+	doc := `
+openapi: 3.0.0
+servers:
+  - url: https://api.example.com
+paths:
+  /search:
+    get:
+      parameters:
+        - name: q
+          in: query
+          schema:
+            type: string
+`
+	if err := os.WriteFile(spec, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(payloads, []byte("' OR 1=1--\n1 UNION SELECT NULL\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newGenerateCmd()
+	cmd.SetArgs([]string{"openapi", spec, "--payloads", payloads, "--output-dir", outputDir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("generate openapi failed: %v", err)
+	}
+
+	suites, err := parser.NewParser().ParseDirectory(outputDir)
+	if err != nil {
+		t.Fatalf("generated files are not valid: %v", err)
+	}
+	if len(suites) != 1 || len(suites[0].Spec.Tests) != 2 {
+		t.Fatalf("unexpected generated suites: %+v", suites)
+	}
+	if suites[0].Spec.Tests[0].Tags[1] != "query:q" {
+		t.Errorf("Tags = %v, want the injection location", suites[0].Spec.Tests[0].Tags)
+	}
+}
+
+func TestPacksListCommand(t *testing.T) {
+	var out strings.Builder
+
+	cmd := newPacksCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"list"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("packs list failed: %v", err)
+	}
+
+	for _, want := range []string{"NAME", "sqli", "xss", "jndi"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("packs list output should contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestFuzzCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, "<") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "xss.yaml")
+	suite := `
+apiVersion: sentinel-test/v1
+kind: SentinelTest
+metadata:
+  name: xss
+spec:
+  target:
+    baseUrl: http://localhost
+  tests:
+    - name: xss
+      request:
+        method: GET
+        path: /
+      expected:
+        blocked: true
+      inject:
+        payloads: ["<script>"]
+        locations: [query:q]
+`
+	if err := os.WriteFile(file, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	cmd := newFuzzCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{file, "--target", server.URL, "--test", "xss", "--transforms", "url,base64", "--log-level", "error"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "bypass") {
+		t.Fatalf("fuzz should fail when a bypass is found, got %v", err)
+	}
+	for _, want := range []string{"Test: xss/query:q/1", "Baseline: blocked (status 403)", "Bypass: "} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("fuzz output should contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestMinimizeCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, "%3Cscript") {
+			_, _ = w.Write([]byte("<script> reflected"))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "bypass.yaml")
+	suite := `
+apiVersion: sentinel-test/v1
+kind: SentinelTest
+metadata:
+  name: report
+spec:
+  target:
+    baseUrl: http://localhost
+  tests:
+    - name: bypass
+      request:
+        method: GET
+        path: /search?page=2&q=%3Cscript%3Ealert(document.cookie)%3C/script%3E
+        headers:
+          Accept: text/html
+      expected:
+        blocked: true
+`
+	if err := os.WriteFile(file, []byte(suite), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, errOut strings.Builder
+	cmd := newMinimizeCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{file, "--target", server.URL, "--log-level", "error"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "marker") {
+		t.Fatalf("minimize without --marker: error = %v, want a required flag error", err)
+	}
+
+	out.Reset()
+	errOut.Reset()
+	cmd = newMinimizeCmd()
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{file, "--target", server.URL, "--marker", "reflected", "--log-level", "error"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("minimize failed: %v", err)
+	}
+
+	minimized, err := parser.NewParser().ParseYAML([]byte(out.String()))
+	if err != nil {
+		t.Fatalf("minimized suite is invalid: %v\n%s", err, out.String())
+	}
+	test := minimized.Spec.Tests[0]
+	if test.Request.Path != "/?%3Cscript" {
+		t.Errorf("Path = %q, want %q", test.Request.Path, "/?%3Cscript")
+	}
+	if test.Request.Headers != nil || test.Expected.Blocked == nil || !*test.Expected.Blocked {
+		t.Errorf("unexpected minimized test: %+v", test)
+	}
+	if !strings.Contains(errOut.String(), "Minimized bypass") {
+		t.Errorf("summary missing from stderr: %q", errOut.String())
+	}
+}
+
+func TestBenignCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.RawQuery, "select") {
+			w.Header().Set("X-Request-Id", "abc123")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("Request blocked. Rule ID: 942100"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	corpus := filepath.Join(t.TempDir(), "urls.txt")
+	urls := "/products/1\n/products/2?q=select+a+size\n/products/3\n/about\n"
+	if err := os.WriteFile(corpus, []byte(urls), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	cmd := newBenignCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{corpus, "--target", server.URL, "--log-level", "error", "--concurrent", "2"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "25.00%") {
+		t.Fatalf("benign should fail on false positives, got %v", err)
+	}
+	for _, want := range []string{
+		"False-Positive Rate: 25.00%",
+		"GET /products/{id}",
+		"False Positive: GET /products/2?q=select+a+size",
+		"Header: X-Request-Id: abc123",
+		"Rule IDs: 942100",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("benign output should contain %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	cmd = newBenignCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{corpus, "--target", server.URL, "--log-level", "error", "--max-fp-rate", "30"})
+	if err := cmd.Execute(); err != nil {
+		t.Errorf("benign should pass below --max-fp-rate, got %v", err)
+	}
+}
+
+func TestServeMockWAFCommand(t *testing.T) {
+	rules := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rules, []byte("rules:\n  - id: 1001\n    pattern: evil\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out, w := io.Pipe()
+	cmd := newServeCmd()
+	cmd.SetOut(w)
+	cmd.SetArgs([]string{"mock-waf", "--listen", "127.0.0.1:0", "--rules", rules, "--log-level", "error"})
+
+	errc := make(chan error, 1)
+	go func() { errc <- cmd.ExecuteContext(ctx) }()
+
+	line, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, baseURL, ok := strings.Cut(strings.TrimSpace(line), "listening on ")
+	if !ok {
+		t.Fatalf("unexpected startup line %q", line)
+	}
+
+	for path, want := range map[string]int{"/?q=evil": 403, "/?q=good": 200} {
+		resp, err := http.Get(baseURL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s: status %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Errorf("serve mock-waf returned %v", err)
+	}
+}
+
+func TestServeEchoCommand(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out, w := io.Pipe()
+	cmd := newServeCmd()
+	cmd.SetOut(w)
+	cmd.SetArgs([]string{"echo", "--listen", "127.0.0.1:0", "--log-level", "error"})
+
+	errc := make(chan error, 1)
+	go func() { errc <- cmd.ExecuteContext(ctx) }()
+
+	line, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, baseURL, ok := strings.Cut(strings.TrimSpace(line), "listening on ")
+	if !ok {
+		t.Fatalf("unexpected startup line %q", line)
+	}
+
+	test := &config.Test{
+		Name: "echo",
+		Request: config.Request{
+			Method:  "POST",
+			Path:    "/a/../b?x=%27",
+			Headers: map[string]string{"X-Test": "1"},
+			Body:    "payload",
+		},
+		Expected: config.Expected{
+			Status:   []int{200},
+			Upstream: &config.Upstream{Path: true, Header: []string{"*"}, Body: true},
+		},
+	}
+	testRunner := runner.NewRunner(executor.NewHTTPExecutor(0), validator.NewResponseValidator(), reporter.NewReporter("text", ""))
+	report, err := testRunner.RunTest(context.Background(), test, config.Target{BaseURL: baseURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != reporter.StatusPass {
+		t.Errorf("status = %s, errors %v", report.Status, report.ValidationResult.Errors)
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Errorf("serve echo returned %v", err)
+	}
+}
+
+func TestExamplesAgainstMockWAF(t *testing.T) {
+	server := httptest.NewServer(mockwaf.Handler(mockwaf.DefaultRules()))
+	defer server.Close()
+
+	suites, err := parser.NewParser().ParseDirectory(filepath.Join("..", "..", "examples", "test-configs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testRunner := runner.NewRunner(executor.NewHTTPExecutor(0), validator.NewResponseValidator(), reporter.NewReporter("text", ""))
+	for _, suite := range suites {
+		target := suite.Spec.Target
+		target.BaseURL = server.URL
+		for i := range suite.Spec.Tests {
+			test := &suite.Spec.Tests[i]
+			report, err := testRunner.RunTest(context.Background(), test, target)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", suite.Metadata.Name, test.Name, err)
+			}
+			if report.Status != reporter.StatusPass {
+				t.Errorf("%s/%s: %s %v", suite.Metadata.Name, test.Name, report.Status, report.ValidationResult)
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/importer"
+	"wafguard/internal/logger"
+	"wafguard/internal/minimize"
+	"wafguard/internal/parser"
+	"wafguard/internal/validator"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	minimizeTestName    string
+	minimizeTarget      string
+	minimizeBlockStatus []int
+	minimizeBudget      int
+	minimizeMarker      string
+	minimizeOutput      string
+)
+
+func newMinimizeCmd() *cobra.Command {
+	minimizeCmd := &cobra.Command{
+		Use:   "minimize [file]",
+		Short: "Shrink a bypassing request to the smallest one that still bypasses",
+		Long: `Shrink a bypassing request to the smallest one that still bypasses.
+
+The selected test is sent as written and must not be blocked. Its header
+values, body and path are then cut down with delta debugging, keeping
+every change after which the WAF still lets the request through and the
+origin still answers with the same status and a body containing --marker.
+The marker, such as a database error or the reflected payload, is required:
+without it a harmless request would count as a bypass and the payload would
+be cut away. Headers that are not needed are dropped.
+
+The result is written as a SentinelTest expecting the request to be
+blocked, ready to be added to a regression suite.`,
+		Args: cobra.ExactArgs(1),
+		RunE: minimizeRequest,
+	}
+
+	minimizeCmd.Flags().StringVarP(&minimizeTestName, "test", "T", "", "Name of the test to minimize (required if the file has several)")
+	minimizeCmd.Flags().StringVarP(&minimizeTarget, "target", "t", "", "Base URL to send requests to, overriding spec.target.baseUrl")
+	minimizeCmd.Flags().IntSliceVar(&minimizeBlockStatus, "block-status", nil, "Statuses that mean the WAF blocked a request, overriding spec.target.blockStatus (default 403)")
+	minimizeCmd.Flags().IntVar(&minimizeBudget, "budget", minimize.DefaultBudget, "Maximum number of requests to send")
+	minimizeCmd.Flags().StringVar(&minimizeMarker, "marker", "", "Text the origin response must contain, proving the payload reached it (required)")
+	minimizeCmd.Flags().StringVarP(&minimizeOutput, "output", "o", "", "File to write the minimized test to (default stdout)")
+	minimizeCmd.Flags().StringVarP(&logLevel, "log-level", "l", "warn", "Log level (debug, info, warn, error)")
+	minimizeCmd.Flags().StringVarP(&logFormat, "log-format", "f", "text", "Log format (json, text)")
+	_ = minimizeCmd.MarkFlagRequired("marker")
+
+	return minimizeCmd
+}
+
+func minimizeRequest(cmd *cobra.Command, args []string) error {
+	setupLogger()
+
+	suite, err := parser.NewParser().ParseFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	test, err := selectTest(suite.Spec.Tests, minimizeTestName)
+	if err != nil {
+		return err
+	}
+	if test.RateLimitCheck != nil {
+		return fmt.Errorf("test %s is a rate-limit check and cannot be minimized", test.Name)
+	}
+
+	target := suite.Spec.Target
+	if minimizeTarget != "" {
+		target.BaseURL = minimizeTarget
+	}
+	if len(minimizeBlockStatus) > 0 {
+		target.BlockStatus = minimizeBlockStatus
+	}
+
+	minimizer := minimize.NewMinimizer(executor.NewHTTPExecutor(target.Timeout), validator.NewResponseValidator(), minimize.Options{
+		Budget: minimizeBudget,
+		Marker: minimizeMarker,
+	})
+	result, err := minimizer.Minimize(cmd.Context(), test, target)
+	if err != nil {
+		return fmt.Errorf("test %s: %w", test.Name, err)
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Minimized %s from %d to %d bytes in %d requests (status %d)\n",
+		test.Name, minimize.RequestSize(result.Original), minimize.RequestSize(result.Test.Request), result.Requests, result.StatusCode)
+	if result.Exhausted {
+		fmt.Fprintln(cmd.ErrOrStderr(), "warning: request budget exhausted, the result may shrink further with a larger --budget")
+	}
+
+	data, err := importer.Marshal(minimizedSuite(suite, target, result.Test))
+	if err != nil {
+		return err
+	}
+
+	if minimizeOutput == "" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(minimizeOutput, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", minimizeOutput, err)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"file": minimizeOutput,
+		"test": test.Name,
+	}).Info("Minimized test written to file")
+
+	return nil
+}
+
+// selectTest returns the test called name, or the only test when name is
+// empty.
+func selectTest(tests []config.Test, name string) (*config.Test, error) {
+	if name == "" {
+		if len(tests) != 1 {
+			return nil, fmt.Errorf("the file has %d tests, use --test to pick one", len(tests))
+		}
+		return &tests[0], nil
+	}
+
+	for i := range tests {
+		if tests[i].Name == name {
+			return &tests[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no test named %s", name)
+}
+
+// minimizedSuite wraps the minimized request in a suite of its own that
+// expects the WAF to block it.
+func minimizedSuite(suite *config.SentinelTest, target config.Target, test *config.Test) *config.SentinelTest {
+	blocked := true
+	minimized := config.Test{
+		Name:     test.Name + "-minimized",
+		Tags:     test.Tags,
+		Request:  test.Request,
+		Expected: config.Expected{Blocked: &blocked},
+	}
+	if len(minimized.Request.Headers) == 0 {
+		minimized.Request.Headers = nil
+	}
+
+	return &config.SentinelTest{
+		APIVersion: importer.APIVersion,
+		Kind:       importer.Kind,
+		Metadata: config.Metadata{
+			Name:        suite.Metadata.Name + "-minimized",
+			Description: fmt.Sprintf("Minimized from test %s", test.Name),
+		},
+		Spec: config.Spec{
+			Target: config.Target{
+				BaseURL:     target.BaseURL,
+				Timeout:     target.Timeout,
+				BlockStatus: target.BlockStatus,
+			},
+			Tests: []config.Test{minimized},
+		},
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"wafguard/internal/core/config"
+	"wafguard/internal/packs"
+
+	"github.com/spf13/cobra"
+)
+
+func newPacksCmd() *cobra.Command {
+	packsCmd := &cobra.Command{
+		Use:   "packs",
+		Short: "Built-in attack payload packs",
+		Long:  "List and inspect the attack payload packs embedded in wafguard. Run them with: wafguard run pack:<name> --target <url>",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List built-in packs",
+		Args:  cobra.NoArgs,
+		RunE:  listPacks,
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show [name]",
+		Short: "Print a pack as SentinelTest YAML",
+		Long:  "Print a pack as SentinelTest YAML, e.g. to copy and customize it",
+		Args:  cobra.ExactArgs(1),
+		RunE:  showPack,
+	}
+
+	packsCmd.AddCommand(listCmd)
+	packsCmd.AddCommand(showCmd)
+
+	return packsCmd
+}
+
+func listPacks(cmd *cobra.Command, args []string) error {
+	list, err := packs.List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tTESTS\tDESCRIPTION")
+	for _, pack := range list {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", pack.Name, pack.Version, pack.Tests, pack.Description)
+	}
+
+	return w.Flush()
+}
+
+func showPack(cmd *cobra.Command, args []string) error {
+	data, err := packs.Raw(args[0])
+	if err != nil {
+		return err
+	}
+
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+// loadPacks parses the named packs in order.
+func loadPacks(names []string) ([]*config.SentinelTest, error) {
+	var suites []*config.SentinelTest
+	for _, name := range names {
+		suite, err := packs.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+	"wafguard/internal/echo"
+	"wafguard/internal/mockwaf"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen string
+	serveRules  string
+)
+
+func newServeCmd() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run local servers for offline testing",
+		Long:  "Run local stand-ins for a WAF or an origin, so suites can be exercised without network access",
+	}
+
+	mockWAFCmd := &cobra.Command{
+		Use:   "mock-waf",
+		Short: "Run a rule-based mock WAF",
+		Long: `Run a mock WAF that blocks requests matching regex rules and echoes the
+others back as JSON.
+
+Without --rules, built-in signatures that block every pack are used. A rule
+file sets the default block status and page and a list of rules:
+
+  blockStatus: 403
+  rules:
+    - id: 942100
+      msg: SQL injection
+      pattern: (?i)union\s+select
+      targets: [args, body]   # path, args, headers, body, header:<name>
+      status: 406             # optional, overrides blockStatus
+      blockPage: "{{status}} blocked by rule {{id}}"
+
+Targets are inspected as received and percent-decoded.`,
+		Args: cobra.NoArgs,
+		RunE: runMockWAF,
+	}
+
+	echoCmd := &cobra.Command{
+		Use:   "echo",
+		Short: "Run an origin that echoes every request",
+		Long: `Run an origin stand-in that answers every request with a JSON description
+of the request exactly as it arrived: method, request target, decoded path
+and arguments, host, headers and body.
+
+Put it behind the WAF under test and use expected.upstream in tests to
+check that allowed requests reach the origin unchanged:
+
+  expected:
+    blocked: false
+    upstream:
+      path: true
+      header: ["*"]
+      body: true`,
+		Args: cobra.NoArgs,
+		RunE: runEcho,
+	}
+
+	serveCmd.PersistentFlags().StringVarP(&serveListen, "listen", "a", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Log level (debug, info, warn, error)")
+	serveCmd.PersistentFlags().StringVarP(&logFormat, "log-format", "f", "text", "Log format (json, text)")
+
+	mockWAFCmd.Flags().StringVarP(&serveRules, "rules", "r", "", "Rule file (defaults to the built-in rules)")
+
+	serveCmd.AddCommand(mockWAFCmd)
+	serveCmd.AddCommand(echoCmd)
+
+	return serveCmd
+}
+
+func runMockWAF(cmd *cobra.Command, args []string) error {
+	setupLogger()
+
+	rules := mockwaf.DefaultRules()
+	if serveRules != "" {
+		var err error
+		if rules, err = mockwaf.LoadRules(serveRules); err != nil {
+			return err
+		}
+	}
+
+	name := fmt.Sprintf("Mock WAF (%d rules)", len(rules.Rules))
+	return serve(cmd.Context(), cmd.OutOrStdout(), serveListen, name, mockwaf.Handler(rules))
+}
+
+func runEcho(cmd *cobra.Command, args []string) error {
+	setupLogger()
+	return serve(cmd.Context(), cmd.OutOrStdout(), serveListen, "Echo origin", echo.Handler())
+}
+
+// serve runs handler on addr until ctx is cancelled. The address actually
+// bound is printed to w, so ":0" can be used to pick a free port.
+func serve(ctx context.Context, w io.Writer, addr, name string, handler http.Handler) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(w, "%s listening on http://%s\n", name, listener.Addr())
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-done
+	return nil
+}
@@ -0,0 +1,229 @@
+// Package benign replays legitimate traffic against a WAF and measures how
+// much of it gets blocked.
+package benign
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"wafguard/internal/core/config"
+	"wafguard/internal/importer"
+	"wafguard/internal/reporter"
+	"wafguard/internal/validator"
+)
+
+// Corpus formats accepted by LoadCorpus.
+const (
+	FormatAuto      = "auto"
+	FormatHAR       = "har"
+	FormatAccessLog = "log"
+	FormatURLs      = "urls"
+)
+
+// LoadCorpus reads a benign traffic corpus and returns it as a suite whose
+// tests all expect to be allowed. format is one of the Format constants;
+// auto picks HAR for .har files, access log when the first line looks like
+// one, and a URL list otherwise. baseURL is required for access logs and
+// overrides the hosts recorded in HAR files and URL lists.
+func LoadCorpus(path, format, baseURL string) (*importer.Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read corpus %s: %w", path, err)
+	}
+
+	if format == "" || format == FormatAuto {
+		format = detectFormat(path, data)
+	}
+
+	allowed := false
+	expected := config.Expected{Blocked: &allowed}
+
+	switch format {
+	case FormatHAR:
+		results, err := importer.ImportHAR(data, path, importer.HAROptions{BaseURL: baseURL, Expected: expected})
+		if err != nil {
+			return nil, err
+		}
+		if len(results) > 1 {
+			return nil, fmt.Errorf("corpus %s contains requests to %d origins, use --target to send all of them to one target", path, len(results))
+		}
+		return results[0], nil
+	case FormatAccessLog:
+		return importer.ImportAccessLog(data, path, importer.AccessLogOptions{BaseURL: baseURL, Expected: expected})
+	case FormatURLs:
+		return importer.ImportURLList(data, path, importer.URLListOptions{BaseURL: baseURL, Expected: expected})
+	default:
+		return nil, fmt.Errorf("unknown corpus format %q (use auto, har, log or urls)", format)
+	}
+}
+
+func detectFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".har":
+		return FormatHAR
+	}
+	if importer.IsAccessLog(data) {
+		return FormatAccessLog
+	}
+	return FormatURLs
+}
+
+// Endpoint aggregates the requests sent to one method and path shape.
+type Endpoint struct {
+	Endpoint string  `json:"endpoint"`
+	Requests int     `json:"requests"`
+	Blocked  int     `json:"blocked"`
+	Rate     float64 `json:"false_positive_rate"`
+}
+
+// FalsePositive is a legitimate request the WAF blocked, with whatever in
+// the response identifies why.
+type FalsePositive struct {
+	Test       string            `json:"test"`
+	Endpoint   string            `json:"endpoint"`
+	Request    *config.Request   `json:"request"`
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	RuleIDs    []string          `json:"rule_ids,omitempty"`
+	Body       string            `json:"body,omitempty"`
+}
+
+// Report summarises a benign traffic run. Rates are percentages of the
+// requests that got a response; requests that failed are only counted in
+// Errors.
+type Report struct {
+	Requests       int             `json:"requests"`
+	Blocked        int             `json:"blocked"`
+	Errors         int             `json:"errors"`
+	Rate           float64         `json:"false_positive_rate"`
+	Endpoints      []Endpoint      `json:"endpoints"`
+	FalsePositives []FalsePositive `json:"false_positives,omitempty"`
+}
+
+// maxEvidenceBody is how much of a block page is kept as evidence.
+const maxEvidenceBody = 512
+
+// evidenceHeaders identify the WAF or CDN that answered, or the request ID
+// to look up in its logs.
+var evidenceHeaders = []string{
+	"Server",
+	"X-Request-Id",
+	"X-Correlation-Id",
+	"X-Amzn-RequestId",
+	"X-Amzn-Waf-Action",
+	"X-Azure-Ref",
+	"CF-Ray",
+	"CF-Mitigated",
+	"X-Iinfo",
+	"X-Sucuri-Id",
+	"X-Sucuri-Block",
+	"Akamai-GRN",
+	"X-Akamai-Request-Id",
+	"X-Cdn",
+}
+
+// ruleID finds rule identifiers in block pages and headers, such as
+// ModSecurity's [id "942100"] or "Rule ID: 1234".
+var ruleID = regexp.MustCompile(`(?i)(?:\[id "|rule[ _-]?id\W{0,3})(\d{3,})`)
+
+// Analyze computes the false-positive rate overall and per endpoint from
+// the reports of a benign run. A test report counts as a false positive
+// when v considers its response blocked.
+func Analyze(reports []reporter.TestReport, v *validator.ResponseValidator) *Report {
+	report := &Report{}
+	endpoints := make(map[string]*Endpoint)
+
+	for _, test := range reports {
+		if test.Response == nil {
+			report.Errors++
+			continue
+		}
+
+		endpoint := test.Request.Method + " " + importer.PathShape(test.Request.Path)
+		stats, ok := endpoints[endpoint]
+		if !ok {
+			stats = &Endpoint{Endpoint: endpoint}
+			endpoints[endpoint] = stats
+		}
+
+		report.Requests++
+		stats.Requests++
+		if !v.IsBlocked(test.Response) {
+			continue
+		}
+
+		report.Blocked++
+		stats.Blocked++
+		report.FalsePositives = append(report.FalsePositives, evidence(test, endpoint))
+	}
+
+	for _, stats := range endpoints {
+		stats.Rate = rate(stats.Blocked, stats.Requests)
+		report.Endpoints = append(report.Endpoints, *stats)
+	}
+	report.Rate = rate(report.Blocked, report.Requests)
+
+	// Worst endpoints first
+	sort.Slice(report.Endpoints, func(i, j int) bool {
+		a, b := report.Endpoints[i], report.Endpoints[j]
+		if a.Rate != b.Rate {
+			return a.Rate > b.Rate
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Endpoint < b.Endpoint
+	})
+
+	return report
+}
+
+func evidence(test reporter.TestReport, endpoint string) FalsePositive {
+	fp := FalsePositive{
+		Test:       test.TestName,
+		Endpoint:   endpoint,
+		Request:    test.Request,
+		StatusCode: test.Response.StatusCode,
+	}
+
+	seen := make(map[string]bool)
+	addRuleIDs := func(s string) {
+		for _, m := range ruleID.FindAllStringSubmatch(s, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				fp.RuleIDs = append(fp.RuleIDs, m[1])
+			}
+		}
+	}
+
+	for _, name := range evidenceHeaders {
+		for key, value := range test.Response.Headers {
+			if strings.EqualFold(key, name) {
+				if fp.Headers == nil {
+					fp.Headers = make(map[string]string)
+				}
+				fp.Headers[key] = value
+				addRuleIDs(value)
+			}
+		}
+	}
+
+	addRuleIDs(test.Response.Body)
+	body := strings.Join(strings.Fields(test.Response.Body), " ")
+	if len(body) > maxEvidenceBody {
+		body = body[:maxEvidenceBody] + "..."
+	}
+	fp.Body = body
+
+	return fp
+}
+
+func rate(blocked, requests int) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return float64(blocked) / float64(requests) * 100
+}
@@ -0,0 +1,117 @@
+package benign
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/reporter"
+	"wafguard/internal/validator"
+)
+
+func benignReport(method, path string, status int, headers map[string]string, body string) reporter.TestReport {
+	return reporter.TestReport{
+		TestName: method + " " + path,
+		Request:  &config.Request{Method: method, Path: path},
+		Response: &executor.Response{StatusCode: status, Headers: headers, Body: body},
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	reports := []reporter.TestReport{
+		benignReport("GET", "/users/1", 200, nil, ""),
+		benignReport("GET", "/users/2?tab=orders", 403, map[string]string{"Cf-Ray": "8a1b2c", "Content-Type": "text/html"},
+			`<html> Access denied.   ModSecurity: [id "942100"] [msg "SQL Injection"] </html>`),
+		benignReport("GET", "/users/3", 200, nil, ""),
+		benignReport("GET", "/users/4", 200, nil, ""),
+		benignReport("POST", "/login", 200, nil, ""),
+		{TestName: "failed", Request: &config.Request{Method: "GET", Path: "/down"}},
+	}
+
+	report := Analyze(reports, validator.NewResponseValidator())
+
+	if report.Requests != 5 || report.Blocked != 1 || report.Errors != 1 {
+		t.Errorf("counts = %d requests, %d blocked, %d errors", report.Requests, report.Blocked, report.Errors)
+	}
+	if report.Rate != 20 {
+		t.Errorf("Rate = %v, want 20", report.Rate)
+	}
+
+	want := []Endpoint{
+		{Endpoint: "GET /users/{id}", Requests: 4, Blocked: 1, Rate: 25},
+		{Endpoint: "POST /login", Requests: 1, Blocked: 0, Rate: 0},
+	}
+	if len(report.Endpoints) != len(want) {
+		t.Fatalf("Endpoints = %+v", report.Endpoints)
+	}
+	for i := range want {
+		if report.Endpoints[i] != want[i] {
+			t.Errorf("Endpoints[%d] = %+v, want %+v", i, report.Endpoints[i], want[i])
+		}
+	}
+
+	if len(report.FalsePositives) != 1 {
+		t.Fatalf("FalsePositives = %+v", report.FalsePositives)
+	}
+	fp := report.FalsePositives[0]
+	if fp.StatusCode != 403 || fp.Endpoint != "GET /users/{id}" || fp.Request.Path != "/users/2?tab=orders" {
+		t.Errorf("false positive = %+v", fp)
+	}
+	if len(fp.Headers) != 1 || fp.Headers["Cf-Ray"] != "8a1b2c" {
+		t.Errorf("evidence headers = %v", fp.Headers)
+	}
+	if len(fp.RuleIDs) != 1 || fp.RuleIDs[0] != "942100" {
+		t.Errorf("RuleIDs = %v", fp.RuleIDs)
+	}
+	if fp.Body != `<html> Access denied. ModSecurity: [id "942100"] [msg "SQL Injection"] </html>` {
+		t.Errorf("Body = %q", fp.Body)
+	}
+}
+
+func TestAnalyzeBlockStatus(t *testing.T) {
+	reports := []reporter.TestReport{benignReport("GET", "/", 406, nil, "")}
+
+	if report := Analyze(reports, validator.NewResponseValidator()); report.Blocked != 0 {
+		t.Error("406 should not count as blocked by default")
+	}
+	if report := Analyze(reports, validator.NewResponseValidator().WithBlockStatus([]int{403, 406})); report.Blocked != 1 {
+		t.Error("406 should count as blocked when listed")
+	}
+}
+
+func TestLoadCorpus(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"capture.har": `{"log": {"entries": [{"request": {"method": "GET", "url": "https://shop.example.com/cart", "headers": []}, "response": {"status": 200}}]}}`,
+		"access.log":  `203.0.113.7 - - [10/Oct/2026:13:55:36 +0000] "GET /cart HTTP/1.1" 200 2326 "-" "Mozilla/5.0"` + "\n",
+		"urls.txt":    "https://shop.example.com/cart\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := LoadCorpus(path, FormatAuto, "https://staging.example.com")
+		if err != nil {
+			t.Fatalf("LoadCorpus(%s) error = %v", name, err)
+		}
+
+		tests := result.Suite.Spec.Tests
+		if len(tests) != 1 || tests[0].Request.Path != "/cart" {
+			t.Errorf("%s: tests = %+v", name, tests)
+		}
+		if blocked := tests[0].Expected.Blocked; blocked == nil || *blocked {
+			t.Errorf("%s: corpus requests should expect to be allowed", name)
+		}
+		if result.Suite.Spec.Target.BaseURL != "https://staging.example.com" {
+			t.Errorf("%s: BaseURL = %s", name, result.Suite.Spec.Target.BaseURL)
+		}
+	}
+
+	if _, err := LoadCorpus(filepath.Join(dir, "urls.txt"), "csv", ""); err == nil {
+		t.Error("LoadCorpus() expected error for an unknown format")
+	}
+}
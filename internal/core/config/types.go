@@ -13,6 +13,7 @@ type SentinelTest struct {
 
 type Metadata struct {
 	Name        string `yaml:"name" validate:"required"`
+	Version     string `yaml:"version,omitempty"`
 	Description string `yaml:"description,omitempty"`
 }
 
@@ -22,14 +23,120 @@ type Spec struct {
 }
 
 type Target struct {
-	BaseURL string        `yaml:"baseUrl" validate:"required,url"`
-	Timeout time.Duration `yaml:"timeout,omitempty"`
+	BaseURL   string        `yaml:"baseUrl" validate:"required,url"`
+	Timeout   time.Duration `yaml:"timeout,omitempty"`
+	Retry     *Retry        `yaml:"retry,omitempty"`
+	RateLimit *RateLimit    `yaml:"rateLimit,omitempty"`
+	// BlockStatus lists the statuses the WAF answers with when it blocks a
+	// request. It is used by `expected.blocked` and defaults to 403.
+	BlockStatus []int `yaml:"blockStatus,omitempty"`
+	// MaxBodySize is how many bytes of each response body are kept for
+	// validation. Longer bodies are truncated and reported as such.
+	// Defaults to 10 MiB.
+	MaxBodySize int64 `yaml:"maxBodySize,omitempty" validate:"min=0"`
+}
+
+// DefaultBlockStatus is used when a target does not set blockStatus.
+var DefaultBlockStatus = []int{403}
+
+// RateLimit caps the request rate sent to the target host so that
+// rate-based WAF rules do not mask the rule under test.
+type RateLimit struct {
+	RPS   float64 `yaml:"rps" validate:"gt=0"`
+	Burst int     `yaml:"burst,omitempty" validate:"min=0"`
 }
 
 type Test struct {
-	Name     string   `yaml:"name" validate:"required"`
-	Request  Request  `yaml:"request" validate:"required"`
-	Expected Expected `yaml:"expected" validate:"required"`
+	Name           string          `yaml:"name" validate:"required"`
+	Tags           []string        `yaml:"tags,omitempty"`
+	Request        Request         `yaml:"request" validate:"required"`
+	Expected       Expected        `yaml:"expected" validate:"required"`
+	Retry          *Retry          `yaml:"retry,omitempty"`
+	RateLimitCheck *RateLimitCheck `yaml:"rateLimitCheck,omitempty"`
+	Inject         *Inject         `yaml:"inject,omitempty"`
+	// Transforms is a chain of evasion encodings applied, left to right,
+	// to every inject payload before it is placed in the request.
+	Transforms []string `yaml:"transforms,omitempty"`
+	// Debug captures the raw request and response of the test into its
+	// report, as `wafguard run --dump` does for every test.
+	Debug bool `yaml:"debug,omitempty"`
+	// Injection is set on tests expanded from an inject block.
+	Injection *Injection `yaml:"-"`
+}
+
+// Inject expands a test into one test per payload and location. The
+// request acts as a template holding baseline values; each location names
+// where the payload goes: query:<param>, header:<name>, cookie:<name>,
+// json:<$.path>, form:<field> or path.
+type Inject struct {
+	Payloads  []string `yaml:"payloads" validate:"required,min=1"`
+	Locations []string `yaml:"locations" validate:"required,min=1"`
+}
+
+// Injection identifies the payload and location of an expanded test.
+// Payload is the payload as written; Encoded is what was sent after the
+// Transforms chain, and is empty without transforms. Template is the
+// request before the payload was placed.
+type Injection struct {
+	Test       string   `json:"test"`
+	Payload    string   `json:"payload"`
+	Location   string   `json:"location"`
+	Transforms []string `json:"transforms,omitempty"`
+	Encoded    string   `json:"encoded,omitempty"`
+	Template   Request  `json:"-"`
+}
+
+// RateLimitCheck turns a test into a rate-based rule verification. The
+// request is sent up to Requests times and the WAF must start blocking no
+// later than request BlockWithin and, if set, within Window of the first
+// request. A response counts as blocked when its status is one of the
+// target's BlockStatus if Expected.Blocked is true, and one of
+// Expected.Status otherwise. Sending stops as soon as blocking is observed.
+// Only RPS paces the requests; the target's RateLimit does not apply.
+type RateLimitCheck struct {
+	Requests    int           `yaml:"requests" validate:"min=1"`
+	RPS         float64       `yaml:"rps,omitempty" validate:"min=0"`
+	Concurrency int           `yaml:"concurrency,omitempty" validate:"min=0"`
+	BlockWithin int           `yaml:"blockWithin,omitempty" validate:"min=0"`
+	Window      time.Duration `yaml:"window,omitempty"`
+}
+
+// Retry controls how a test is re-attempted. Execution errors are always
+// retried; validation failures only when OnValidationFailure is set. The
+// wait between attempts starts at Backoff and doubles up to MaxBackoff.
+type Retry struct {
+	Retries             int           `yaml:"retries" validate:"min=0"`
+	Backoff             time.Duration `yaml:"backoff,omitempty"`
+	MaxBackoff          time.Duration `yaml:"maxBackoff,omitempty"`
+	OnValidationFailure bool          `yaml:"onValidationFailure,omitempty"`
+}
+
+// RetryPolicy returns the retry settings for the test, preferring the
+// test's own block over the target default.
+func (t *Test) RetryPolicy(target Target) Retry {
+	if t.Retry != nil {
+		return *t.Retry
+	}
+	if target.Retry != nil {
+		return *target.Retry
+	}
+	return Retry{}
+}
+
+// BackoffFor returns how long to wait before the given retry (1-based).
+func (r Retry) BackoffFor(retry int) time.Duration {
+	if r.Backoff <= 0 {
+		return 0
+	}
+
+	wait := r.Backoff
+	for i := 1; i < retry && (r.MaxBackoff <= 0 || wait < r.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if r.MaxBackoff > 0 && wait > r.MaxBackoff {
+		wait = r.MaxBackoff
+	}
+	return wait
 }
 
 type Request struct {
@@ -39,10 +146,39 @@ type Request struct {
 	Body    string            `yaml:"body,omitempty"`
 }
 
+// Expected describes the assertions run against a response. Blocked checks
+// the WAF decision against the target's blockStatus instead of pinning an
+// exact status code; at least one of Status and Blocked is required.
 type Expected struct {
-	Status  []int             `yaml:"status" validate:"required,min=1"`
-	Headers map[string]string `yaml:"headers,omitempty"`
-	Body    *BodyExpected     `yaml:"body,omitempty"`
+	Status   []int             `yaml:"status,omitempty" validate:"required_without=Blocked,omitempty,min=1"`
+	Blocked  *bool             `yaml:"blocked,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty"`
+	Body     *BodyExpected     `yaml:"body,omitempty"`
+	Upstream *Upstream         `yaml:"upstream,omitempty"`
+	Latency  *Latency          `yaml:"latency,omitempty"`
+}
+
+// Upstream checks that an allowed request reached the origin as it was
+// sent, so WAFs that normalize or sanitize requests are caught. The origin
+// must echo requests the way `wafguard serve echo` does. Header lists the
+// headers to compare; "*" selects every header the test sends.
+type Upstream struct {
+	Path   bool     `yaml:"path,omitempty"`
+	Header []string `yaml:"header,omitempty"`
+	Body   bool     `yaml:"body,omitempty"`
+}
+
+// Latency sets upper bounds on the response time and its phases, e.g. to
+// catch WAF rules that slow requests down. Bounds left unset are not
+// checked. TTFB counts from the start of the request, so it includes the
+// DNS, connect and TLS phases.
+type Latency struct {
+	Total    time.Duration `yaml:"total,omitempty"`
+	DNS      time.Duration `yaml:"dns,omitempty"`
+	Connect  time.Duration `yaml:"connect,omitempty"`
+	TLS      time.Duration `yaml:"tls,omitempty"`
+	TTFB     time.Duration `yaml:"ttfb,omitempty"`
+	Transfer time.Duration `yaml:"transfer,omitempty"`
 }
 
 type BodyExpected struct {
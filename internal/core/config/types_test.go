@@ -345,4 +345,105 @@ spec:
 	if sentinelTest.Spec.Target.Timeout != expectedTimeout {
 		t.Errorf("Timeout mismatch: got %v, want %v", sentinelTest.Spec.Target.Timeout, expectedTimeout)
 	}
-}
\ No newline at end of file
+}
+
+func TestRetryPolicy(t *testing.T) {
+	targetRetry := &Retry{Retries: 2}
+	testRetry := &Retry{Retries: 5}
+
+	tests := []struct {
+		name        string
+		test        Test
+		target      Target
+		wantRetries int
+	}{
+		{
+			name:        "no retry configured",
+			test:        Test{},
+			target:      Target{},
+			wantRetries: 0,
+		},
+		{
+			name:        "target default",
+			test:        Test{},
+			target:      Target{Retry: targetRetry},
+			wantRetries: 2,
+		},
+		{
+			name:        "test overrides target",
+			test:        Test{Retry: testRetry},
+			target:      Target{Retry: targetRetry},
+			wantRetries: 5,
+		},
+		{
+			name:        "test disables retries",
+			test:        Test{Retry: &Retry{Retries: 0}},
+			target:      Target{Retry: targetRetry},
+			wantRetries: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.test.RetryPolicy(tt.target)
+			if got.Retries != tt.wantRetries {
+				t.Errorf("RetryPolicy() retries = %d, want %d", got.Retries, tt.wantRetries)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		retry Retry
+		n     int
+		want  time.Duration
+	}{
+		{"no backoff", Retry{}, 3, 0},
+		{"first retry", Retry{Backoff: 100 * time.Millisecond}, 1, 100 * time.Millisecond},
+		{"exponential", Retry{Backoff: 100 * time.Millisecond}, 3, 400 * time.Millisecond},
+		{"capped", Retry{Backoff: 100 * time.Millisecond, MaxBackoff: 250 * time.Millisecond}, 3, 250 * time.Millisecond},
+		{"cap above base", Retry{Backoff: time.Second, MaxBackoff: 500 * time.Millisecond}, 1, 500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.retry.BackoffFor(tt.n); got != tt.want {
+				t.Errorf("BackoffFor(%d) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryYAMLUnmarshaling(t *testing.T) {
+	yamlContent := `
+baseUrl: https://example.com
+retry:
+  retries: 3
+  backoff: 250ms
+  maxBackoff: 2s
+  onValidationFailure: true
+`
+
+	var target Target
+	if err := yaml.Unmarshal([]byte(yamlContent), &target); err != nil {
+		t.Fatalf("Failed to unmarshal target: %v", err)
+	}
+
+	if target.Retry == nil {
+		t.Fatal("Target.Retry should be set")
+	}
+	if target.Retry.Retries != 3 {
+		t.Errorf("Retries = %d, want 3", target.Retry.Retries)
+	}
+	if target.Retry.Backoff != 250*time.Millisecond {
+		t.Errorf("Backoff = %v, want 250ms", target.Retry.Backoff)
+	}
+	if target.Retry.MaxBackoff != 2*time.Second {
+		t.Errorf("MaxBackoff = %v, want 2s", target.Retry.MaxBackoff)
+	}
+	if !target.Retry.OnValidationFailure {
+		t.Error("OnValidationFailure should be true")
+	}
+}
@@ -0,0 +1,92 @@
+// Package echo describes HTTP requests as received by a server, so that
+// stand-in origins can send back exactly what reached them.
+package echo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// MaxBodySize is how much of a request body is read and echoed.
+const MaxBodySize = 10 << 20
+
+// Request is a received request as echoed in JSON. URI is the request
+// target exactly as it appeared in the request line. Bodies that are not
+// valid UTF-8 are sent in BodyBase64 instead of Body.
+type Request struct {
+	Method     string              `json:"method"`
+	URI        string              `json:"uri"`
+	Path       string              `json:"path"`
+	Args       map[string][]string `json:"args"`
+	Host       string              `json:"host"`
+	Headers    map[string][]string `json:"headers"`
+	Body       string              `json:"body"`
+	BodyBase64 string              `json:"body_base64,omitempty"`
+}
+
+// RawBody returns the received body.
+func (r *Request) RawBody() ([]byte, error) {
+	if r.BodyBase64 != "" {
+		return base64.StdEncoding.DecodeString(r.BodyBase64)
+	}
+	return []byte(r.Body), nil
+}
+
+// Handler echoes every request it receives.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ReadBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		Write(w, Capture(r, body))
+	})
+}
+
+// ReadBody reads up to MaxBodySize bytes of the request body.
+func ReadBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	return body, nil
+}
+
+// Capture describes r, whose body has already been read into body.
+func Capture(r *http.Request, body []byte) *Request {
+	request := &Request{
+		Method:  r.Method,
+		URI:     r.RequestURI,
+		Path:    r.URL.Path,
+		Args:    r.URL.Query(),
+		Host:    r.Host,
+		Headers: r.Header,
+	}
+	if utf8.Valid(body) {
+		request.Body = string(body)
+	} else {
+		request.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+	return request
+}
+
+// Write sends request as an indented JSON document with status 200.
+func Write(w http.ResponseWriter, request *Request) {
+	data, err := json.MarshalIndent(request, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(append(data, '\n'))
+}
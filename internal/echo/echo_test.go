@@ -0,0 +1,101 @@
+package echo
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	// A raw request keeps the target exactly as written, encoding included
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	body := "a=1&b=<x>"
+	raw := "PUT /a%2Fb/c?q=%27x%27&q=2&empty HTTP/1.1\r\n" +
+		"Host: origin.example\r\n" +
+		"X-Token: one\r\n" +
+		"X-Token: two\r\n" +
+		"Content-Length: 9\r\n" +
+		"Connection: close\r\n" +
+		"\r\n" + body
+	if _, err := conn.Write([]byte(raw)); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("status = %d, Content-Type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	var got Request
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if got.Method != "PUT" {
+		t.Errorf("Method = %q, want PUT", got.Method)
+	}
+	if got.URI != "/a%2Fb/c?q=%27x%27&q=2&empty" {
+		t.Errorf("URI = %q, want the request target as sent", got.URI)
+	}
+	if got.Path != "/a/b/c" {
+		t.Errorf("Path = %q, want /a/b/c", got.Path)
+	}
+	if want := map[string][]string{"q": {"'x'", "2"}, "empty": {""}}; !reflect.DeepEqual(got.Args, want) {
+		t.Errorf("Args = %v, want %v", got.Args, want)
+	}
+	if got.Host != "origin.example" {
+		t.Errorf("Host = %q, want origin.example", got.Host)
+	}
+	if !reflect.DeepEqual(got.Headers["X-Token"], []string{"one", "two"}) {
+		t.Errorf("X-Token = %v, want both values in order", got.Headers["X-Token"])
+	}
+	if got.Body != body || got.BodyBase64 != "" {
+		t.Errorf("Body = %q, BodyBase64 = %q, want %q", got.Body, got.BodyBase64, body)
+	}
+}
+
+func TestHandlerBinaryBody(t *testing.T) {
+	server := httptest.NewServer(Handler())
+	defer server.Close()
+
+	body := []byte{0xff, 0x00, 0xfe}
+	resp, err := http.Post(server.URL+"/upload", "application/octet-stream", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var got Request
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if got.Body != "" || got.BodyBase64 == "" {
+		t.Errorf("Body = %q, BodyBase64 = %q, want the body in BodyBase64", got.Body, got.BodyBase64)
+	}
+	raw, err := got.RawBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != string(body) {
+		t.Errorf("RawBody() = %x, want %x", raw, body)
+	}
+}
@@ -0,0 +1,226 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrNotRecorded is returned by a strict replay cassette for requests that
+// were not recorded.
+var ErrNotRecorded = errors.New("no recorded response")
+
+// Cassette stores responses in a directory, one JSON file per request
+// fingerprint, so a run can be recorded once and replayed later without
+// network access. A request sent several times keeps every response in
+// order; on replay they are served in the same order and the last one is
+// repeated, so retries and rate-limit checks replay as recorded.
+type Cassette struct {
+	dir    string
+	replay bool
+	strict bool
+
+	mu      sync.Mutex
+	entries map[string]*cassetteEntry
+	served  map[string]int
+	misses  int
+}
+
+type cassetteEntry struct {
+	Request   cassetteRequest     `json:"request"`
+	Responses []*cassetteResponse `json:"responses"`
+}
+
+type cassetteRequest struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+// cassetteResponse is a recorded Response. Bodies that are not valid UTF-8
+// are kept in BodyBase64 so they survive the JSON round trip.
+type cassetteResponse struct {
+	Response
+	BodyBase64 string `json:",omitempty"`
+}
+
+// NewRecordingCassette records responses into dir, creating it if needed.
+// Files of requests sent again are overwritten.
+func NewRecordingCassette(dir string) (*Cassette, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	return &Cassette{
+		dir:     dir,
+		entries: make(map[string]*cassetteEntry),
+	}, nil
+}
+
+// LoadCassette loads the responses recorded in dir for replay. Requests
+// that were not recorded are sent over the network, or fail with
+// ErrNotRecorded if strict is set.
+func LoadCassette(dir string, strict bool) (*Cassette, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded responses found in %s", dir)
+	}
+
+	c := &Cassette{
+		dir:     dir,
+		replay:  true,
+		strict:  strict,
+		entries: make(map[string]*cassetteEntry, len(files)),
+		served:  make(map[string]int),
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recorded response: %w", err)
+		}
+
+		var entry cassetteEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse recorded response %s: %w", file, err)
+		}
+		if len(entry.Responses) == 0 {
+			return nil, fmt.Errorf("recorded response %s has no responses", file)
+		}
+
+		fingerprint := strings.TrimSuffix(filepath.Base(file), ".json")
+		c.entries[fingerprint] = &entry
+	}
+
+	return c, nil
+}
+
+// Replaying reports whether the cassette serves responses rather than
+// recording them.
+func (c *Cassette) Replaying() bool {
+	return c.replay
+}
+
+// Misses returns how many requests had no recorded response.
+func (c *Cassette) Misses() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.misses
+}
+
+// lookup returns the next recorded response for the request with the
+// given fingerprint, or nil if it was not recorded and the cassette is not
+// strict.
+func (c *Cassette) lookup(fingerprint string, req *http.Request) (*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[fingerprint]
+	if !ok {
+		c.misses++
+		if c.strict {
+			return nil, fmt.Errorf("%w for %s %s in %s", ErrNotRecorded, req.Method, req.URL, c.dir)
+		}
+		return nil, nil
+	}
+
+	index := c.served[fingerprint]
+	if index >= len(entry.Responses) {
+		index = len(entry.Responses) - 1
+	}
+	c.served[fingerprint]++
+
+	recorded := entry.Responses[index]
+	response := recorded.Response
+	if recorded.BodyBase64 != "" {
+		body, err := base64.StdEncoding.DecodeString(recorded.BodyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recorded body for %s %s: %w", req.Method, req.URL, err)
+		}
+		response.Body = string(body)
+	}
+
+	return &response, nil
+}
+
+// record appends response to the entry of the request with the given
+// fingerprint and rewrites its file.
+func (c *Cassette) record(fingerprint string, req *http.Request, body string, response *Response) error {
+	recorded := &cassetteResponse{Response: *response}
+	recorded.Request = nil
+	if !utf8.ValidString(recorded.Body) {
+		recorded.BodyBase64 = base64.StdEncoding.EncodeToString([]byte(recorded.Body))
+		recorded.Body = ""
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[fingerprint]
+	if !ok {
+		entry = &cassetteEntry{
+			Request: cassetteRequest{
+				Method:  req.Method,
+				URL:     req.URL.String(),
+				Headers: req.Header,
+				Body:    body,
+			},
+		}
+		c.entries[fingerprint] = entry
+	}
+	entry.Responses = append(entry.Responses, recorded)
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recorded response: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, fingerprint+".json"), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write recorded response: %w", err)
+	}
+	return nil
+}
+
+// Fingerprint identifies a request for recording and replay. The method,
+// scheme and host are case-normalized, default ports and fragments are
+// dropped and headers are sorted; the path, query and body are used byte
+// for byte, since WAF verdicts depend on their exact encoding.
+func Fingerprint(req *http.Request, body string) string {
+	u := req.URL
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	target := (&url.URL{Path: u.Path, RawPath: u.RawPath, RawQuery: u.RawQuery, ForceQuery: u.ForceQuery}).RequestURI()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s://%s%s\n", strings.ToUpper(req.Method), strings.ToLower(u.Scheme), host, target)
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", http.CanonicalHeaderKey(name), strings.Join(req.Header[name], ", "))
+	}
+	b.WriteString("\n")
+	b.WriteString(body)
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,175 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"wafguard/internal/core/config"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		w.Header().Set("X-Hit", fmt.Sprint(n))
+		if r.URL.Query().Get("q") == "binary" {
+			_, _ = w.Write([]byte{0xff, 0x00, 0xfe})
+			return
+		}
+		w.WriteHeader(200 + int(n))
+	}))
+
+	dir := t.TempDir()
+	recorder, err := NewRecordingCassette(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []*config.Test{
+		{Name: "first", Request: config.Request{Method: "GET", Path: "/?q=a"}},
+		{Name: "repeat", Request: config.Request{Method: "GET", Path: "/?q=a"}},
+		{Name: "binary", Request: config.Request{Method: "GET", Path: "/?q=binary"}},
+		{Name: "post", Request: config.Request{Method: "POST", Path: "/?q=a", Body: "x=1"}},
+	}
+
+	exec := NewHTTPExecutor(0, WithCassette(recorder))
+	var recorded []*Response
+	for _, test := range tests {
+		resp, err := exec.ExecuteTest(context.Background(), test, server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorded = append(recorded, resp)
+	}
+	server.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 3 {
+		t.Fatalf("recorded %d files, want 3", len(files))
+	}
+
+	player, err := LoadCassette(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec = NewHTTPExecutor(0, WithCassette(player))
+	for i, test := range tests {
+		resp, err := exec.ExecuteTest(context.Background(), test, server.URL)
+		if err != nil {
+			t.Fatalf("%s: %v", test.Name, err)
+		}
+		want := recorded[i]
+		if resp.StatusCode != want.StatusCode || resp.Body != want.Body || resp.Headers["X-Hit"] != want.Headers["X-Hit"] {
+			t.Errorf("%s: replayed %d %q (hit %s), want %d %q (hit %s)", test.Name,
+				resp.StatusCode, resp.Body, resp.Headers["X-Hit"], want.StatusCode, want.Body, want.Headers["X-Hit"])
+		}
+		if resp.Request != &test.Request {
+			t.Errorf("%s: replayed response does not reference the sent request", test.Name)
+		}
+	}
+
+	// Responses beyond the recorded ones repeat the last
+	resp, err := exec.ExecuteTest(context.Background(), tests[0], server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Headers["X-Hit"] != "2" {
+		t.Errorf("extra replay served hit %s, want 2", resp.Headers["X-Hit"])
+	}
+}
+
+func TestCassetteStrictReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	recorder, err := NewRecordingCassette(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := &config.Test{Name: "recorded", Request: config.Request{Method: "GET", Path: "/a", Headers: map[string]string{"X-Token": "1"}}}
+	if _, err := NewHTTPExecutor(0, WithCassette(recorder)).ExecuteTest(context.Background(), recorded, server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	unrecorded := []*config.Test{
+		{Name: "path", Request: config.Request{Method: "GET", Path: "/b", Headers: map[string]string{"X-Token": "1"}}},
+		{Name: "encoding", Request: config.Request{Method: "GET", Path: "/%61", Headers: map[string]string{"X-Token": "1"}}},
+		{Name: "header", Request: config.Request{Method: "GET", Path: "/a", Headers: map[string]string{"X-Token": "2"}}},
+		{Name: "body", Request: config.Request{Method: "GET", Path: "/a", Headers: map[string]string{"X-Token": "1"}, Body: "x"}},
+	}
+
+	strict, err := LoadCassette(dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec := NewHTTPExecutor(0, WithCassette(strict))
+	for _, test := range unrecorded {
+		if _, err := exec.ExecuteTest(context.Background(), test, server.URL); !errors.Is(err, ErrNotRecorded) {
+			t.Errorf("%s: error = %v, want ErrNotRecorded", test.Name, err)
+		}
+	}
+	if strict.Misses() != len(unrecorded) {
+		t.Errorf("Misses() = %d, want %d", strict.Misses(), len(unrecorded))
+	}
+
+	lenient, err := LoadCassette(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := NewHTTPExecutor(0, WithCassette(lenient)).ExecuteTest(context.Background(), unrecorded[0], server.URL)
+	if err != nil {
+		t.Fatalf("non-strict replay did not send the request: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || lenient.Misses() != 1 {
+		t.Errorf("status = %d, misses = %d, want 200 and 1", resp.StatusCode, lenient.Misses())
+	}
+}
+
+func TestFingerprintNormalization(t *testing.T) {
+	fingerprint := func(method, rawURL string, headers map[string]string) string {
+		req, err := http.NewRequest(method, rawURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, value := range headers {
+			req.Header[name] = []string{value}
+		}
+		return Fingerprint(req, "")
+	}
+
+	base := fingerprint("GET", "http://example.com/a?x=1", map[string]string{"Accept": "*/*", "X-A": "1"})
+	same := []string{
+		fingerprint("get", "HTTP://EXAMPLE.com:80/a?x=1#frag", map[string]string{"X-A": "1", "Accept": "*/*"}),
+	}
+	for _, fp := range same {
+		if fp != base {
+			t.Errorf("fingerprint %s differs from %s for an equivalent request", fp, base)
+		}
+	}
+
+	different := []string{
+		fingerprint("GET", "http://example.com/A?x=1", map[string]string{"Accept": "*/*", "X-A": "1"}),
+		fingerprint("GET", "http://example.com/a?x=%31", map[string]string{"Accept": "*/*", "X-A": "1"}),
+		fingerprint("GET", "http://example.com:8080/a?x=1", map[string]string{"Accept": "*/*", "X-A": "1"}),
+		fingerprint("GET", "http://example.com/a?x=1", map[string]string{"Accept": "*/*"}),
+	}
+	for _, fp := range different {
+		if fp == base {
+			t.Errorf("fingerprint %s matches a different request", fp)
+		}
+	}
+}
+
+func TestLoadCassetteEmpty(t *testing.T) {
+	if _, err := LoadCassette(t.TempDir(), false); err == nil {
+		t.Error("LoadCassette() of an empty directory succeeded")
+	}
+}
@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+)
+
+// DefaultDumpSize is how much of each side of an exchange is dumped when
+// no size is configured.
+const DefaultDumpSize = 64 << 10
+
+// Dump is the raw HTTP exchange of a test: the request as serialized on the
+// wire, including headers added by the client such as Content-Length and
+// User-Agent, and the response as received. After a redirect the response
+// is the final one. Each side is cut to the configured size.
+type Dump struct {
+	Request  string `json:"request"`
+	Response string `json:"response"`
+	// Truncated is set if either side was cut.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+func newDump(request []byte, resp *http.Response, body []byte, maxSize int) *Dump {
+	// The body has already been read, so only the head is dumped from resp
+	head, err := httputil.DumpResponse(resp, false)
+	if err != nil {
+		head = []byte(fmt.Sprintf("%s %s\r\n\r\n", resp.Proto, resp.Status))
+	}
+
+	dump := &Dump{}
+	var cut bool
+	dump.Request, cut = truncateDump(request, maxSize)
+	dump.Truncated = cut
+	dump.Response, cut = truncateDump(append(head, body...), maxSize)
+	dump.Truncated = dump.Truncated || cut
+	return dump
+}
+
+func truncateDump(data []byte, maxSize int) (string, bool) {
+	if len(data) <= maxSize {
+		return string(data), false
+	}
+	return fmt.Sprintf("%s\n[truncated, %d of %d bytes shown]", data[:maxSize], maxSize, len(data)), true
+}
@@ -0,0 +1,22 @@
+// Package executor sends the requests described by tests and captures the
+// responses for validation.
+package executor
+
+import (
+	"context"
+	"wafguard/internal/core/config"
+)
+
+// Executor sends test requests. HTTPExecutor is the implementation used by
+// the CLI and the client; other implementations can stand in for it, for
+// example to run suites against an in-process handler.
+type Executor interface {
+	// ExecuteTest sends the test request to baseURL.
+	ExecuteTest(ctx context.Context, test *config.Test, baseURL string, opts ...RequestOption) (*Response, error)
+	// ExecuteRateLimitCheck sends the test request repeatedly as described
+	// by test.RateLimitCheck. blockStatus is the target's blockStatus, used
+	// when the test expects blocked: true.
+	ExecuteRateLimitCheck(ctx context.Context, test *config.Test, baseURL string, blockStatus []int) (*RateLimitResult, error)
+}
+
+var _ Executor = (*HTTPExecutor)(nil)
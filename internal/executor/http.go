@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
+	"net/http/httputil"
 	"net/url"
 	"strings"
 	"time"
@@ -15,32 +17,170 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// DefaultMaxBodySize is how much of a response body is kept when no other
+// limit is set.
+const DefaultMaxBodySize = 10 << 20
+
 type HTTPExecutor struct {
-	client *http.Client
+	client       *http.Client
+	transport    http.RoundTripper
+	rateLimiters *RateLimiters
+	cassette     *Cassette
+}
+
+// Option configures an HTTPExecutor.
+type Option func(*HTTPExecutor)
+
+// WithRateLimiters paces every request through the shared per-host
+// limiters, so several executors can share one request budget.
+func WithRateLimiters(limiters *RateLimiters) Option {
+	return func(e *HTTPExecutor) {
+		e.rateLimiters = limiters
+	}
+}
+
+// WithHTTPClient sends requests through client instead of a client of the
+// executor's own. A copy is used, and if client has no timeout the one
+// passed to NewHTTPExecutor is applied to the copy.
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *HTTPExecutor) {
+		c := *client
+		e.client = &c
+	}
+}
+
+// WithTransport sends requests through transport, e.g. to add proxies,
+// TLS settings or instrumentation, or to serve responses in-process. It
+// also applies to a client given with WithHTTPClient, in either order.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(e *HTTPExecutor) {
+		e.transport = transport
+	}
+}
+
+// WithCassette records every response into cassette, or serves responses
+// from it instead of sending requests when it is a replay cassette.
+func WithCassette(cassette *Cassette) Option {
+	return func(e *HTTPExecutor) {
+		e.cassette = cassette
+	}
 }
 
 type Response struct {
+	URL        string
+	Proto      string
 	StatusCode int
 	Headers    map[string]string
 	Body       string
-	Duration   time.Duration
+	// Truncated is set if Body holds only the first bytes of a response
+	// body larger than the size limit.
+	Truncated bool `json:",omitempty"`
+	// Started is when the request was sent. Replayed responses have none.
+	Started  time.Time `json:"-"`
+	Duration time.Duration
+	// Timings breaks Duration down into phases.
+	Timings *Timings `json:",omitempty"`
+	// Dump is the raw exchange, captured with WithDump.
+	Dump *Dump `json:"-"`
+	// Request is the request that was sent, for comparing with what the
+	// origin received.
+	Request *config.Request `json:"-"`
 }
 
-func NewHTTPExecutor(timeout time.Duration) *HTTPExecutor {
+func NewHTTPExecutor(timeout time.Duration, opts ...Option) *HTTPExecutor {
 	if timeout == 0 {
 		timeout = 30 * time.Second
 	}
 
-	return &HTTPExecutor{
-		client: &http.Client{
-			Timeout: timeout,
-		},
+	e := &HTTPExecutor{
+		client: &http.Client{},
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if e.transport != nil {
+		e.client.Transport = e.transport
+	}
+	if e.client.Timeout == 0 {
+		e.client.Timeout = timeout
 	}
+
+	return e
 }
 
-func (e *HTTPExecutor) ExecuteTest(test *config.Test, baseURL string) (*Response, error) {
-	start := time.Now()
-	
+// RequestOption adjusts how a single request is executed.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	timeout       time.Duration
+	maxBodySize   int64
+	beforeSend    []func(*http.Request) error
+	afterResponse []func(*Response)
+	dumpSize      int
+	// unpaced skips the shared rate limiters, for requests that are paced
+	// by their caller.
+	unpaced bool
+}
+
+// WithRequestTimeout bounds the request, including reading the response
+// body. It can only shorten the executor's timeout. Time spent waiting for
+// the rate limiter is not counted.
+func WithRequestTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithMaxBodySize keeps at most size bytes of the response body instead of
+// DefaultMaxBodySize. The rest is discarded unread and Response.Truncated
+// is set.
+func WithMaxBodySize(size int64) RequestOption {
+	return func(o *requestOptions) {
+		o.maxBodySize = size
+	}
+}
+
+// WithBeforeSend calls hook with the request just before it is sent, e.g.
+// to sign it. An error from the hook aborts the request.
+func WithBeforeSend(hook func(*http.Request) error) RequestOption {
+	return func(o *requestOptions) {
+		o.beforeSend = append(o.beforeSend, hook)
+	}
+}
+
+// WithAfterResponse calls hook with the response, including responses
+// served from a replay cassette.
+func WithAfterResponse(hook func(*Response)) RequestOption {
+	return func(o *requestOptions) {
+		o.afterResponse = append(o.afterResponse, hook)
+	}
+}
+
+// WithDump captures the request as serialized on the wire and the
+// response as received into Response.Dump, keeping at most maxSize bytes
+// of each. Replayed responses have no dump.
+func WithDump(maxSize int) RequestOption {
+	return func(o *requestOptions) {
+		o.dumpSize = maxSize
+	}
+}
+
+func withoutRateLimit() RequestOption {
+	return func(o *requestOptions) {
+		o.unpaced = true
+	}
+}
+
+// ExecuteTest sends the test request to baseURL. Cancelling ctx aborts the
+// request, or the wait for the rate limiter before it.
+func (e *HTTPExecutor) ExecuteTest(ctx context.Context, test *config.Test, baseURL string, opts ...RequestOption) (*Response, error) {
+	options := &requestOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	logger.WithFields(logrus.Fields{
 		"test_name": test.Name,
 		"method":    test.Request.Method,
@@ -57,25 +197,89 @@ func (e *HTTPExecutor) ExecuteTest(test *config.Test, baseURL string) (*Response
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	// The fingerprint is taken before hooks run, since they may add
+	// volatile headers such as signatures
+	var fingerprint string
+	if e.cassette != nil {
+		fingerprint = Fingerprint(req, test.Request.Body)
+	}
+
+	if replayed, err := e.replay(test, req, fingerprint); replayed != nil || err != nil {
+		if replayed != nil {
+			options.runAfterResponse(replayed)
+		}
+		return replayed, err
+	}
+
+	if !options.unpaced {
+		if err := e.waitForRateLimit(ctx, req.URL.Host); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+		defer cancel()
+	}
+	req = req.WithContext(ctx)
+
+	for _, hook := range options.beforeSend {
+		if err := hook(req); err != nil {
+			return nil, fmt.Errorf("before-send hook failed: %w", err)
+		}
+	}
+
+	var requestDump []byte
+	if options.dumpSize > 0 {
+		if requestDump, err = httputil.DumpRequestOut(req, true); err != nil {
+			return nil, fmt.Errorf("failed to dump request: %w", err)
+		}
+	}
+
+	// Time spent waiting for the rate limiter is not part of the response time
+	start := time.Now()
+	trace := newTracer(start)
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace.clientTrace()))
+
 	resp, err := e.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	body, err := io.ReadAll(resp.Body)
+	maxBodySize := options.maxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	body, truncated, err := readBody(resp.Body, maxBodySize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
-	duration := time.Since(start)
+	end := time.Now()
+	duration := end.Sub(start)
 
 	response := &Response{
+		URL:        fullURL,
+		Proto:      resp.Proto,
 		StatusCode: resp.StatusCode,
 		Headers:    e.extractHeaders(resp.Header),
 		Body:       string(body),
+		Truncated:  truncated,
+		Started:    start,
 		Duration:   duration,
+		Timings:    trace.finish(end),
+		Request:    &test.Request,
+	}
+	if options.dumpSize > 0 {
+		response.Dump = newDump(requestDump, resp, body, options.dumpSize)
+	}
+
+	if err := e.record(test, req, fingerprint, response); err != nil {
+		return nil, err
 	}
+	options.runAfterResponse(response)
 
 	logger.WithFields(logrus.Fields{
 		"test_name":   test.Name,
@@ -86,56 +290,72 @@ func (e *HTTPExecutor) ExecuteTest(test *config.Test, baseURL string) (*Response
 	return response, nil
 }
 
-func (e *HTTPExecutor) ExecuteTestWithContext(ctx context.Context, test *config.Test, baseURL string) (*Response, error) {
-	start := time.Now()
-	
-	logger.WithFields(logrus.Fields{
-		"test_name": test.Name,
-		"method":    test.Request.Method,
-		"path":      test.Request.Path,
-	}).Info("Executing test with context")
-
-	fullURL, err := e.buildURL(baseURL, test.Request.Path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build URL: %w", err)
+func (o *requestOptions) runAfterResponse(response *Response) {
+	for _, hook := range o.afterResponse {
+		hook(response)
 	}
+}
 
-	req, err := e.createRequest(test.Request, fullURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// readBody reads at most maxSize bytes of the response body into a
+// bounded buffer, reporting whether the body was longer.
+func readBody(r io.Reader, maxSize int64) ([]byte, bool, error) {
+	var body bytes.Buffer
+	if _, err := io.Copy(&body, io.LimitReader(r, maxSize+1)); err != nil {
+		return nil, false, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	req = req.WithContext(ctx)
-
-	resp, err := e.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+	if int64(body.Len()) > maxSize {
+		body.Truncate(int(maxSize))
+		return body.Bytes(), true, nil
 	}
-	defer func() { _ = resp.Body.Close() }()
+	return body.Bytes(), false, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+// replay returns the recorded response for req, or nil if the request has
+// to be sent.
+func (e *HTTPExecutor) replay(test *config.Test, req *http.Request, fingerprint string) (*Response, error) {
+	if e.cassette == nil || !e.cassette.Replaying() {
+		return nil, nil
 	}
 
-	duration := time.Since(start)
-
-	response := &Response{
-		StatusCode: resp.StatusCode,
-		Headers:    e.extractHeaders(resp.Header),
-		Body:       string(body),
-		Duration:   duration,
+	response, err := e.cassette.lookup(fingerprint, req)
+	if err != nil || response == nil {
+		if err == nil {
+			logger.WithFields(logrus.Fields{
+				"test_name": test.Name,
+				"url":       req.URL.String(),
+			}).Warn("No recorded response, sending request")
+		}
+		return nil, err
 	}
+	response.Request = &test.Request
 
 	logger.WithFields(logrus.Fields{
 		"test_name":   test.Name,
 		"status_code": response.StatusCode,
-		"duration":    duration.String(),
-	}).Info("Test executed with context")
+	}).Info("Test replayed")
 
 	return response, nil
 }
 
+func (e *HTTPExecutor) record(test *config.Test, req *http.Request, fingerprint string, response *Response) error {
+	if e.cassette == nil || e.cassette.Replaying() {
+		return nil
+	}
+	return e.cassette.record(fingerprint, req, test.Request.Body, response)
+}
+
+func (e *HTTPExecutor) waitForRateLimit(ctx context.Context, host string) error {
+	if e.rateLimiters == nil {
+		return nil
+	}
+
+	if err := e.rateLimiters.Wait(ctx, host); err != nil {
+		return fmt.Errorf("rate limiter wait aborted: %w", err)
+	}
+	return nil
+}
+
 func (e *HTTPExecutor) buildURL(baseURL, path string) (string, error) {
 	base, err := url.Parse(baseURL)
 	if err != nil {
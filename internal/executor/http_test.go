@@ -2,8 +2,11 @@ package executor
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 	"wafguard/internal/core/config"
@@ -236,7 +239,7 @@ func TestExecuteTest(t *testing.T) {
 		},
 	}
 
-	response, err := executor.ExecuteTest(test, server.URL)
+	response, err := executor.ExecuteTest(context.Background(), test, server.URL)
 	if err != nil {
 		t.Fatalf("ExecuteTest() failed: %v", err)
 	}
@@ -264,9 +267,17 @@ func TestExecuteTest(t *testing.T) {
 	if response.Duration <= 0 {
 		t.Error("ExecuteTest() duration should be positive")
 	}
+
+	if response.URL != server.URL+"/test" {
+		t.Errorf("ExecuteTest() URL = %s, want %s", response.URL, server.URL+"/test")
+	}
+
+	if response.Proto != "HTTP/1.1" {
+		t.Errorf("ExecuteTest() Proto = %s, want HTTP/1.1", response.Proto)
+	}
 }
 
-func TestExecuteTestWithContext(t *testing.T) {
+func TestExecuteTestContext(t *testing.T) {
 	// Create a test server with delay
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(100 * time.Millisecond) // Small delay
@@ -287,22 +298,22 @@ func TestExecuteTestWithContext(t *testing.T) {
 
 	// Test with normal context
 	ctx := context.Background()
-	response, err := executor.ExecuteTestWithContext(ctx, test, server.URL)
+	response, err := executor.ExecuteTest(ctx, test, server.URL)
 	if err != nil {
-		t.Fatalf("ExecuteTestWithContext() failed: %v", err)
+		t.Fatalf("ExecuteTest() failed: %v", err)
 	}
 
 	if response.StatusCode != 200 {
-		t.Errorf("ExecuteTestWithContext() status code = %d, want 200", response.StatusCode)
+		t.Errorf("ExecuteTest() status code = %d, want 200", response.StatusCode)
 	}
 
 	// Test with cancelled context
 	cancelCtx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	_, err = executor.ExecuteTestWithContext(cancelCtx, test, server.URL)
+	_, err = executor.ExecuteTest(cancelCtx, test, server.URL)
 	if err == nil {
-		t.Error("ExecuteTestWithContext() should fail with cancelled context")
+		t.Error("ExecuteTest() should fail with cancelled context")
 	}
 }
 
@@ -324,7 +335,7 @@ func TestExecuteTestServerError(t *testing.T) {
 		},
 	}
 
-	response, err := executor.ExecuteTest(test, server.URL)
+	response, err := executor.ExecuteTest(context.Background(), test, server.URL)
 	if err != nil {
 		t.Fatalf("ExecuteTest() failed: %v", err)
 	}
@@ -364,7 +375,7 @@ func TestExecuteTestWithBody(t *testing.T) {
 		},
 	}
 
-	response, err := executor.ExecuteTest(test, server.URL)
+	response, err := executor.ExecuteTest(context.Background(), test, server.URL)
 	if err != nil {
 		t.Fatalf("ExecuteTest() failed: %v", err)
 	}
@@ -390,13 +401,13 @@ func TestExecuteTestInvalidURL(t *testing.T) {
 	}
 
 	// Test with invalid base URL
-	_, err := executor.ExecuteTest(test, "invalid-url")
+	_, err := executor.ExecuteTest(context.Background(), test, "invalid-url")
 	if err == nil {
 		t.Error("ExecuteTest() should fail with invalid base URL")
 	}
 
 	// Test with unreachable URL
-	_, err = executor.ExecuteTest(test, "http://localhost:99999")
+	_, err = executor.ExecuteTest(context.Background(), test, "http://localhost:99999")
 	if err == nil {
 		t.Error("ExecuteTest() should fail with unreachable URL")
 	}
@@ -422,7 +433,7 @@ func TestExecuteTestTimeout(t *testing.T) {
 		},
 	}
 
-	_, err := executor.ExecuteTest(test, server.URL)
+	_, err := executor.ExecuteTest(context.Background(), test, server.URL)
 	if err == nil {
 		t.Error("ExecuteTest() should fail with timeout")
 	}
@@ -442,4 +453,217 @@ func indexContains(s, substr string) int {
 		}
 	}
 	return -1
-}
\ No newline at end of file
+}
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	var seen string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		seen = req.Method + " " + req.URL.String()
+		return &http.Response{
+			StatusCode: http.StatusTeapot,
+			Proto:      "HTTP/1.1",
+			Header:     http.Header{"X-Served": []string{"in-process"}},
+			Body:       io.NopCloser(strings.NewReader("short and stout")),
+			Request:    req,
+		}, nil
+	})
+
+	executor := NewHTTPExecutor(5*time.Second, WithTransport(transport))
+	test := &config.Test{Name: "transport", Request: config.Request{Method: "GET", Path: "/pot"}}
+	resp, err := executor.ExecuteTest(context.Background(), test, "http://waf.invalid")
+	if err != nil {
+		t.Fatalf("ExecuteTest() failed: %v", err)
+	}
+
+	if seen != "GET http://waf.invalid/pot" {
+		t.Errorf("transport saw %q", seen)
+	}
+	if resp.StatusCode != http.StatusTeapot || resp.Body != "short and stout" || resp.Headers["X-Served"] != "in-process" {
+		t.Errorf("response = %d %q %v", resp.StatusCode, resp.Body, resp.Headers)
+	}
+	if executor.client.Timeout != 5*time.Second {
+		t.Errorf("timeout = %v, want 5s", executor.client.Timeout)
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	executor := NewHTTPExecutor(7*time.Second, WithHTTPClient(client))
+	test := &config.Test{Name: "client", Request: config.Request{Method: "GET", Path: "/"}}
+	resp, err := executor.ExecuteTest(context.Background(), test, server.URL)
+	if err != nil {
+		t.Fatalf("ExecuteTest() failed: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("status = %d, want the unfollowed 302", resp.StatusCode)
+	}
+	if executor.client.Timeout != 7*time.Second {
+		t.Errorf("timeout = %v, want 7s", executor.client.Timeout)
+	}
+	if client.Timeout != 0 {
+		t.Errorf("caller's client was modified: timeout = %v", client.Timeout)
+	}
+
+	client.Timeout = time.Second
+	if got := NewHTTPExecutor(7*time.Second, WithHTTPClient(client)).client.Timeout; got != time.Second {
+		t.Errorf("timeout = %v, want the client's own 1s", got)
+	}
+}
+
+func TestWithTransportAndHTTPClient(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTeapot,
+			Proto:      "HTTP/1.1",
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+	client := &http.Client{Timeout: time.Second}
+
+	orders := map[string][]Option{
+		"transport first": {WithTransport(transport), WithHTTPClient(client)},
+		"client first":    {WithHTTPClient(client), WithTransport(transport)},
+	}
+	for name, opts := range orders {
+		t.Run(name, func(t *testing.T) {
+			executor := NewHTTPExecutor(5*time.Second, opts...)
+			test := &config.Test{Name: "both", Request: config.Request{Method: "GET", Path: "/"}}
+			resp, err := executor.ExecuteTest(context.Background(), test, "http://waf.invalid")
+			if err != nil {
+				t.Fatalf("ExecuteTest() failed: %v", err)
+			}
+
+			if resp.StatusCode != http.StatusTeapot {
+				t.Errorf("status = %d, want the transport's 418", resp.StatusCode)
+			}
+			if executor.client.Timeout != time.Second {
+				t.Errorf("timeout = %v, want the client's own 1s", executor.client.Timeout)
+			}
+			if client.Transport != nil {
+				t.Error("caller's client was modified")
+			}
+		})
+	}
+}
+
+func TestExecuteTestRequestOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("X-Signature-Seen", r.Header.Get("X-Signature"))
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer server.Close()
+
+	executor := NewHTTPExecutor(30 * time.Second)
+	test := func(path string) *config.Test {
+		return &config.Test{Name: path, Request: config.Request{Method: "GET", Path: path}}
+	}
+
+	if _, err := executor.ExecuteTest(context.Background(), test("/slow"), server.URL, WithRequestTimeout(50*time.Millisecond)); err == nil {
+		t.Error("ExecuteTest() should fail when the request timeout expires")
+	}
+
+	for _, tt := range []struct {
+		limit     int64
+		body      string
+		truncated bool
+	}{
+		{0, "0123456789", false},
+		{10, "0123456789", false},
+		{9, "012345678", true},
+		{1, "0", true},
+	} {
+		resp, err := executor.ExecuteTest(context.Background(), test("/"), server.URL, WithMaxBodySize(tt.limit))
+		if err != nil {
+			t.Fatalf("ExecuteTest() with limit %d failed: %v", tt.limit, err)
+		}
+		if resp.Body != tt.body || resp.Truncated != tt.truncated {
+			t.Errorf("limit %d: body = %q, truncated = %v, want %q, %v", tt.limit, resp.Body, resp.Truncated, tt.body, tt.truncated)
+		}
+	}
+
+	var seen *Response
+	resp, err := executor.ExecuteTest(context.Background(), test("/"), server.URL,
+		WithBeforeSend(func(req *http.Request) error {
+			req.Header.Set("X-Signature", "signed")
+			return nil
+		}),
+		WithAfterResponse(func(r *Response) { seen = r }),
+	)
+	if err != nil {
+		t.Fatalf("ExecuteTest() failed: %v", err)
+	}
+	if resp.Headers["X-Signature-Seen"] != "signed" {
+		t.Errorf("before-send hook did not modify the request: %v", resp.Headers)
+	}
+	if seen != resp {
+		t.Error("after-response hook was not called with the response")
+	}
+
+	_, err = executor.ExecuteTest(context.Background(), test("/"), server.URL, WithBeforeSend(func(*http.Request) error {
+		return errors.New("no credentials")
+	}))
+	if err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("ExecuteTest() error = %v, want the hook error", err)
+	}
+}
+
+func TestExecuteTestTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(30 * time.Millisecond)
+		_, _ = w.Write([]byte("done"))
+	}))
+	defer server.Close()
+
+	executor := NewHTTPExecutor(5 * time.Second)
+	test := &config.Test{Name: "timed", Request: config.Request{Method: "GET", Path: "/"}}
+
+	resp, err := executor.ExecuteTest(context.Background(), test, server.URL)
+	if err != nil {
+		t.Fatalf("ExecuteTest() failed: %v", err)
+	}
+	timings := resp.Timings
+	if timings == nil {
+		t.Fatal("ExecuteTest() recorded no timings")
+	}
+	if timings.ConnReused || timings.Connect <= 0 {
+		t.Errorf("first request: reused = %v, connect = %v, want a new connection", timings.ConnReused, timings.Connect)
+	}
+	if timings.TTFB < 50*time.Millisecond || timings.Transfer < 30*time.Millisecond {
+		t.Errorf("ttfb = %v, transfer = %v, want at least 50ms and 30ms", timings.TTFB, timings.Transfer)
+	}
+	if timings.TTFB+timings.Transfer > resp.Duration {
+		t.Errorf("ttfb + transfer = %v exceeds duration %v", timings.TTFB+timings.Transfer, resp.Duration)
+	}
+
+	resp, err = executor.ExecuteTest(context.Background(), test, server.URL)
+	if err != nil {
+		t.Fatalf("ExecuteTest() failed: %v", err)
+	}
+	if !resp.Timings.ConnReused || resp.Timings.Connect != 0 {
+		t.Errorf("second request: reused = %v, connect = %v, want the kept-alive connection", resp.Timings.ConnReused, resp.Timings.Connect)
+	}
+}
@@ -0,0 +1,176 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+	"wafguard/internal/core/config"
+)
+
+// RateLimiter is a token bucket. All requests sharing a limiter are paced
+// to Rate per second, with up to Burst requests sent back to back.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(limit config.RateLimit) *RateLimiter {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		rate:   limit.RPS,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until the caller may send a request or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+
+	// Reserve a token now, even if that drives the bucket negative, so
+	// that concurrent callers queue up behind each other.
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// lower switches the limiter to limit if it is more restrictive than the
+// current rate.
+func (l *RateLimiter) lower(limit config.RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	if limit.RPS < l.rate {
+		l.rate = limit.RPS
+	}
+	if burst := float64(limit.Burst); burst >= 1 && burst < l.burst {
+		l.burst = burst
+		if l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+}
+
+func (l *RateLimiter) refill(now time.Time) {
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+}
+
+// RateLimiters hands out one RateLimiter per target host so that every
+// executor and worker talking to the same host shares a single budget.
+type RateLimiters struct {
+	mu           sync.Mutex
+	defaultLimit *config.RateLimit
+	limits       map[string]config.RateLimit
+	limiters     map[string]*RateLimiter
+}
+
+func NewRateLimiters() *RateLimiters {
+	return &RateLimiters{
+		limits:   make(map[string]config.RateLimit),
+		limiters: make(map[string]*RateLimiter),
+	}
+}
+
+// SetDefault applies limit to every host, overriding per-target settings.
+func (r *RateLimiters) SetDefault(limit config.RateLimit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.defaultLimit = &limit
+	r.limiters = make(map[string]*RateLimiter)
+}
+
+// Configure registers the target's rate limit for its host. When several
+// targets share a host, the most restrictive limit wins.
+func (r *RateLimiters) Configure(target config.Target) error {
+	if target.RateLimit == nil {
+		return nil
+	}
+
+	base, err := url.Parse(target.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	host := base.Host
+	limit := *target.RateLimit
+	if existing, ok := r.limits[host]; ok && existing.RPS < limit.RPS {
+		limit = existing
+	}
+	r.limits[host] = limit
+
+	if limiter, ok := r.limiters[host]; ok {
+		limiter.lower(limit)
+	}
+
+	return nil
+}
+
+// Wait paces a request to host. Hosts without a configured limit are not
+// throttled.
+func (r *RateLimiters) Wait(ctx context.Context, host string) error {
+	limiter := r.limiterFor(host)
+	if limiter == nil {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+func (r *RateLimiters) limiterFor(host string) *RateLimiter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if limiter, ok := r.limiters[host]; ok {
+		return limiter
+	}
+
+	var limit config.RateLimit
+	if r.defaultLimit != nil {
+		limit = *r.defaultLimit
+	} else if hostLimit, ok := r.limits[host]; ok {
+		limit = hostLimit
+	} else {
+		return nil
+	}
+
+	limiter := NewRateLimiter(limit)
+	r.limiters[host] = limiter
+	return limiter
+}
@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"wafguard/internal/core/config"
+)
+
+func TestRateLimiterPacesRequests(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimit{RPS: 20})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() failed: %v", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// The first request is free, the remaining four are spaced 50ms apart
+	if elapsed < 190*time.Millisecond {
+		t.Errorf("5 requests at 20 rps took %v, want at least 200ms", elapsed)
+	}
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimit{RPS: 1, Burst: 3})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() failed: %v", err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("burst of 3 took %v, want it to be immediate", elapsed)
+	}
+}
+
+func TestRateLimiterSharedAcrossWorkers(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimit{RPS: 50})
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = limiter.Wait(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("6 concurrent requests at 50 rps took %v, want at least 100ms", elapsed)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(config.RateLimit{RPS: 0.1})
+	_ = limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("Wait() should fail when the context expires first")
+	}
+}
+
+func TestRateLimitersConfigure(t *testing.T) {
+	limiters := NewRateLimiters()
+
+	if err := limiters.Configure(config.Target{BaseURL: "https://a.example.com"}); err != nil {
+		t.Fatalf("Configure() without rate limit failed: %v", err)
+	}
+	if limiters.limiterFor("a.example.com") != nil {
+		t.Error("host without a rate limit should not be throttled")
+	}
+
+	_ = limiters.Configure(config.Target{BaseURL: "https://b.example.com", RateLimit: &config.RateLimit{RPS: 10}})
+	_ = limiters.Configure(config.Target{BaseURL: "https://b.example.com/other", RateLimit: &config.RateLimit{RPS: 5}})
+	_ = limiters.Configure(config.Target{BaseURL: "https://b.example.com", RateLimit: &config.RateLimit{RPS: 20}})
+
+	limiter := limiters.limiterFor("b.example.com")
+	if limiter == nil {
+		t.Fatal("configured host should have a limiter")
+	}
+	if limiter.rate != 5 {
+		t.Errorf("limiter rate = %v, want the most restrictive 5", limiter.rate)
+	}
+	if limiters.limiterFor("b.example.com") != limiter {
+		t.Error("limiterFor() should return the shared limiter for a host")
+	}
+}
+
+func TestRateLimitersDefault(t *testing.T) {
+	limiters := NewRateLimiters()
+	_ = limiters.Configure(config.Target{BaseURL: "https://a.example.com", RateLimit: &config.RateLimit{RPS: 10}})
+	limiters.SetDefault(config.RateLimit{RPS: 2})
+
+	for _, host := range []string{"a.example.com", "c.example.com"} {
+		limiter := limiters.limiterFor(host)
+		if limiter == nil {
+			t.Fatalf("host %s should be throttled by the default limit", host)
+		}
+		if limiter.rate != 2 {
+			t.Errorf("host %s rate = %v, want 2", host, limiter.rate)
+		}
+	}
+
+	if limiters.limiterFor("a.example.com") == limiters.limiterFor("c.example.com") {
+		t.Error("each host should get its own limiter")
+	}
+}
+
+func TestExecuteTestWithRateLimiters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	limiters := NewRateLimiters()
+	_ = limiters.Configure(config.Target{BaseURL: server.URL, RateLimit: &config.RateLimit{RPS: 20}})
+	executor := NewHTTPExecutor(5*time.Second, WithRateLimiters(limiters))
+
+	test := &config.Test{
+		Name:    "rate-limited",
+		Request: config.Request{Method: "GET", Path: "/"},
+	}
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		response, err := executor.ExecuteTest(context.Background(), test, server.URL)
+		if err != nil {
+			t.Fatalf("ExecuteTest() failed: %v", err)
+		}
+		if response.Duration > 40*time.Millisecond {
+			t.Errorf("response duration %v should not include rate limiter wait", response.Duration)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("4 requests at 20 rps took %v, want at least 150ms", elapsed)
+	}
+}
@@ -0,0 +1,173 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"wafguard/internal/core/config"
+	"wafguard/internal/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimitResult summarises a rate-limit check run.
+type RateLimitResult struct {
+	RequestsSent    int           `json:"requests_sent"`
+	BlockedAt       int           `json:"blocked_at"`
+	BlockedAfter    time.Duration `json:"blocked_after"`
+	StatusHistogram map[int]int   `json:"status_histogram"`
+	Errors          int           `json:"errors"`
+	Duration        time.Duration `json:"duration"`
+	// Response is the first blocked response, or the last response seen if
+	// the WAF never blocked.
+	Response *Response `json:"-"`
+	// Responses holds every response received, in the order the requests
+	// were dispatched.
+	Responses []*Response `json:"-"`
+}
+
+// ExecuteRateLimitCheck fires the test request repeatedly as described by
+// test.RateLimitCheck and records the (1-based) index of the first request
+// the WAF blocked. With expected.blocked: true a request is blocked when
+// its status is in blockStatus (config.DefaultBlockStatus if empty), as
+// for other tests; otherwise when its status is in test.Expected.Status.
+// The requests are paced by check.RPS only, not by the shared per-host
+// rate limiters, so that a target or --rps limit cannot keep the rule
+// under test from firing.
+func (e *HTTPExecutor) ExecuteRateLimitCheck(ctx context.Context, test *config.Test, baseURL string, blockStatus []int) (*RateLimitResult, error) {
+	check := test.RateLimitCheck
+	if check == nil {
+		return nil, fmt.Errorf("test %s has no rateLimitCheck", test.Name)
+	}
+	if check.Requests < 1 {
+		return nil, fmt.Errorf("rateLimitCheck.requests must be at least 1, got %d", check.Requests)
+	}
+
+	workers := check.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	var pacer *RateLimiter
+	if check.RPS > 0 {
+		pacer = NewRateLimiter(config.RateLimit{RPS: check.RPS})
+	}
+
+	statuses := RateLimitBlockStatus(test, blockStatus)
+	blocking := make(map[int]bool, len(statuses))
+	for _, status := range statuses {
+		blocking[status] = true
+	}
+
+	logger.WithFields(logrus.Fields{
+		"test_name":   test.Name,
+		"requests":    check.Requests,
+		"rps":         check.RPS,
+		"concurrency": workers,
+	}).Info("Executing rate limit check")
+
+	result := &RateLimitResult{
+		StatusHistogram: make(map[int]int),
+	}
+	var mu sync.Mutex
+	var lastErr error
+	responses := make([]*Response, check.Requests)
+
+	// Dispatch stops once blocking is seen; in-flight requests still finish.
+	dispatchCtx, stopDispatch := context.WithCancel(ctx)
+	defer stopDispatch()
+
+	type job struct {
+		index  int
+		sentAt time.Duration
+	}
+	jobs := make(chan job)
+	start := time.Now()
+
+	go func() {
+		defer close(jobs)
+		for i := 1; i <= check.Requests; i++ {
+			if pacer != nil {
+				if err := pacer.Wait(dispatchCtx); err != nil {
+					return
+				}
+			}
+			select {
+			case jobs <- job{index: i, sentAt: time.Since(start)}:
+			case <-dispatchCtx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				response, err := e.ExecuteTest(ctx, test, baseURL, withoutRateLimit())
+
+				mu.Lock()
+				result.RequestsSent++
+				if err != nil {
+					result.Errors++
+					lastErr = err
+					mu.Unlock()
+					continue
+				}
+
+				responses[j.index-1] = response
+				result.StatusHistogram[response.StatusCode]++
+				if blocking[response.StatusCode] {
+					if result.BlockedAt == 0 || j.index < result.BlockedAt {
+						result.BlockedAt = j.index
+						result.BlockedAfter = j.sentAt
+						result.Response = response
+					}
+					stopDispatch()
+				} else if result.BlockedAt == 0 {
+					result.Response = response
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	result.Duration = time.Since(start)
+	for _, response := range responses {
+		if response != nil {
+			result.Responses = append(result.Responses, response)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if result.Response == nil {
+		return nil, fmt.Errorf("all %d requests failed: %w", result.RequestsSent, lastErr)
+	}
+
+	logger.WithFields(logrus.Fields{
+		"test_name":     test.Name,
+		"requests_sent": result.RequestsSent,
+		"blocked_at":    result.BlockedAt,
+		"duration":      result.Duration.String(),
+	}).Info("Rate limit check executed")
+
+	return result, nil
+}
+
+// RateLimitBlockStatus returns the statuses that count as the WAF blocking
+// during the rate-limit check of test, given the target's blockStatus.
+func RateLimitBlockStatus(test *config.Test, blockStatus []int) []int {
+	if test.Expected.Blocked == nil || !*test.Expected.Blocked {
+		return test.Expected.Status
+	}
+	if len(blockStatus) == 0 {
+		return config.DefaultBlockStatus
+	}
+	return blockStatus
+}
@@ -0,0 +1,206 @@
+package executor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wafguard/internal/core/config"
+)
+
+// rateLimitedServer answers 200 until more than limit requests arrived and
+// 429 afterwards
+func rateLimitedServer(limit int32) *httptest.Server {
+	var count int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) > limit {
+			w.WriteHeader(429)
+			_, _ = w.Write([]byte("rate limited"))
+			return
+		}
+		w.WriteHeader(200)
+	}))
+}
+
+func TestExecuteRateLimitCheck(t *testing.T) {
+	server := rateLimitedServer(4)
+	defer server.Close()
+
+	executor := NewHTTPExecutor(5 * time.Second)
+	test := &config.Test{
+		Name:           "rate-limit",
+		Request:        config.Request{Method: "GET", Path: "/login"},
+		Expected:       config.Expected{Status: []int{429}},
+		RateLimitCheck: &config.RateLimitCheck{Requests: 20},
+	}
+
+	result, err := executor.ExecuteRateLimitCheck(context.Background(), test, server.URL, nil)
+	if err != nil {
+		t.Fatalf("ExecuteRateLimitCheck() failed: %v", err)
+	}
+
+	if result.BlockedAt != 5 {
+		t.Errorf("BlockedAt = %d, want 5", result.BlockedAt)
+	}
+	if result.RequestsSent != 5 {
+		t.Errorf("RequestsSent = %d, want 5 (sending stops once blocked)", result.RequestsSent)
+	}
+	if result.StatusHistogram[200] != 4 || result.StatusHistogram[429] != 1 {
+		t.Errorf("StatusHistogram = %v, want 4x200 and 1x429", result.StatusHistogram)
+	}
+	if result.Response == nil || result.Response.StatusCode != 429 {
+		t.Error("Response should be the first blocked response")
+	}
+	if len(result.Responses) != 5 || result.Responses[4] != result.Response {
+		t.Errorf("Responses = %d, want all 5 in order", len(result.Responses))
+	}
+}
+
+func TestExecuteRateLimitCheckBlockedExpectation(t *testing.T) {
+	server := rateLimitedServer(2)
+	defer server.Close()
+
+	executor := NewHTTPExecutor(5 * time.Second)
+	blocked := true
+	test := &config.Test{
+		Name:           "rate-limit",
+		Request:        config.Request{Method: "GET", Path: "/login"},
+		Expected:       config.Expected{Blocked: &blocked},
+		RateLimitCheck: &config.RateLimitCheck{Requests: 10},
+	}
+
+	result, err := executor.ExecuteRateLimitCheck(context.Background(), test, server.URL, []int{403, 429})
+	if err != nil {
+		t.Fatalf("ExecuteRateLimitCheck() failed: %v", err)
+	}
+	if result.BlockedAt != 3 {
+		t.Errorf("BlockedAt = %d, want 3 with 429 in the target's blockStatus", result.BlockedAt)
+	}
+
+	// The default blockStatus does not include 429
+	result, err = executor.ExecuteRateLimitCheck(context.Background(), test, server.URL, nil)
+	if err != nil {
+		t.Fatalf("ExecuteRateLimitCheck() failed: %v", err)
+	}
+	if result.BlockedAt != 0 {
+		t.Errorf("BlockedAt = %d, want 0 with the default blockStatus", result.BlockedAt)
+	}
+}
+
+func TestExecuteRateLimitCheckIgnoresSharedRateLimit(t *testing.T) {
+	server := rateLimitedServer(4)
+	defer server.Close()
+
+	// At 1 request per second the check would take about 4s
+	limiters := NewRateLimiters()
+	limiters.SetDefault(config.RateLimit{RPS: 1})
+	executor := NewHTTPExecutor(5*time.Second, WithRateLimiters(limiters))
+	test := &config.Test{
+		Name:           "rate-limit",
+		Request:        config.Request{Method: "GET", Path: "/login"},
+		Expected:       config.Expected{Status: []int{429}},
+		RateLimitCheck: &config.RateLimitCheck{Requests: 20},
+	}
+
+	result, err := executor.ExecuteRateLimitCheck(context.Background(), test, server.URL, nil)
+	if err != nil {
+		t.Fatalf("ExecuteRateLimitCheck() failed: %v", err)
+	}
+	if result.BlockedAt != 5 {
+		t.Errorf("BlockedAt = %d, want 5", result.BlockedAt)
+	}
+	if result.Duration > time.Second {
+		t.Errorf("Duration = %s, the shared rate limit should not pace the check", result.Duration)
+	}
+}
+
+func TestExecuteRateLimitCheckNeverBlocked(t *testing.T) {
+	server := rateLimitedServer(100)
+	defer server.Close()
+
+	executor := NewHTTPExecutor(5 * time.Second)
+	test := &config.Test{
+		Name:           "never-blocked",
+		Request:        config.Request{Method: "GET", Path: "/"},
+		Expected:       config.Expected{Status: []int{429}},
+		RateLimitCheck: &config.RateLimitCheck{Requests: 10, Concurrency: 3},
+	}
+
+	result, err := executor.ExecuteRateLimitCheck(context.Background(), test, server.URL, nil)
+	if err != nil {
+		t.Fatalf("ExecuteRateLimitCheck() failed: %v", err)
+	}
+
+	if result.BlockedAt != 0 {
+		t.Errorf("BlockedAt = %d, want 0", result.BlockedAt)
+	}
+	if result.RequestsSent != 10 {
+		t.Errorf("RequestsSent = %d, want 10", result.RequestsSent)
+	}
+	if result.StatusHistogram[200] != 10 {
+		t.Errorf("StatusHistogram = %v, want 10x200", result.StatusHistogram)
+	}
+}
+
+func TestExecuteRateLimitCheckPacing(t *testing.T) {
+	server := rateLimitedServer(100)
+	defer server.Close()
+
+	executor := NewHTTPExecutor(5 * time.Second)
+	test := &config.Test{
+		Name:           "paced",
+		Request:        config.Request{Method: "GET", Path: "/"},
+		Expected:       config.Expected{Status: []int{429}},
+		RateLimitCheck: &config.RateLimitCheck{Requests: 5, RPS: 25, Concurrency: 5},
+	}
+
+	result, err := executor.ExecuteRateLimitCheck(context.Background(), test, server.URL, nil)
+	if err != nil {
+		t.Fatalf("ExecuteRateLimitCheck() failed: %v", err)
+	}
+
+	if result.Duration < 150*time.Millisecond {
+		t.Errorf("5 requests at 25 rps took %v, want at least 160ms", result.Duration)
+	}
+}
+
+func TestExecuteRateLimitCheckInvalid(t *testing.T) {
+	executor := NewHTTPExecutor(5 * time.Second)
+
+	tests := []struct {
+		name string
+		test *config.Test
+	}{
+		{
+			name: "missing check",
+			test: &config.Test{Name: "plain", Request: config.Request{Method: "GET", Path: "/"}},
+		},
+		{
+			name: "zero requests",
+			test: &config.Test{
+				Name:           "zero",
+				Request:        config.Request{Method: "GET", Path: "/"},
+				RateLimitCheck: &config.RateLimitCheck{},
+			},
+		},
+		{
+			name: "unreachable target",
+			test: &config.Test{
+				Name:           "unreachable",
+				Request:        config.Request{Method: "GET", Path: "/"},
+				RateLimitCheck: &config.RateLimitCheck{Requests: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executor.ExecuteRateLimitCheck(context.Background(), tt.test, "http://localhost:99999", nil)
+			if err == nil {
+				t.Error("ExecuteRateLimitCheck() should fail")
+			}
+		})
+	}
+}
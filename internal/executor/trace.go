@@ -0,0 +1,113 @@
+package executor
+
+import (
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings breaks the response time down into phases. DNS, Connect and TLS
+// are zero when a kept-alive connection was reused or, for TLS, over plain
+// HTTP. TTFB runs from the start of the request to the first response byte
+// and so includes the phases before it; Transfer is the time spent reading
+// the rest of the response. Over redirects, DNS, Connect and TLS add up
+// over every hop and TTFB is measured to the first byte of the last
+// response.
+type Timings struct {
+	DNS        time.Duration `json:"dns"`
+	Connect    time.Duration `json:"connect"`
+	TLS        time.Duration `json:"tls"`
+	TTFB       time.Duration `json:"ttfb"`
+	Transfer   time.Duration `json:"transfer"`
+	ConnReused bool          `json:"conn_reused"`
+}
+
+// Wait is the time the server took to answer once the request could be
+// sent, i.e. TTFB without the connection set-up.
+func (t *Timings) Wait() time.Duration {
+	wait := t.TTFB - t.DNS - t.Connect - t.TLS
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// tracer collects Timings through httptrace. Hooks may run on other
+// goroutines, e.g. when dialing several addresses, hence the lock.
+type tracer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	firstByte    time.Time
+	timings      Timings
+}
+
+func newTracer(start time.Time) *tracer {
+	return &tracer{start: start}
+}
+
+func (t *tracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.DNS += time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if err == nil && !t.connectStart.IsZero() {
+				t.timings.Connect += time.Since(t.connectStart)
+				t.connectStart = time.Time{}
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.TLS += time.Since(t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timings.ConnReused = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+		},
+	}
+}
+
+// finish returns the timings of a response whose body was read by end.
+func (t *tracer) finish(end time.Time) *Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := t.timings
+	if !t.firstByte.IsZero() {
+		timings.TTFB = t.firstByte.Sub(t.start)
+		timings.Transfer = end.Sub(t.firstByte)
+	}
+	return &timings
+}
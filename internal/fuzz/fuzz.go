@@ -0,0 +1,219 @@
+// Package fuzz searches for WAF bypasses by re-sending a blocked injected
+// test with its payload run through chains of evasion transforms.
+package fuzz
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/logger"
+	"wafguard/internal/parser"
+	"wafguard/internal/transform"
+	"wafguard/internal/validator"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultBudget   = 100
+	DefaultMaxDepth = 3
+)
+
+// Options bounds a fuzzing run. Seed only decides the order in which
+// chains are tried; randomised transforms are seeded the same way as in
+// test files, so a reported chain reproduces exactly with `transforms:`.
+type Options struct {
+	Seed       int64
+	Budget     int
+	MaxDepth   int
+	Transforms []string
+}
+
+// Bypass is a variant of the test that the WAF let through.
+type Bypass struct {
+	Chain      []string       `json:"chain"`
+	Encoded    string         `json:"encoded"`
+	StatusCode int            `json:"status_code"`
+	Request    config.Request `json:"request"`
+}
+
+// Result is the outcome of fuzzing a single test.
+type Result struct {
+	Test           string   `json:"test"`
+	Payload        string   `json:"payload"`
+	Location       string   `json:"location"`
+	BaselineStatus int      `json:"baseline_status"`
+	Blocked        bool     `json:"blocked"`
+	Requests       int      `json:"requests"`
+	Depth          int      `json:"depth"`
+	Bypasses       []Bypass `json:"bypasses,omitempty"`
+	Errors         int      `json:"errors,omitempty"`
+}
+
+type Fuzzer struct {
+	executor  executor.Executor
+	validator *validator.ResponseValidator
+	options   Options
+}
+
+func NewFuzzer(httpExecutor executor.Executor, responseValidator *validator.ResponseValidator, options Options) *Fuzzer {
+	if options.Budget <= 0 {
+		options.Budget = DefaultBudget
+	}
+	if options.MaxDepth <= 0 {
+		options.MaxDepth = DefaultMaxDepth
+	}
+	if len(options.Transforms) == 0 {
+		options.Transforms = transform.Names()
+	}
+
+	return &Fuzzer{
+		executor:  httpExecutor,
+		validator: responseValidator,
+		options:   options,
+	}
+}
+
+// Fuzz sends the test unchanged and, if the WAF blocks it, tries transform
+// chains in order of length until a bypass is found or the budget is
+// spent. All bypasses of the shortest successful length are reported. The
+// baseline request counts towards the budget.
+func (f *Fuzzer) Fuzz(ctx context.Context, test *config.Test, target config.Target) (*Result, error) {
+	if test.Injection == nil {
+		return nil, fmt.Errorf("test %s has no injected payload; fuzz needs a test with an inject block", test.Name)
+	}
+	if err := transform.Validate(f.options.Transforms); err != nil {
+		return nil, err
+	}
+
+	injection := test.Injection
+	result := &Result{
+		Test:     test.Name,
+		Payload:  injection.Payload,
+		Location: injection.Location,
+	}
+	v := f.validator.WithBlockStatus(target.BlockStatus)
+
+	baseline, err := f.executor.ExecuteTest(ctx, test, target.BaseURL)
+	result.Requests++
+	if err != nil {
+		return nil, fmt.Errorf("baseline request failed: %w", err)
+	}
+	result.BaselineStatus = baseline.StatusCode
+	result.Blocked = v.IsBlocked(baseline)
+	if !result.Blocked {
+		return result, nil
+	}
+
+	rng := rand.New(rand.NewSource(f.options.Seed))
+	tried := map[string]bool{requestKey(test.Request): true}
+
+	maxDepth := f.options.MaxDepth
+	if maxDepth > len(f.options.Transforms) {
+		maxDepth = len(f.options.Transforms)
+	}
+
+	for depth := 1; depth <= maxDepth && len(result.Bypasses) == 0; depth++ {
+		chains := permutations(f.options.Transforms, depth)
+		rng.Shuffle(len(chains), func(i, j int) { chains[i], chains[j] = chains[j], chains[i] })
+
+		for _, chain := range chains {
+			if result.Requests >= f.options.Budget || ctx.Err() != nil {
+				return result, nil
+			}
+
+			variant, encoded, err := mutate(test, chain)
+			if err != nil {
+				// Some encodings cannot be placed at some locations
+				continue
+			}
+			// Different chains often encode to the same bytes
+			key := requestKey(variant.Request)
+			if tried[key] {
+				continue
+			}
+			tried[key] = true
+
+			result.Depth = depth
+			result.Requests++
+			response, err := f.executor.ExecuteTest(ctx, variant, target.BaseURL)
+			if err != nil {
+				result.Errors++
+				logger.WithFields(logrus.Fields{
+					"test_name": test.Name,
+					"chain":     chain,
+					"error":     err.Error(),
+				}).Debug("Fuzz variant failed")
+				continue
+			}
+
+			if !v.IsBlocked(response) {
+				result.Bypasses = append(result.Bypasses, Bypass{
+					Chain:      chain,
+					Encoded:    encoded,
+					StatusCode: response.StatusCode,
+					Request:    variant.Request,
+				})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// mutate re-injects the test's payload after the test's own transforms
+// and then chain, seeding randomised transforms as the parser does.
+func mutate(test *config.Test, chain []string) (*config.Test, string, error) {
+	injection := test.Injection
+	full := append(append([]string(nil), injection.Transforms...), chain...)
+
+	rng := rand.New(rand.NewSource(transform.Seed(injection.Test, injection.Payload)))
+	encoded, err := transform.Apply(full, injection.Payload, rng)
+	if err != nil {
+		return nil, "", err
+	}
+
+	request, err := parser.InjectPayload(injection.Template, injection.Location, encoded)
+	if err != nil {
+		return nil, "", err
+	}
+
+	variant := *test
+	variant.Name = fmt.Sprintf("%s+%v", test.Name, chain)
+	variant.Request = request
+	return &variant, encoded, nil
+}
+
+func requestKey(request config.Request) string {
+	return fmt.Sprintf("%s\x00%v\x00%s", request.Path, request.Headers, request.Body)
+}
+
+// permutations returns every ordered selection of n distinct names.
+func permutations(names []string, n int) [][]string {
+	var chains [][]string
+	used := make([]bool, len(names))
+	chain := make([]string, 0, n)
+
+	var walk func()
+	walk = func() {
+		if len(chain) == n {
+			chains = append(chains, append([]string(nil), chain...))
+			return
+		}
+		for i, name := range names {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			chain = append(chain, name)
+			walk()
+			chain = chain[:len(chain)-1]
+			used[i] = false
+		}
+	}
+	walk()
+
+	return chains
+}
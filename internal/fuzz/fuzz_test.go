@@ -0,0 +1,143 @@
+package fuzz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/validator"
+)
+
+// naiveWAF blocks requests whose raw query contains any of the words
+func naiveWAF(words ...string) (*httptest.Server, *int32) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		for _, word := range words {
+			if strings.Contains(r.URL.RawQuery, word) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, &count
+}
+
+func injectedTest(payload string) *config.Test {
+	blocked := true
+	template := config.Request{Method: "GET", Path: "/search"}
+	return &config.Test{
+		Name:     "sqli/query:q/1",
+		Request:  config.Request{Method: "GET", Path: "/search?q=" + payload},
+		Expected: config.Expected{Blocked: &blocked},
+		Injection: &config.Injection{
+			Test:     "sqli",
+			Payload:  payload,
+			Location: "query:q",
+			Template: template,
+		},
+	}
+}
+
+func newTestFuzzer(options Options) *Fuzzer {
+	return NewFuzzer(executor.NewHTTPExecutor(5*time.Second), validator.NewResponseValidator(), options)
+}
+
+func TestFuzzFindsMinimalBypass(t *testing.T) {
+	server, _ := naiveWAF("'")
+	defer server.Close()
+
+	result, err := newTestFuzzer(Options{Seed: 1}).Fuzz(context.Background(), injectedTest("'OR'1"), config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Fuzz() error = %v", err)
+	}
+
+	if !result.Blocked || result.BaselineStatus != 403 {
+		t.Errorf("baseline = %d blocked %v, want a blocked baseline", result.BaselineStatus, result.Blocked)
+	}
+	if result.Depth != 1 {
+		t.Errorf("Depth = %d, want 1", result.Depth)
+	}
+	if len(result.Bypasses) == 0 {
+		t.Fatal("Fuzz() found no bypass")
+	}
+	for _, bypass := range result.Bypasses {
+		if len(bypass.Chain) != 1 {
+			t.Errorf("bypass chain %v is not minimal", bypass.Chain)
+		}
+		if bypass.StatusCode != 200 || strings.Contains(bypass.Request.Path, "'") {
+			t.Errorf("unexpected bypass %+v", bypass)
+		}
+	}
+}
+
+func TestFuzzIsReproducible(t *testing.T) {
+	server, _ := naiveWAF("'", "%27")
+	defer server.Close()
+
+	run := func() *Result {
+		result, err := newTestFuzzer(Options{Seed: 42, Budget: 30}).Fuzz(context.Background(), injectedTest("'OR'1"), config.Target{BaseURL: server.URL})
+		if err != nil {
+			t.Fatalf("Fuzz() error = %v", err)
+		}
+		return result
+	}
+
+	if first, second := run(), run(); !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed gave different results:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestFuzzRespectsBudget(t *testing.T) {
+	// Nothing gets through, so the whole budget is spent
+	server, count := naiveWAF("")
+	defer server.Close()
+
+	result, err := newTestFuzzer(Options{Budget: 7}).Fuzz(context.Background(), injectedTest("'"), config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Fuzz() error = %v", err)
+	}
+
+	if result.Requests != 7 || *count != 7 {
+		t.Errorf("sent %d requests (server saw %d), want 7", result.Requests, *count)
+	}
+	if len(result.Bypasses) != 0 {
+		t.Errorf("Bypasses = %+v, want none", result.Bypasses)
+	}
+}
+
+func TestFuzzNotBlocked(t *testing.T) {
+	server, count := naiveWAF("<script>")
+	defer server.Close()
+
+	result, err := newTestFuzzer(Options{}).Fuzz(context.Background(), injectedTest("'"), config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Fuzz() error = %v", err)
+	}
+
+	if result.Blocked || *count != 1 {
+		t.Errorf("an allowed baseline should not be fuzzed: %+v", result)
+	}
+}
+
+func TestFuzzRequiresInjection(t *testing.T) {
+	test := &config.Test{Name: "plain", Request: config.Request{Method: "GET", Path: "/"}}
+	if _, err := newTestFuzzer(Options{}).Fuzz(context.Background(), test, config.Target{BaseURL: "http://localhost"}); err == nil {
+		t.Error("Fuzz() expected error for a test without injection")
+	}
+}
+
+func TestPermutations(t *testing.T) {
+	got := permutations([]string{"a", "b", "c"}, 2)
+	want := [][]string{{"a", "b"}, {"a", "c"}, {"b", "a"}, {"b", "c"}, {"c", "a"}, {"c", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("permutations() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,388 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"wafguard/internal/core/config"
+)
+
+// Predefined access log formats, in nginx syntax.
+const (
+	CommonLogFormat   = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent`
+	CombinedLogFormat = CommonLogFormat + ` "$http_referer" "$http_user_agent"`
+)
+
+// AccessLogOptions controls how web server access logs are converted.
+type AccessLogOptions struct {
+	// Name is used for the suite; it defaults to one derived from the source.
+	Name string
+	// BaseURL is the target for every request. Access logs do not record
+	// the scheme and host, so it is required.
+	BaseURL string
+	// Format is "common", "combined", or a custom nginx log_format
+	// ($remote_addr ...) or Apache LogFormat (%h ...) string. The default
+	// accepts both common and combined lines.
+	Format   string
+	Expected config.Expected
+	// Dedupe keeps only the first request for each method and path shape,
+	// see RequestShape.
+	Dedupe bool
+}
+
+// logFormat is a compiled access log format. fields names the capture
+// groups of pattern: "request", "method", "uri", "path", "query" or
+// "header:<name>"; other groups are named "".
+type logFormat struct {
+	pattern *regexp.Regexp
+	fields  []string
+}
+
+// defaultLogFormat accepts the Common Log Format, optionally followed by
+// the referer and user agent of the Combined Log Format.
+var defaultLogFormat = &logFormat{
+	pattern: regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}|-) (\d+|-)(?: "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)")?`),
+	fields:  []string{"", "", "", "", "request", "", "", "header:Referer", "header:User-Agent"},
+}
+
+// logDirective matches nginx variables ($name or ${name}) and Apache
+// format directives (%h, %>s, %{Referer}i), and %% for a literal percent.
+var logDirective = regexp.MustCompile(`\$\{?[a-zA-Z_][a-zA-Z0-9_]*\}?|%%|%[<>]?(?:\{[^}]*\})?[a-zA-Z]`)
+
+// compileLogFormat turns a format name or format string into a matcher.
+func compileLogFormat(format string) (*logFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "":
+		return defaultLogFormat, nil
+	case "common":
+		format = CommonLogFormat
+	case "combined":
+		format = CombinedLogFormat
+	}
+
+	// Formats are often copied from shell-quoted configuration
+	format = strings.ReplaceAll(format, `\"`, `"`)
+
+	var pattern strings.Builder
+	var fields []string
+	pattern.WriteString("^")
+
+	last := 0
+	for _, loc := range logDirective.FindAllStringIndex(format, -1) {
+		pattern.WriteString(regexp.QuoteMeta(format[last:loc[0]]))
+		directive := format[loc[0]:loc[1]]
+		last = loc[1]
+
+		if directive == "%%" {
+			pattern.WriteString("%")
+			continue
+		}
+
+		// The character before a field decides how far it extends
+		switch {
+		case directive == "%t":
+			// Apache writes the brackets itself
+			pattern.WriteString(`(\[[^\]]*\])`)
+		case loc[0] > 0 && format[loc[0]-1] == '"':
+			pattern.WriteString(`((?:[^"\\]|\\.)*)`)
+		case loc[0] > 0 && format[loc[0]-1] == '[':
+			pattern.WriteString(`([^\]]*)`)
+		default:
+			pattern.WriteString(`(\S*)`)
+		}
+		fields = append(fields, logField(directive))
+	}
+	pattern.WriteString(regexp.QuoteMeta(format[last:]))
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("log format %q has no fields", format)
+	}
+
+	hasRequest := false
+	for _, field := range fields {
+		if field == "request" || field == "uri" || field == "path" {
+			hasRequest = true
+		}
+	}
+	if !hasRequest {
+		return nil, fmt.Errorf("log format %q does not log the request ($request, $request_uri, %%r or %%U)", format)
+	}
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid log format %q: %w", format, err)
+	}
+	return &logFormat{pattern: re, fields: fields}, nil
+}
+
+// logField maps an nginx variable or Apache directive to the request part
+// it records.
+func logField(directive string) string {
+	if strings.HasPrefix(directive, "$") {
+		name := strings.Trim(directive, "${}")
+		switch name {
+		case "request":
+			return "request"
+		case "request_method":
+			return "method"
+		case "request_uri":
+			return "uri"
+		case "uri", "document_uri":
+			return "path"
+		case "args", "query_string":
+			return "query"
+		}
+		if header, ok := strings.CutPrefix(name, "http_"); ok {
+			return "header:" + strings.ReplaceAll(header, "_", "-")
+		}
+		return ""
+	}
+
+	switch {
+	case directive == "%r":
+		return "request"
+	case directive == "%m":
+		return "method"
+	case directive == "%U":
+		return "path"
+	case directive == "%q":
+		return "query"
+	case strings.HasPrefix(directive, "%{") && strings.HasSuffix(directive, "}i"):
+		return "header:" + directive[2:len(directive)-2]
+	}
+	return ""
+}
+
+// IsAccessLog reports whether the first non-empty line of data looks like
+// a common or combined access log line.
+func IsAccessLog(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		return defaultLogFormat.pattern.MatchString(line)
+	}
+	return false
+}
+
+// ImportAccessLog converts the requests in an access log into a suite.
+// Logged request headers, such as the referer and user agent of combined
+// logs, are replayed.
+func ImportAccessLog(data []byte, source string, opts AccessLogOptions) (*Result, error) {
+	if opts.BaseURL == "" {
+		return nil, fmt.Errorf("access logs do not record the target host, a base URL is required")
+	}
+
+	format, err := compileLogFormat(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Source: source}
+	used := make(map[string]int)
+	shapes := make(map[string]bool)
+	duplicates := 0
+	var tests []config.Test
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		m := format.pattern.FindStringSubmatch(line)
+		if m == nil {
+			result.skip("line %d: does not match the log format", number)
+			continue
+		}
+
+		test, err := accessLogTest(format.fields, m[1:])
+		if err != nil {
+			result.skip("line %d: %v", number, err)
+			continue
+		}
+
+		if opts.Dedupe {
+			shape := RequestShape(test.Request.Method, test.Request.Path)
+			if shapes[shape] {
+				duplicates++
+				continue
+			}
+			shapes[shape] = true
+		}
+
+		test.Name = uniqueName(test.Name, used)
+		test.Expected = opts.Expected
+		tests = append(tests, test)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read access log %s: %w", source, err)
+	}
+
+	if len(tests) == 0 {
+		return result, nil
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = "log-" + sourceName(source)
+	}
+	description := fmt.Sprintf("Imported from access log %s", source)
+	if duplicates > 0 {
+		description += fmt.Sprintf(" (%d duplicate requests removed)", duplicates)
+	}
+	result.Suite = newSuite(sanitizeName(name), description, opts.BaseURL)
+	result.Suite.Spec.Tests = tests
+
+	return result, nil
+}
+
+// RequestShape identifies requests that exercise the same endpoint in the
+// same way: the method, the path shape (see PathShape) and the sorted
+// names of the query parameters.
+func RequestShape(method, path string) string {
+	shape := method + " " + PathShape(path)
+
+	_, query, _ := strings.Cut(path, "?")
+	query, _, _ = strings.Cut(query, "#")
+	if query == "" {
+		return shape
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(query, "&") {
+		name, _, _ := strings.Cut(pair, "=")
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return shape + "?" + strings.Join(names, "&")
+}
+
+// accessLogTest builds a test from the captured fields of one log line.
+func accessLogTest(fields, values []string) (config.Test, error) {
+	var method, uri, path, query string
+	var headers map[string]string
+
+	for i, field := range fields {
+		value := unescapeLogField(values[i])
+		switch {
+		case field == "request":
+			parts := strings.Fields(value)
+			if len(parts) < 2 || len(parts) > 3 {
+				return config.Test{}, fmt.Errorf("malformed request line %q", value)
+			}
+			method, uri = parts[0], parts[1]
+		case field == "method":
+			method = value
+		case field == "uri":
+			uri = value
+		case field == "path":
+			path = value
+		case field == "query":
+			query = strings.TrimPrefix(value, "?")
+		case strings.HasPrefix(field, "header:"):
+			name := strings.TrimPrefix(field, "header:")
+			if value == "" || value == "-" || harDroppedHeaders[strings.ToLower(name)] {
+				continue
+			}
+			if headers == nil {
+				headers = make(map[string]string)
+			}
+			headers[canonicalHeader(name)] = value
+		}
+	}
+
+	if uri == "" && path != "" {
+		uri = path
+		if query != "" && query != "-" {
+			uri += "?" + query
+		}
+	}
+
+	if method == "" {
+		method = "GET"
+	}
+	if !isMethod(method) {
+		return config.Test{}, fmt.Errorf("unsupported method %q", method)
+	}
+	if !strings.HasPrefix(uri, "/") {
+		return config.Test{}, fmt.Errorf("unsupported request target %q", uri)
+	}
+	uri = escapeLoggedPath(uri)
+
+	return config.Test{
+		Name:    testNameFor(method, uri),
+		Request: config.Request{Method: method, Path: uri, Headers: headers},
+	}, nil
+}
+
+// canonicalHeader formats a logged header name the way clients send it,
+// e.g. user-agent as User-Agent.
+func canonicalHeader(name string) string {
+	parts := strings.Split(strings.ToLower(name), "-")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// unescapeLogField reverses the \xHH, \" and \\ escapes nginx and Apache
+// write into log fields.
+func unescapeLogField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch next := s[i+1]; {
+		case next == 'x' && i+3 < len(s):
+			if v, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+			b.WriteByte(s[i])
+		case next == '"' || next == '\\':
+			b.WriteByte(next)
+			i++
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// escapeLoggedPath percent-encodes the bytes a request line cannot carry
+// as they are, leaving existing escapes alone.
+func escapeLoggedPath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c <= 0x20 || c >= 0x7f || c == '"' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"wafguard/internal/core/config"
+	"wafguard/internal/parser"
+)
+
+func TestImportAccessLog(t *testing.T) {
+	log := `203.0.113.7 - - [10/Oct/2026:13:55:36 +0000] "GET /index.html HTTP/1.1" 200 2326 "https://example.com/" "Mozilla/5.0 (X11; Linux x86_64)"
+203.0.113.7 - frank [10/Oct/2026:13:55:37 +0000] "POST /login HTTP/1.1" 302 0
+198.51.100.2 - - [10/Oct/2026:13:55:38 +0000] "GET /search?q=\x22caf\xC3\xA9%20au%20lait\x22 HTTP/1.1" 200 512 "-" "curl/8.0"
+198.51.100.2 - - [10/Oct/2026:13:55:39 +0000] "\x16\x03\x01\x00" 400 150 "-" "-"
+198.51.100.2 - - [10/Oct/2026:13:55:40 +0000] "CONNECT example.com:443 HTTP/1.1" 405 0 "-" "-"
+garbage
+`
+	result, err := ImportAccessLog([]byte(log), "logs/access.log", AccessLogOptions{BaseURL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("ImportAccessLog() error = %v", err)
+	}
+
+	suite := result.Suite
+	if suite.Metadata.Name != "log-access" || len(suite.Spec.Tests) != 3 {
+		t.Fatalf("unexpected suite %s with %d tests", suite.Metadata.Name, len(suite.Spec.Tests))
+	}
+
+	first := suite.Spec.Tests[0].Request
+	if first.Method != "GET" || first.Path != "/index.html" {
+		t.Errorf("first request = %s %s", first.Method, first.Path)
+	}
+	if first.Headers["Referer"] != "https://example.com/" || first.Headers["User-Agent"] != "Mozilla/5.0 (X11; Linux x86_64)" {
+		t.Errorf("first headers = %v", first.Headers)
+	}
+
+	if second := suite.Spec.Tests[1].Request; second.Method != "POST" || second.Headers != nil {
+		t.Errorf("common log line = %+v", second)
+	}
+
+	third := suite.Spec.Tests[2].Request
+	if third.Path != "/search?q=%22caf%C3%A9%20au%20lait%22" {
+		t.Errorf("escaped path = %q", third.Path)
+	}
+	if _, ok := third.Headers["Referer"]; ok {
+		t.Error(`"-" referer should not be replayed`)
+	}
+
+	if len(result.Unsupported) != 3 {
+		t.Errorf("Unsupported = %v, want 3 notes", result.Unsupported)
+	}
+
+	data, err := Marshal(suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.NewParser().ParseYAML(data); err != nil {
+		t.Errorf("imported suite is invalid: %v", err)
+	}
+}
+
+func TestImportAccessLogRequiresBaseURL(t *testing.T) {
+	if _, err := ImportAccessLog([]byte(`1.2.3.4 - - [x] "GET / HTTP/1.1" 200 1`), "access.log", AccessLogOptions{}); err == nil {
+		t.Error("ImportAccessLog() expected error without a base URL")
+	}
+}
+
+func TestIsAccessLog(t *testing.T) {
+	if !IsAccessLog([]byte("\n" + `1.2.3.4 - - [10/Oct/2026:13:55:36 +0000] "GET / HTTP/1.1" 200 1` + "\n")) {
+		t.Error("IsAccessLog() should accept a common log line")
+	}
+	if IsAccessLog([]byte("https://example.com/\n")) {
+		t.Error("IsAccessLog() should reject a URL list")
+	}
+}
+
+func TestImportAccessLogCustomFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		line   string
+		want   config.Request
+	}{
+		{
+			name:   "nginx",
+			format: `$remote_addr [$time_local] "$request_method $request_uri" $status "$http_user_agent" "$http_x_forwarded_for" $request_time`,
+			line:   `10.0.0.1 [10/Oct/2026:13:55:36 +0000] "GET /api/items?page=2" 200 "Mozilla/5.0" "203.0.113.9" 0.012`,
+			want: config.Request{Method: "GET", Path: "/api/items?page=2", Headers: map[string]string{
+				"User-Agent":      "Mozilla/5.0",
+				"X-Forwarded-For": "203.0.113.9",
+			}},
+		},
+		{
+			name:   "apache",
+			format: `%h %l %u %t \"%m %U%q %H\" %>s %b \"%{Referer}i\" \"%{User-agent}i\" %D`,
+			line:   `10.0.0.1 - - [10/Oct/2026:13:55:36 +0000] "POST /login?next=/ HTTP/1.1" 302 0 "https://example.com/" "curl/8.0" 1234`,
+			want: config.Request{Method: "POST", Path: "/login?next=/", Headers: map[string]string{
+				"Referer":    "https://example.com/",
+				"User-Agent": "curl/8.0",
+			}},
+		},
+		{
+			name:   "split path and query",
+			format: `$remote_addr "$request_method" "$uri" "$args" $status`,
+			line:   `10.0.0.1 "GET" "/search" "q=shoes" 200`,
+			want:   config.Request{Method: "GET", Path: "/search?q=shoes"},
+		},
+		{
+			name:   "combined by name",
+			format: "combined",
+			line:   `10.0.0.1 - - [10/Oct/2026:13:55:36 +0000] "HEAD / HTTP/1.1" 200 0 "-" "probe/1.0"`,
+			want:   config.Request{Method: "HEAD", Path: "/", Headers: map[string]string{"User-Agent": "probe/1.0"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ImportAccessLog([]byte(tt.line+"\n"), "access.log", AccessLogOptions{BaseURL: "https://example.com", Format: tt.format})
+			if err != nil {
+				t.Fatalf("ImportAccessLog() error = %v", err)
+			}
+			if result.Suite == nil {
+				t.Fatalf("line was not imported: %v", result.Unsupported)
+			}
+			if got := result.Suite.Spec.Tests[0].Request; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("request = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportAccessLogInvalidFormat(t *testing.T) {
+	for _, format := range []string{"no fields here", `$remote_addr $status`} {
+		if _, err := ImportAccessLog([]byte("x\n"), "access.log", AccessLogOptions{BaseURL: "https://example.com", Format: format}); err == nil {
+			t.Errorf("ImportAccessLog() expected error for format %q", format)
+		}
+	}
+}
+
+func TestImportAccessLogDedupe(t *testing.T) {
+	log := `1.2.3.4 - - [x] "GET /users/1?tab=a HTTP/1.1" 200 1
+1.2.3.4 - - [x] "GET /users/2?tab=b HTTP/1.1" 200 1
+1.2.3.4 - - [x] "GET /users/3?tab=c&sort=d HTTP/1.1" 200 1
+1.2.3.4 - - [x] "POST /users/4?tab=a HTTP/1.1" 200 1
+1.2.3.4 - - [x] "GET /users/5 HTTP/1.1" 200 1
+`
+	result, err := ImportAccessLog([]byte(log), "access.log", AccessLogOptions{BaseURL: "https://example.com", Dedupe: true})
+	if err != nil {
+		t.Fatalf("ImportAccessLog() error = %v", err)
+	}
+
+	var paths []string
+	for _, test := range result.Suite.Spec.Tests {
+		paths = append(paths, test.Request.Method+" "+test.Request.Path)
+	}
+	want := []string{"GET /users/1?tab=a", "GET /users/3?tab=c&sort=d", "POST /users/4?tab=a", "GET /users/5"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("deduplicated requests = %v, want %v", paths, want)
+	}
+	if !strings.Contains(result.Suite.Metadata.Description, "1 duplicate") {
+		t.Errorf("Description = %q", result.Suite.Metadata.Description)
+	}
+}
+
+func TestRequestShape(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{"GET", "/users/42", "GET /users/{id}"},
+		{"GET", "/search?q=a&page=2&q=b", "GET /search?page&q"},
+		{"POST", "/search?page=1&q=x", "POST /search?page&q"},
+	}
+
+	for _, tt := range tests {
+		if got := RequestShape(tt.method, tt.path); got != tt.want {
+			t.Errorf("RequestShape(%s, %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
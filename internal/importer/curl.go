@@ -0,0 +1,464 @@
+package importer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"wafguard/internal/core/config"
+)
+
+// CurlOptions controls how curl command lines are converted.
+type CurlOptions struct {
+	// Name is used for the suite; it defaults to one derived from the
+	// first request.
+	Name string
+	// BaseURL replaces the scheme and host of every command.
+	BaseURL string
+	// Expected is applied to every converted test.
+	Expected config.Expected
+}
+
+// curlBoolFlags are short options that take no value and may be clustered,
+// e.g. -sSLk. They do not change the request wafguard sends.
+const curlBoolFlags = "sSLkivfgGI#"
+
+// curlIgnoredValueFlags take a value that has no effect on the request
+// itself, so the value is consumed and dropped.
+var curlIgnoredValueFlags = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true,
+	"--connect-timeout": true, "-w": true, "--write-out": true,
+	"-x": true, "--proxy": true, "--resolve": true, "--cacert": true,
+	"--cert": true, "--key": true, "-c": true, "--cookie-jar": true,
+	"--retry": true, "--max-redirs": true,
+}
+
+type curlRequest struct {
+	method  string
+	url     string
+	headers map[string]string
+	data    []string
+	get     bool
+	head    bool
+}
+
+// ImportCurl converts one or more curl command lines into a suite with one
+// test per command. Commands may span lines with trailing backslashes, as
+// produced by browser "Copy as cURL". All commands must target the same
+// host unless opts.BaseURL is set.
+func ImportCurl(input string, opts CurlOptions) (*Result, error) {
+	commands, err := splitCommands(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("no curl command found")
+	}
+
+	result := &Result{Source: "curl"}
+	var tests []config.Test
+	baseURL := opts.BaseURL
+	used := make(map[string]int)
+
+	for i, args := range commands {
+		req, err := parseCurlArgs(args, result)
+		if err != nil {
+			return nil, fmt.Errorf("command %d: %w", i+1, err)
+		}
+
+		test, commandBase, err := req.toTest()
+		if err != nil {
+			return nil, fmt.Errorf("command %d: %w", i+1, err)
+		}
+
+		if baseURL == "" {
+			baseURL = commandBase
+		} else if opts.BaseURL == "" && commandBase != baseURL {
+			return nil, fmt.Errorf("command %d targets %s but previous commands target %s, use a base URL override to convert them together", i+1, commandBase, baseURL)
+		}
+
+		test.Name = uniqueName(test.Name, used)
+		test.Expected = opts.Expected
+		tests = append(tests, test)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = "curl-" + tests[0].Name
+	}
+
+	result.Suite = newSuite(sanitizeName(name), "Converted from curl", baseURL)
+	result.Suite.Spec.Tests = tests
+
+	return result, nil
+}
+
+func parseCurlArgs(args []string, result *Result) (*curlRequest, error) {
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, fmt.Errorf("not a curl command")
+	}
+
+	req := &curlRequest{headers: make(map[string]string)}
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		flag, value, hasValue := splitCurlFlag(arg)
+		next := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s requires a value", flag)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch flag {
+		case "-X", "--request":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			req.method = strings.ToUpper(v)
+		case "-H", "--header":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			key, val, ok := strings.Cut(v, ":")
+			if !ok {
+				result.skip("header %q has no value and was ignored", v)
+				continue
+			}
+			req.headers[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw", "--data-urlencode":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if flag != "--data-raw" && strings.HasPrefix(v, "@") {
+				result.skip("%s %s reads from a file and was ignored", flag, v)
+				continue
+			}
+			if flag == "--data-urlencode" {
+				v = curlURLEncode(v)
+			}
+			req.data = append(req.data, v)
+		case "-b", "--cookie":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if !strings.Contains(v, "=") {
+				result.skip("cookie file %q was ignored", v)
+				continue
+			}
+			req.headers["Cookie"] = v
+		case "-u", "--user":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			req.headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(v))
+		case "-A", "--user-agent":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			req.headers["User-Agent"] = v
+		case "-e", "--referer":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			req.headers["Referer"] = v
+		case "--url":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			req.url = v
+		case "-G", "--get":
+			req.get = true
+		case "-I", "--head":
+			req.head = true
+		case "-F", "--form":
+			if _, err := next(); err != nil {
+				return nil, err
+			}
+			result.skip("multipart form data (%s) is not supported and was ignored", flag)
+		case "--compressed", "--insecure", "--location", "--silent", "--show-error",
+			"--include", "--verbose", "--fail", "--globoff", "--http1.1", "--http2", "--path-as-is":
+			// No effect on the request wafguard sends
+		default:
+			switch {
+			case curlIgnoredValueFlags[flag]:
+				if _, err := next(); err != nil {
+					return nil, err
+				}
+			case strings.HasPrefix(arg, "--"):
+				result.skip("option %s is not supported and was ignored", arg)
+			case strings.HasPrefix(arg, "-") && len(arg) > 1:
+				if strings.Trim(arg[1:], curlBoolFlags) != "" {
+					result.skip("option %s is not supported and was ignored", arg)
+				}
+				if strings.ContainsRune(arg, 'G') {
+					req.get = true
+				}
+				if strings.ContainsRune(arg, 'I') {
+					req.head = true
+				}
+			case req.url == "":
+				req.url = arg
+			default:
+				result.skip("extra URL %s was ignored", arg)
+			}
+		}
+	}
+
+	if req.url == "" {
+		return nil, fmt.Errorf("no URL in curl command")
+	}
+
+	return req, nil
+}
+
+// splitCurlFlag separates attached short option values, e.g. -XPOST or
+// -H'Accept: */*'. Long options and clustered boolean flags are returned
+// unchanged.
+func splitCurlFlag(arg string) (flag, value string, hasValue bool) {
+	if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' && strings.ContainsRune("XHdbuAe", rune(arg[1])) {
+		return arg[:2], arg[2:], true
+	}
+	return arg, "", false
+}
+
+// curlURLEncode mimics --data-urlencode: "name=content" encodes only the
+// content, anything else is encoded as a whole.
+func curlURLEncode(v string) string {
+	if name, content, ok := strings.Cut(v, "="); ok {
+		if name == "" {
+			return url.QueryEscape(content)
+		}
+		return name + "=" + url.QueryEscape(content)
+	}
+	return url.QueryEscape(v)
+}
+
+func (r *curlRequest) toTest() (config.Test, string, error) {
+	raw := r.url
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return config.Test{}, "", fmt.Errorf("invalid URL %s: %w", r.url, err)
+	}
+	if u.Host == "" {
+		return config.Test{}, "", fmt.Errorf("URL %s has no host", r.url)
+	}
+
+	body := strings.Join(r.data, "&")
+
+	// Keep the path and query exactly as typed, payloads are often
+	// deliberately malformed.
+	authority := raw[strings.Index(raw, "://")+3:]
+	path := ""
+	if i := strings.IndexAny(authority, "/?#"); i >= 0 {
+		path, _, _ = strings.Cut(authority[i:], "#")
+	}
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+
+	if r.get && body != "" {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		path += sep + body
+		body = ""
+	}
+
+	method := r.method
+	switch {
+	case method != "":
+	case r.head:
+		method = "HEAD"
+	case body != "":
+		method = "POST"
+	default:
+		method = "GET"
+	}
+
+	headers := r.headers
+	if u.User != nil && !hasHeader(headers, "Authorization") {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(u.User.String()))
+	}
+	if body != "" && !hasHeader(headers, "Content-Type") {
+		headers["Content-Type"] = "application/x-www-form-urlencoded"
+	}
+	if len(headers) == 0 {
+		headers = nil
+	}
+
+	test := config.Test{
+		Name: testNameFor(method, path),
+		Request: config.Request{
+			Method:  method,
+			Path:    path,
+			Headers: headers,
+			Body:    body,
+		},
+	}
+
+	base := url.URL{Scheme: u.Scheme, Host: u.Host}
+	return test, base.String(), nil
+}
+
+// splitCommands splits shell input into commands and their words. Unquoted
+// newlines separate commands, backslash-newline continues a command. Single,
+// double and ANSI-C ($'...') quoting are supported.
+func splitCommands(input string) ([][]string, error) {
+	var commands [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			commands = append(commands, words)
+			words = nil
+		}
+	}
+
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(input) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			if input[i] == '\n' {
+				continue
+			}
+			if input[i] == '\r' && i+1 < len(input) && input[i+1] == '\n' {
+				i++
+				continue
+			}
+			word.WriteByte(input[i])
+			inWord = true
+		case c == '\'':
+			end := strings.IndexByte(input[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(input[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			j := i + 1
+			for ; j < len(input) && input[j] != '"'; j++ {
+				if input[j] == '\\' && j+1 < len(input) && strings.IndexByte("\"\\$`\n", input[j+1]) >= 0 {
+					j++
+					if input[j] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(input[j])
+			}
+			if j >= len(input) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			i = j
+			inWord = true
+		case c == '$' && i+1 < len(input) && input[i+1] == '\'':
+			n, err := readANSIQuoted(input[i+2:], &word)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 1
+			inWord = true
+		case c == '\n' || c == ';':
+			endCommand()
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	endCommand()
+
+	return commands, nil
+}
+
+// readANSIQuoted decodes the body of a $'...' string into word and returns
+// the number of bytes consumed, including the closing quote.
+func readANSIQuoted(s string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			return i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(s) {
+			word.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			word.WriteByte('\n')
+		case 'r':
+			word.WriteByte('\r')
+		case 't':
+			word.WriteByte('\t')
+		case '0':
+			word.WriteByte(0)
+		case 'x', 'u':
+			size := 2
+			if s[i] == 'u' {
+				size = 4
+			}
+			end := i + 1
+			for end < len(s) && end < i+1+size && isHex(s[end]) {
+				end++
+			}
+			if end == i+1 {
+				word.WriteByte('\\')
+				word.WriteByte(s[i])
+				continue
+			}
+			v, _ := strconv.ParseUint(s[i+1:end], 16, 32)
+			if s[i] == 'x' {
+				word.WriteByte(byte(v))
+			} else {
+				var buf [utf8.UTFMax]byte
+				word.Write(buf[:utf8.EncodeRune(buf[:], rune(v))])
+			}
+			i = end - 1
+		default:
+			word.WriteByte(s[i])
+		}
+	}
+	return 0, fmt.Errorf("unterminated $'...' quote")
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,236 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+	"wafguard/internal/core/config"
+	"wafguard/internal/parser"
+)
+
+func TestSplitCommands(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name:  "simple",
+			input: `curl https://example.com/`,
+			want:  [][]string{{"curl", "https://example.com/"}},
+		},
+		{
+			name:  "quotes",
+			input: `curl -H 'X-Test: a b' -d "x=\"1\"" https://example.com`,
+			want:  [][]string{{"curl", "-H", "X-Test: a b", "-d", `x="1"`, "https://example.com"}},
+		},
+		{
+			name:  "line continuation",
+			input: "curl 'https://example.com/' \\\n  -H 'Accept: */*' \\\n  --compressed",
+			want:  [][]string{{"curl", "https://example.com/", "-H", "Accept: */*", "--compressed"}},
+		},
+		{
+			name:  "ansi c quoting",
+			input: `curl --data-raw $'a=1\nb=\'2\'\x41é' https://example.com`,
+			want:  [][]string{{"curl", "--data-raw", "a=1\nb='2'Aé", "https://example.com"}},
+		},
+		{
+			name:  "multiple commands",
+			input: "curl https://example.com/a\n\ncurl https://example.com/b\n",
+			want:  [][]string{{"curl", "https://example.com/a"}, {"curl", "https://example.com/b"}},
+		},
+		{
+			name:    "unterminated quote",
+			input:   `curl 'https://example.com`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitCommands(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitCommands() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCommands() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportCurl(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		wantBaseURL string
+		wantRequest config.Request
+	}{
+		{
+			name:        "plain get",
+			command:     `curl "https://example.com/search?q=<script>alert(1)</script>"`,
+			wantBaseURL: "https://example.com",
+			wantRequest: config.Request{
+				Method: "GET",
+				Path:   "/search?q=<script>alert(1)</script>",
+			},
+		},
+		{
+			name:        "post data defaults",
+			command:     `curl -d "user=admin' OR 1=1--" -d pass=x http://example.com:8080/login`,
+			wantBaseURL: "http://example.com:8080",
+			wantRequest: config.Request{
+				Method:  "POST",
+				Path:    "/login",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				Body:    "user=admin' OR 1=1--&pass=x",
+			},
+		},
+		{
+			name:        "headers cookies and auth",
+			command:     `curl -XPUT -H 'Content-Type: application/json' -b 'session=abc; id=1' -u admin:secret -A evil --data-raw '{"a":1}' example.com/api`,
+			wantBaseURL: "http://example.com",
+			wantRequest: config.Request{
+				Method: "PUT",
+				Path:   "/api",
+				Headers: map[string]string{
+					"Content-Type":  "application/json",
+					"Cookie":        "session=abc; id=1",
+					"Authorization": "Basic YWRtaW46c2VjcmV0",
+					"User-Agent":    "evil",
+				},
+				Body: `{"a":1}`,
+			},
+		},
+		{
+			name:        "get with data",
+			command:     `curl -sG --data-urlencode "q=1 union select" https://example.com/s?x=1`,
+			wantBaseURL: "https://example.com",
+			wantRequest: config.Request{
+				Method: "GET",
+				Path:   "/s?x=1&q=1+union+select",
+			},
+		},
+		{
+			name:        "head request without path",
+			command:     `curl -I https://example.com`,
+			wantBaseURL: "https://example.com",
+			wantRequest: config.Request{
+				Method: "HEAD",
+				Path:   "/",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocked := true
+			result, err := ImportCurl(tt.command, CurlOptions{Expected: config.Expected{Blocked: &blocked}})
+			if err != nil {
+				t.Fatalf("ImportCurl() error = %v", err)
+			}
+
+			suite := result.Suite
+			if suite.Spec.Target.BaseURL != tt.wantBaseURL {
+				t.Errorf("BaseURL = %q, want %q", suite.Spec.Target.BaseURL, tt.wantBaseURL)
+			}
+			if len(suite.Spec.Tests) != 1 {
+				t.Fatalf("got %d tests, want 1", len(suite.Spec.Tests))
+			}
+			if got := suite.Spec.Tests[0].Request; !reflect.DeepEqual(got, tt.wantRequest) {
+				t.Errorf("Request = %+v, want %+v", got, tt.wantRequest)
+			}
+
+			data, err := Marshal(suite)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if _, err := parser.NewParser().ParseYAML(data); err != nil {
+				t.Errorf("converted suite does not parse: %v\n%s", err, data)
+			}
+		})
+	}
+}
+
+func TestImportCurlErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+	}{
+		{"empty", ""},
+		{"not curl", "wget https://example.com"},
+		{"no url", "curl -H 'A: b'"},
+		{"missing value", "curl https://example.com -H"},
+		{"mixed hosts", "curl https://a.example.com\ncurl https://b.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ImportCurl(tt.command, CurlOptions{}); err == nil {
+				t.Error("ImportCurl() expected error")
+			}
+		})
+	}
+}
+
+func TestImportCurlMultipleCommands(t *testing.T) {
+	input := "curl https://a.example.com/x\ncurl https://b.example.com/x\n"
+
+	result, err := ImportCurl(input, CurlOptions{BaseURL: "https://waf.example.com", Name: "Pentest Findings"})
+	if err != nil {
+		t.Fatalf("ImportCurl() error = %v", err)
+	}
+
+	if result.Suite.Metadata.Name != "pentest-findings" {
+		t.Errorf("Metadata.Name = %q", result.Suite.Metadata.Name)
+	}
+	if result.Suite.Spec.Target.BaseURL != "https://waf.example.com" {
+		t.Errorf("BaseURL = %q", result.Suite.Spec.Target.BaseURL)
+	}
+
+	names := []string{result.Suite.Spec.Tests[0].Name, result.Suite.Spec.Tests[1].Name}
+	if !reflect.DeepEqual(names, []string{"get-x", "get-x-2"}) {
+		t.Errorf("test names = %v, want unique names", names)
+	}
+}
+
+func TestImportCurlUnsupportedOptions(t *testing.T) {
+	result, err := ImportCurl(`curl -F file=@x.txt --data @body.json --tcp-nodelay -svk -o out.txt https://example.com`, CurlOptions{})
+	if err != nil {
+		t.Fatalf("ImportCurl() error = %v", err)
+	}
+
+	if len(result.Unsupported) != 3 {
+		t.Errorf("Unsupported = %v, want 3 notes", result.Unsupported)
+	}
+}
+
+func TestParseExpectation(t *testing.T) {
+	blocked := true
+	allowed := false
+
+	tests := []struct {
+		value   string
+		want    config.Expected
+		wantErr bool
+	}{
+		{value: "blocked", want: config.Expected{Blocked: &blocked}},
+		{value: "Allowed", want: config.Expected{Blocked: &allowed}},
+		{value: "403", want: config.Expected{Status: []int{403}}},
+		{value: "200, 404", want: config.Expected{Status: []int{200, 404}}},
+		{value: "maybe", wantErr: true},
+		{value: "42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseExpectation(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExpectation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseExpectation() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"wafguard/internal/core/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FTWOptions controls how go-ftw regression tests are converted.
+type FTWOptions struct {
+	// BaseURL replaces the dest_addr/port/protocol of every stage. When
+	// empty it is derived from the first stage of each file.
+	BaseURL string
+	// LogAsBlocked imports stages that only expect rule hits in the WAF log
+	// (log_contains, expect_ids) as `blocked: true`. Under anomaly scoring
+	// a single rule hit does not block the request, so they are skipped by
+	// default.
+	LogAsBlocked bool
+}
+
+type ftwFile struct {
+	Meta struct {
+		Author      string `yaml:"author"`
+		Description string `yaml:"description"`
+		Enabled     *bool  `yaml:"enabled"`
+		Name        string `yaml:"name"`
+	} `yaml:"meta"`
+	RuleID int       `yaml:"rule_id"`
+	Tests  []ftwTest `yaml:"tests"`
+}
+
+type ftwTest struct {
+	Title  string     `yaml:"test_title"`
+	ID     int        `yaml:"test_id"`
+	Desc   string     `yaml:"desc"`
+	Stages []ftwStage `yaml:"stages"`
+}
+
+// ftwStage accepts both the legacy layout, where input and output are
+// wrapped in a `stage` key, and the current flat layout.
+type ftwStage struct {
+	Stage  *ftwStage `yaml:"stage"`
+	Input  ftwInput  `yaml:"input"`
+	Output ftwOutput `yaml:"output"`
+}
+
+type ftwInput struct {
+	DestAddr            string            `yaml:"dest_addr"`
+	Port                int               `yaml:"port"`
+	Protocol            string            `yaml:"protocol"`
+	Method              string            `yaml:"method"`
+	URI                 string            `yaml:"uri"`
+	Version             string            `yaml:"version"`
+	Headers             map[string]string `yaml:"headers"`
+	Data                ftwData           `yaml:"data"`
+	EncodedRequest      string            `yaml:"encoded_request"`
+	RawRequest          string            `yaml:"raw_request"`
+	AutocompleteHeaders *bool             `yaml:"autocomplete_headers"`
+}
+
+type ftwOutput struct {
+	Status           ftwStatus `yaml:"status"`
+	ResponseContains string    `yaml:"response_contains"`
+	LogContains      string    `yaml:"log_contains"`
+	NoLogContains    string    `yaml:"no_log_contains"`
+	ExpectError      bool      `yaml:"expect_error"`
+	Log              struct {
+		ExpectIDs   []int `yaml:"expect_ids"`
+		NoExpectIDs []int `yaml:"no_expect_ids"`
+	} `yaml:"log"`
+}
+
+// ftwData is a request body given either as a string or, in older test
+// files, as a list of lines joined with CRLF.
+type ftwData string
+
+func (d *ftwData) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var lines []string
+		if err := node.Decode(&lines); err != nil {
+			return err
+		}
+		*d = ftwData(strings.Join(lines, "\r\n"))
+		return nil
+	}
+
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	*d = ftwData(s)
+	return nil
+}
+
+// ftwStatus is an expected status given either as a single code or a list.
+type ftwStatus []int
+
+func (s *ftwStatus) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var codes []int
+		if err := node.Decode(&codes); err != nil {
+			return err
+		}
+		*s = codes
+		return nil
+	}
+
+	var code int
+	if err := node.Decode(&code); err != nil {
+		return err
+	}
+	*s = ftwStatus{code}
+	return nil
+}
+
+var ftwLogRuleID = regexp.MustCompile(`id\s*"?(\d{6})`)
+
+// ImportFTW converts a single go-ftw test file into a SentinelTest suite.
+// Each stage becomes one test; stages relying on raw or encoded requests,
+// or on expectations that cannot be observed from the client side, are
+// listed in Result.Unsupported.
+func ImportFTW(data []byte, source string, opts FTWOptions) (*Result, error) {
+	var file ftwFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse ftw file %s: %w", source, err)
+	}
+
+	result := &Result{Source: source}
+
+	if file.Meta.Enabled != nil && !*file.Meta.Enabled {
+		result.skip("file is disabled (meta.enabled: false)")
+		return result, nil
+	}
+
+	base := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	name := file.Meta.Name
+	if name == "" {
+		name = base
+	}
+	name = sanitizeName(strings.TrimSuffix(name, filepath.Ext(name)))
+
+	ruleID := ""
+	if file.RuleID != 0 {
+		ruleID = strconv.Itoa(file.RuleID)
+	} else if _, err := strconv.Atoi(base); err == nil {
+		ruleID = base
+	}
+
+	var tests []config.Test
+	baseURL := opts.BaseURL
+	used := make(map[string]int)
+
+	for i, test := range file.Tests {
+		title := test.Title
+		if title == "" && ruleID != "" && test.ID != 0 {
+			title = fmt.Sprintf("%s-%d", ruleID, test.ID)
+		}
+		if title == "" {
+			title = fmt.Sprintf("%s-%d", name, i+1)
+		}
+
+		for j, stage := range test.Stages {
+			if stage.Stage != nil {
+				stage = *stage.Stage
+			}
+
+			testName := sanitizeName(title)
+			if len(test.Stages) > 1 {
+				testName = fmt.Sprintf("%s-stage-%d", testName, j+1)
+			}
+
+			converted, ok := convertFTWStage(stage, testName, opts, result)
+			if !ok {
+				continue
+			}
+
+			converted.Name = uniqueName(testName, used)
+			converted.Tags = ftwTags(ruleID, stage.Output)
+
+			if baseURL == "" {
+				baseURL = ftwBaseURL(stage.Input)
+			}
+
+			tests = append(tests, converted)
+		}
+	}
+
+	if len(tests) == 0 {
+		return result, nil
+	}
+
+	description := file.Meta.Description
+	if description == "" {
+		description = fmt.Sprintf("Imported from go-ftw test file %s", filepath.Base(source))
+	}
+
+	result.Suite = newSuite(name, description, baseURL)
+	result.Suite.Spec.Tests = tests
+
+	return result, nil
+}
+
+// ImportFTWDir converts every YAML file under dir. Files that cannot be
+// parsed are reported in their Result instead of aborting the import.
+func ImportFTWDir(dir string, opts FTWOptions) ([]*Result, error) {
+	var results []*Result
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || (filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+
+		result, err := ImportFTW(data, path, opts)
+		if err != nil {
+			result = &Result{Source: path}
+			result.skip("%v", err)
+		}
+		results = append(results, result)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to import directory %s: %w", dir, err)
+	}
+
+	return results, nil
+}
+
+func convertFTWStage(stage ftwStage, name string, opts FTWOptions, result *Result) (config.Test, bool) {
+	input := stage.Input
+	output := stage.Output
+
+	if input.EncodedRequest != "" || input.RawRequest != "" {
+		result.skip("%s: raw/encoded requests cannot be replayed", name)
+		return config.Test{}, false
+	}
+	if output.ExpectError {
+		result.skip("%s: expect_error cannot be asserted", name)
+		return config.Test{}, false
+	}
+
+	method := strings.ToUpper(input.Method)
+	if method == "" {
+		method = "GET"
+	}
+	if strings.ContainsAny(method, " \t") {
+		result.skip("%s: invalid method %q", name, input.Method)
+		return config.Test{}, false
+	}
+
+	path := input.URI
+	if path == "" {
+		path = "/"
+	}
+
+	if input.Version != "" && input.Version != "HTTP/1.1" {
+		result.skip("%s: HTTP version %q is ignored", name, input.Version)
+	}
+	if input.AutocompleteHeaders != nil && !*input.AutocompleteHeaders {
+		result.skip("%s: autocomplete_headers: false is ignored, standard headers will be added", name)
+	}
+
+	var headers map[string]string
+	for key, value := range input.Headers {
+		// The Host header must match the target under test rather than
+		// the CRS test environment.
+		if strings.EqualFold(key, "Host") {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[key] = value
+	}
+
+	if len(output.Status) == 0 && (output.LogContains != "" || len(output.Log.ExpectIDs) > 0) && !opts.LogAsBlocked {
+		result.skip("%s: log_contains/expect_ids only show that a rule matched, which does not block on its own under anomaly scoring", name)
+		return config.Test{}, false
+	}
+
+	expected, ok := ftwExpected(output)
+	if !ok {
+		result.skip("%s: no supported output expectation", name)
+		return config.Test{}, false
+	}
+
+	return config.Test{
+		Request: config.Request{
+			Method:  method,
+			Path:    path,
+			Headers: headers,
+			Body:    string(input.Data),
+		},
+		Expected: expected,
+	}, true
+}
+
+// ftwExpected maps ftw output checks onto config.Expected. An explicit
+// status wins; otherwise rule hits in the WAF log become `blocked: true`,
+// which callers only allow with FTWOptions.LogAsBlocked, and asserted
+// absences become `blocked: false`.
+func ftwExpected(output ftwOutput) (config.Expected, bool) {
+	var expected config.Expected
+	ok := false
+
+	switch {
+	case len(output.Status) > 0:
+		expected.Status = output.Status
+		ok = true
+	case output.LogContains != "" || len(output.Log.ExpectIDs) > 0:
+		blocked := true
+		expected.Blocked = &blocked
+		ok = true
+	case output.NoLogContains != "" || len(output.Log.NoExpectIDs) > 0:
+		blocked := false
+		expected.Blocked = &blocked
+		ok = true
+	}
+
+	if output.ResponseContains != "" {
+		expected.Body = &config.BodyExpected{
+			Contains: []string{output.ResponseContains},
+		}
+	}
+
+	return expected, ok
+}
+
+func ftwTags(ruleID string, output ftwOutput) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			tags = append(tags, id)
+		}
+	}
+
+	add(ruleID)
+	for _, id := range output.Log.ExpectIDs {
+		add(strconv.Itoa(id))
+	}
+	for _, match := range ftwLogRuleID.FindAllStringSubmatch(output.LogContains, -1) {
+		add(match[1])
+	}
+
+	return tags
+}
+
+func ftwBaseURL(input ftwInput) string {
+	scheme := input.Protocol
+	if scheme == "" {
+		scheme = "http"
+	}
+	host := input.DestAddr
+	if host == "" {
+		host = "localhost"
+	}
+
+	u := url.URL{Scheme: scheme, Host: host}
+	if input.Port != 0 && !(scheme == "http" && input.Port == 80) && !(scheme == "https" && input.Port == 443) {
+		u.Host = fmt.Sprintf("%s:%d", host, input.Port)
+	}
+	return u.String()
+}
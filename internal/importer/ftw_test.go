@@ -0,0 +1,290 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"wafguard/internal/parser"
+)
+
+const legacyFTW = `
+---
+meta:
+  author: csanders-git
+  enabled: true
+  name: 920100.yaml
+  description: Invalid HTTP Request Line
+tests:
+- test_title: 920100-1
+  stages:
+  - stage:
+      input:
+        dest_addr: 127.0.0.1
+        port: 80
+        method: GET
+        headers:
+          Host: localhost
+          User-Agent: OWASP CRS test agent
+        uri: /?test=test1
+      output:
+        no_log_contains: id "920100"
+- test_title: 920100-2
+  stages:
+  - stage:
+      input:
+        dest_addr: 127.0.0.1
+        port: 80
+        method: POST
+        headers:
+          Host: localhost
+        data:
+        - "a=1"
+        - "b=2"
+      output:
+        log_contains: id "920100"
+- test_title: 920100-3
+  stages:
+  - stage:
+      input:
+        encoded_request: R0VUIC8gSFRUUC8xLjENCg0K
+      output:
+        status: 400
+`
+
+const currentFTW = `
+---
+meta:
+  author: crs-team
+  description: SQL injection
+rule_id: 942100
+tests:
+- test_id: 1
+  desc: libinjection
+  stages:
+  - input:
+      dest_addr: waf.example.com
+      port: 443
+      protocol: https
+      uri: "/get?var=1' or '1'='1"
+    output:
+      log:
+        expect_ids: [942100]
+  - input:
+      uri: /get?var=safe
+    output:
+      status: [200, 404]
+      response_contains: safe
+- test_id: 2
+  stages:
+  - input:
+      uri: /get
+    output:
+      expect_error: true
+`
+
+func TestImportFTWLegacyFormat(t *testing.T) {
+	result, err := ImportFTW([]byte(legacyFTW), "rules/920100.yaml", FTWOptions{LogAsBlocked: true})
+	if err != nil {
+		t.Fatalf("ImportFTW() error = %v", err)
+	}
+
+	suite := result.Suite
+	if suite == nil {
+		t.Fatal("ImportFTW() returned no suite")
+	}
+
+	if suite.Metadata.Name != "920100" {
+		t.Errorf("Metadata.Name = %q, want %q", suite.Metadata.Name, "920100")
+	}
+	if suite.Spec.Target.BaseURL != "http://127.0.0.1" {
+		t.Errorf("BaseURL = %q, want %q", suite.Spec.Target.BaseURL, "http://127.0.0.1")
+	}
+	if len(suite.Spec.Tests) != 2 {
+		t.Fatalf("got %d tests, want 2", len(suite.Spec.Tests))
+	}
+
+	allowed := suite.Spec.Tests[0]
+	if allowed.Name != "920100-1" {
+		t.Errorf("Name = %q, want %q", allowed.Name, "920100-1")
+	}
+	if allowed.Expected.Blocked == nil || *allowed.Expected.Blocked {
+		t.Errorf("no_log_contains should map to blocked: false, got %v", allowed.Expected.Blocked)
+	}
+	if _, ok := allowed.Request.Headers["Host"]; ok {
+		t.Error("Host header should be dropped")
+	}
+	if allowed.Request.Headers["User-Agent"] != "OWASP CRS test agent" {
+		t.Errorf("User-Agent = %q", allowed.Request.Headers["User-Agent"])
+	}
+	if !reflect.DeepEqual(allowed.Tags, []string{"920100"}) {
+		t.Errorf("Tags = %v, want [920100]", allowed.Tags)
+	}
+
+	blocked := suite.Spec.Tests[1]
+	if blocked.Expected.Blocked == nil || !*blocked.Expected.Blocked {
+		t.Errorf("log_contains should map to blocked: true, got %v", blocked.Expected.Blocked)
+	}
+	if blocked.Request.Method != "POST" || blocked.Request.Path != "/" {
+		t.Errorf("Request = %s %s, want POST /", blocked.Request.Method, blocked.Request.Path)
+	}
+	if blocked.Request.Body != "a=1\r\nb=2" {
+		t.Errorf("Body = %q, want %q", blocked.Request.Body, "a=1\r\nb=2")
+	}
+
+	if len(result.Unsupported) != 1 || !strings.Contains(result.Unsupported[0], "encoded") {
+		t.Errorf("Unsupported = %v, want one note about encoded requests", result.Unsupported)
+	}
+}
+
+func TestImportFTWCurrentFormat(t *testing.T) {
+	result, err := ImportFTW([]byte(currentFTW), "942100.yaml", FTWOptions{BaseURL: "https://staging.example.com", LogAsBlocked: true})
+	if err != nil {
+		t.Fatalf("ImportFTW() error = %v", err)
+	}
+
+	suite := result.Suite
+	if suite == nil {
+		t.Fatal("ImportFTW() returned no suite")
+	}
+
+	if suite.Spec.Target.BaseURL != "https://staging.example.com" {
+		t.Errorf("BaseURL = %q, want override", suite.Spec.Target.BaseURL)
+	}
+	if len(suite.Spec.Tests) != 2 {
+		t.Fatalf("got %d tests, want 2", len(suite.Spec.Tests))
+	}
+
+	first := suite.Spec.Tests[0]
+	if first.Name != "942100-1-stage-1" {
+		t.Errorf("Name = %q, want %q", first.Name, "942100-1-stage-1")
+	}
+	if first.Request.Method != "GET" {
+		t.Errorf("Method = %q, want GET", first.Request.Method)
+	}
+	if first.Expected.Blocked == nil || !*first.Expected.Blocked {
+		t.Errorf("expect_ids should map to blocked: true, got %v", first.Expected.Blocked)
+	}
+
+	second := suite.Spec.Tests[1]
+	if !reflect.DeepEqual(second.Expected.Status, []int{200, 404}) {
+		t.Errorf("Status = %v, want [200 404]", second.Expected.Status)
+	}
+	if second.Expected.Body == nil || second.Expected.Body.Contains[0] != "safe" {
+		t.Errorf("response_contains should map to body.contains, got %+v", second.Expected.Body)
+	}
+
+	if len(result.Unsupported) != 1 || !strings.Contains(result.Unsupported[0], "expect_error") {
+		t.Errorf("Unsupported = %v, want one note about expect_error", result.Unsupported)
+	}
+
+	data, err := Marshal(suite)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if _, err := parser.NewParser().ParseYAML(data); err != nil {
+		t.Errorf("imported suite does not parse: %v\n%s", err, data)
+	}
+}
+
+func TestImportFTWSkipsLogOnlyStages(t *testing.T) {
+	result, err := ImportFTW([]byte(legacyFTW), "rules/920100.yaml", FTWOptions{})
+	if err != nil {
+		t.Fatalf("ImportFTW() error = %v", err)
+	}
+
+	tests := result.Suite.Spec.Tests
+	if len(tests) != 1 || tests[0].Name != "920100-1" {
+		t.Fatalf("Tests = %+v, want only the no_log_contains stage", tests)
+	}
+
+	notes := strings.Join(result.Unsupported, "\n")
+	if len(result.Unsupported) != 2 || !strings.Contains(notes, "920100-2: log_contains/expect_ids") {
+		t.Errorf("Unsupported = %v, want a note about the log_contains stage", result.Unsupported)
+	}
+}
+
+func TestImportFTWDerivedBaseURL(t *testing.T) {
+	result, err := ImportFTW([]byte(currentFTW), "942100.yaml", FTWOptions{LogAsBlocked: true})
+	if err != nil {
+		t.Fatalf("ImportFTW() error = %v", err)
+	}
+
+	if result.Suite.Spec.Target.BaseURL != "https://waf.example.com" {
+		t.Errorf("BaseURL = %q, want %q", result.Suite.Spec.Target.BaseURL, "https://waf.example.com")
+	}
+}
+
+func TestImportFTWDisabled(t *testing.T) {
+	data := `
+meta:
+  enabled: false
+tests:
+- test_title: disabled
+  stages:
+  - input:
+      uri: /
+    output:
+      status: 200
+`
+	result, err := ImportFTW([]byte(data), "disabled.yaml", FTWOptions{})
+	if err != nil {
+		t.Fatalf("ImportFTW() error = %v", err)
+	}
+
+	if result.Suite != nil {
+		t.Error("disabled file should not produce a suite")
+	}
+	if len(result.Unsupported) != 1 {
+		t.Errorf("Unsupported = %v, want one note", result.Unsupported)
+	}
+}
+
+func TestImportFTWInvalidYAML(t *testing.T) {
+	if _, err := ImportFTW([]byte("tests: [unclosed"), "bad.yaml", FTWOptions{}); err == nil {
+		t.Error("ImportFTW() expected error for invalid YAML")
+	}
+}
+
+func TestImportFTWDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	ruleDir := filepath.Join(tmpDir, "REQUEST-920-PROTOCOL-ENFORCEMENT")
+	if err := os.MkdirAll(ruleDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		filepath.Join(ruleDir, "920100.yaml"): legacyFTW,
+		filepath.Join(tmpDir, "942100.yml"):   currentFTW,
+		filepath.Join(tmpDir, "broken.yaml"):  "tests: [unclosed",
+		filepath.Join(tmpDir, "README.md"):    "not a test",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := ImportFTWDir(tmpDir, FTWOptions{})
+	if err != nil {
+		t.Fatalf("ImportFTWDir() error = %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+
+	converted := 0
+	for _, result := range results {
+		if result.Suite != nil {
+			converted++
+		}
+		if strings.HasSuffix(result.Source, "broken.yaml") && len(result.Unsupported) == 0 {
+			t.Error("broken file should be reported in Unsupported")
+		}
+	}
+	if converted != 2 {
+		t.Errorf("converted %d files, want 2", converted)
+	}
+}
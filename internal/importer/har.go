@@ -0,0 +1,211 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"wafguard/internal/core/config"
+)
+
+// HAROptions controls how HAR captures are converted.
+type HAROptions struct {
+	// Name is used for the suite; it defaults to one derived from the host.
+	Name string
+	// BaseURL sends every entry to this target and produces a single suite.
+	BaseURL string
+	// Host keeps only entries for this host (host or host:port).
+	Host string
+	// Expected is applied to every converted test unless UseRecordedStatus
+	// is set.
+	Expected config.Expected
+	// UseRecordedStatus expects the status recorded in the HAR.
+	UseRecordedStatus bool
+}
+
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harEntry struct {
+	Request struct {
+		Method   string         `json:"method"`
+		URL      string         `json:"url"`
+		Headers  []harNameValue `json:"headers"`
+		PostData *struct {
+			MimeType string         `json:"mimeType"`
+			Text     string         `json:"text"`
+			Params   []harNameValue `json:"params"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status int `json:"status"`
+	} `json:"response"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harDroppedHeaders are set by the HTTP client itself or describe the
+// original connection, so replaying them would be wrong.
+var harDroppedHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+	"keep-alive":        true,
+	"upgrade":           true,
+}
+
+// ImportHAR converts the entries of a HAR capture into suites, one per
+// origin, or a single suite when opts.BaseURL is set.
+func ImportHAR(data []byte, source string, opts HAROptions) ([]*Result, error) {
+	var file harFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR file %s: %w", source, err)
+	}
+
+	var origins []string
+	byOrigin := make(map[string]*Result)
+	used := make(map[string]map[string]int)
+
+	// Notes for entries that never reach a suite are attached to the first
+	// result, or to an empty one if nothing converted.
+	var notes []string
+	skip := func(format string, args ...interface{}) {
+		notes = append(notes, fmt.Sprintf(format, args...))
+	}
+
+	for i, entry := range file.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			skip("entry %d: unsupported URL %q", i+1, entry.Request.URL)
+			continue
+		}
+		if opts.Host != "" && !strings.EqualFold(u.Host, opts.Host) && !strings.EqualFold(u.Hostname(), opts.Host) {
+			continue
+		}
+
+		test, err := harTest(entry, u, opts)
+		if err != nil {
+			skip("entry %d (%s %s): %v", i+1, entry.Request.Method, entry.Request.URL, err)
+			continue
+		}
+
+		origin := opts.BaseURL
+		if origin == "" {
+			origin = (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+		}
+
+		result, ok := byOrigin[origin]
+		if !ok {
+			name := opts.Name
+			if name == "" {
+				name = "har-" + u.Hostname()
+			} else if opts.BaseURL == "" && len(origins) > 0 {
+				name = name + "-" + u.Hostname()
+			}
+			result = &Result{
+				Source: source,
+				Suite:  newSuite(sanitizeName(name), fmt.Sprintf("Imported from HAR capture %s", source), origin),
+			}
+			byOrigin[origin] = result
+			used[origin] = make(map[string]int)
+			origins = append(origins, origin)
+		}
+
+		test.Name = uniqueName(test.Name, used[origin])
+		result.Suite.Spec.Tests = append(result.Suite.Spec.Tests, test)
+	}
+
+	results := make([]*Result, 0, len(origins))
+	for _, origin := range origins {
+		results = append(results, byOrigin[origin])
+	}
+
+	if len(results) == 0 {
+		results = append(results, &Result{Source: source})
+	}
+	results[0].Unsupported = append(results[0].Unsupported, notes...)
+
+	return results, nil
+}
+
+func harTest(entry harEntry, u *url.URL, opts HAROptions) (config.Test, error) {
+	method := strings.ToUpper(entry.Request.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	var headers map[string]string
+	for _, h := range entry.Request.Headers {
+		name := h.Name
+		if strings.HasPrefix(name, ":") || harDroppedHeaders[strings.ToLower(name)] {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		// HTTP/2 captures may split one header, typically cookie, into
+		// several entries.
+		if existing := headerKey(headers, name); existing != "" {
+			sep := ", "
+			if strings.EqualFold(name, "Cookie") {
+				sep = "; "
+			}
+			headers[existing] += sep + h.Value
+			continue
+		}
+		headers[name] = h.Value
+	}
+
+	body := ""
+	if post := entry.Request.PostData; post != nil {
+		body = post.Text
+		if body == "" && len(post.Params) > 0 {
+			values := url.Values{}
+			for _, p := range post.Params {
+				values.Add(p.Name, p.Value)
+			}
+			body = values.Encode()
+		}
+		if body != "" && post.MimeType != "" && !hasHeader(headers, "Content-Type") {
+			if headers == nil {
+				headers = make(map[string]string)
+			}
+			headers["Content-Type"] = post.MimeType
+		}
+	}
+
+	expected := opts.Expected
+	if opts.UseRecordedStatus {
+		if entry.Response.Status == 0 {
+			return config.Test{}, fmt.Errorf("no recorded response status")
+		}
+		expected = config.Expected{Status: []int{entry.Response.Status}}
+	}
+
+	return config.Test{
+		Name: testNameFor(method, path),
+		Request: config.Request{
+			Method:  method,
+			Path:    path,
+			Headers: headers,
+			Body:    body,
+		},
+		Expected: expected,
+	}, nil
+}
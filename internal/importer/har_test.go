@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"wafguard/internal/core/config"
+	"wafguard/internal/parser"
+)
+
+const testHAR = `{
+  "log": {
+    "version": "1.2",
+    "entries": [
+      {
+        "request": {
+          "method": "GET",
+          "url": "https://shop.example.com/search?q=red+shoes",
+          "headers": [
+            {"name": ":authority", "value": "shop.example.com"},
+            {"name": "accept-encoding", "value": "gzip, br"},
+            {"name": "user-agent", "value": "Mozilla/5.0"},
+            {"name": "cookie", "value": "a=1"},
+            {"name": "cookie", "value": "b=2"}
+          ]
+        },
+        "response": {"status": 200}
+      },
+      {
+        "request": {
+          "method": "POST",
+          "url": "https://shop.example.com/cart",
+          "headers": [{"name": "Content-Length", "value": "9"}],
+          "postData": {
+            "mimeType": "application/x-www-form-urlencoded",
+            "params": [{"name": "item", "value": "42"}, {"name": "qty", "value": "1"}]
+          }
+        },
+        "response": {"status": 302}
+      },
+      {
+        "request": {"method": "GET", "url": "https://cdn.example.com/app.js", "headers": []},
+        "response": {"status": 200}
+      },
+      {
+        "request": {"method": "GET", "url": "data:image/png;base64,AAAA", "headers": []},
+        "response": {"status": 200}
+      },
+      {
+        "request": {"method": "GET", "url": "https://shop.example.com/search?q=red+shoes", "headers": []},
+        "response": {"status": 0}
+      }
+    ]
+  }
+}`
+
+func TestImportHAR(t *testing.T) {
+	allowed := false
+	results, err := ImportHAR([]byte(testHAR), "capture.har", HAROptions{Expected: config.Expected{Blocked: &allowed}})
+	if err != nil {
+		t.Fatalf("ImportHAR() error = %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want one per origin", len(results))
+	}
+
+	shop := results[0].Suite
+	if shop.Spec.Target.BaseURL != "https://shop.example.com" {
+		t.Errorf("BaseURL = %q", shop.Spec.Target.BaseURL)
+	}
+	if shop.Metadata.Name != "har-shop-example-com" {
+		t.Errorf("Metadata.Name = %q", shop.Metadata.Name)
+	}
+	if len(shop.Spec.Tests) != 3 {
+		t.Fatalf("got %d shop tests, want 3", len(shop.Spec.Tests))
+	}
+
+	search := shop.Spec.Tests[0]
+	if search.Request.Path != "/search?q=red+shoes" {
+		t.Errorf("Path = %q", search.Request.Path)
+	}
+	wantHeaders := map[string]string{"user-agent": "Mozilla/5.0", "cookie": "a=1; b=2"}
+	if !reflect.DeepEqual(search.Request.Headers, wantHeaders) {
+		t.Errorf("Headers = %v, want %v", search.Request.Headers, wantHeaders)
+	}
+	if search.Expected.Blocked == nil || *search.Expected.Blocked {
+		t.Errorf("Expected.Blocked = %v, want false", search.Expected.Blocked)
+	}
+
+	cart := shop.Spec.Tests[1]
+	if cart.Request.Method != "POST" || cart.Request.Body != "item=42&qty=1" {
+		t.Errorf("cart request = %+v", cart.Request)
+	}
+	if cart.Request.Headers["Content-Type"] != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %q", cart.Request.Headers["Content-Type"])
+	}
+	if _, ok := cart.Request.Headers["Content-Length"]; ok {
+		t.Error("Content-Length should be dropped")
+	}
+
+	if shop.Spec.Tests[2].Name != "get-search-2" {
+		t.Errorf("duplicate request name = %q, want get-search-2", shop.Spec.Tests[2].Name)
+	}
+
+	if len(results[0].Unsupported) != 1 || !strings.Contains(results[0].Unsupported[0], "data:") {
+		t.Errorf("Unsupported = %v, want note about data: URL", results[0].Unsupported)
+	}
+
+	for _, result := range results {
+		data, err := Marshal(result.Suite)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		if _, err := parser.NewParser().ParseYAML(data); err != nil {
+			t.Errorf("converted suite does not parse: %v\n%s", err, data)
+		}
+	}
+}
+
+func TestImportHARRecordedStatus(t *testing.T) {
+	results, err := ImportHAR([]byte(testHAR), "capture.har", HAROptions{Host: "shop.example.com", UseRecordedStatus: true})
+	if err != nil {
+		t.Fatalf("ImportHAR() error = %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+
+	tests := results[0].Suite.Spec.Tests
+	if len(tests) != 2 {
+		t.Fatalf("got %d tests, want 2 (entry without status is skipped)", len(tests))
+	}
+	if !reflect.DeepEqual(tests[0].Expected.Status, []int{200}) || !reflect.DeepEqual(tests[1].Expected.Status, []int{302}) {
+		t.Errorf("statuses = %v, %v", tests[0].Expected.Status, tests[1].Expected.Status)
+	}
+	if len(results[0].Unsupported) != 2 {
+		t.Errorf("Unsupported = %v, want 2 notes", results[0].Unsupported)
+	}
+}
+
+func TestImportHARBaseURL(t *testing.T) {
+	results, err := ImportHAR([]byte(testHAR), "capture.har", HAROptions{BaseURL: "https://staging.example.com", Name: "checkout"})
+	if err != nil {
+		t.Fatalf("ImportHAR() error = %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want a single suite", len(results))
+	}
+	if results[0].Suite.Metadata.Name != "checkout" || len(results[0].Suite.Spec.Tests) != 4 {
+		t.Errorf("suite = %s with %d tests", results[0].Suite.Metadata.Name, len(results[0].Suite.Spec.Tests))
+	}
+}
+
+func TestImportHARInvalid(t *testing.T) {
+	if _, err := ImportHAR([]byte("{not json"), "bad.har", HAROptions{}); err == nil {
+		t.Error("ImportHAR() expected error for invalid JSON")
+	}
+
+	results, err := ImportHAR([]byte(`{"log":{"entries":[]}}`), "empty.har", HAROptions{})
+	if err != nil {
+		t.Fatalf("ImportHAR() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Suite != nil {
+		t.Errorf("empty capture should produce one result without a suite")
+	}
+}
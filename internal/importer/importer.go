@@ -0,0 +1,203 @@
+// Package importer converts third-party test and traffic formats into
+// SentinelTest suites.
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"wafguard/internal/core/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	APIVersion = "sentinel-test/v1"
+	Kind       = "SentinelTest"
+
+	// DefaultTimeout is the target timeout written into converted suites.
+	DefaultTimeout = 30 * time.Second
+)
+
+// Result is the outcome of converting one source document. Suite is nil
+// when nothing in the source could be converted; Unsupported lists the
+// parts that were skipped and why.
+type Result struct {
+	Source      string
+	Suite       *config.SentinelTest
+	Unsupported []string
+}
+
+func (r *Result) skip(format string, args ...interface{}) {
+	r.Unsupported = append(r.Unsupported, fmt.Sprintf(format, args...))
+}
+
+func newSuite(name, description, baseURL string) *config.SentinelTest {
+	return &config.SentinelTest{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Metadata: config.Metadata{
+			Name:        name,
+			Description: description,
+		},
+		Spec: config.Spec{
+			Target: config.Target{
+				BaseURL: baseURL,
+				Timeout: DefaultTimeout,
+			},
+		},
+	}
+}
+
+// Marshal renders a suite as SentinelTest YAML.
+func Marshal(suite *config.SentinelTest) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(suite); err != nil {
+		return nil, fmt.Errorf("failed to encode suite %s: %w", suite.Metadata.Name, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode suite %s: %w", suite.Metadata.Name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes suite to path as YAML, creating parent directories.
+func WriteFile(path string, suite *config.SentinelTest) error {
+	data, err := Marshal(suite)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// ParseExpectation builds the expectation applied to converted tests from
+// a command-line value: "blocked", "allowed", or a comma-separated list of
+// status codes.
+func ParseExpectation(value string) (config.Expected, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "blocked":
+		blocked := true
+		return config.Expected{Blocked: &blocked}, nil
+	case "allowed":
+		blocked := false
+		return config.Expected{Blocked: &blocked}, nil
+	}
+
+	var statuses []int
+	for _, field := range strings.Split(value, ",") {
+		status, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || status < 100 || status > 599 {
+			return config.Expected{}, fmt.Errorf("invalid expectation %q: use blocked, allowed or a list of status codes", value)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return config.Expected{Status: statuses}, nil
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// sanitizeName turns s into a lowercase, dash-separated identifier suitable
+// for metadata and test names.
+func sanitizeName(s string) string {
+	name := nonNameChars.ReplaceAllString(strings.ToLower(s), "-")
+	return strings.Trim(name, "-")
+}
+
+// uniqueName returns name, suffixed with a counter if it was already used.
+func uniqueName(name string, used map[string]int) string {
+	used[name]++
+	if used[name] == 1 {
+		return name
+	}
+	return fmt.Sprintf("%s-%d", name, used[name])
+}
+
+// testNameFor derives a readable test name from a method and request path,
+// ignoring the query string.
+func testNameFor(method, path string) string {
+	p, _, _ := strings.Cut(path, "?")
+	name := sanitizeName(method + " " + p)
+	if len(name) > 60 {
+		name = strings.Trim(name[:60], "-")
+	}
+	return name
+}
+
+var (
+	numericSegment = regexp.MustCompile(`^[0-9]+$`)
+	uuidSegment    = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	hexSegment     = regexp.MustCompile(`^(?i)[0-9a-f]{16,}$`)
+	tokenSegment   = regexp.MustCompile(`^[A-Za-z0-9_-]{24,}$`)
+)
+
+// PathShape reduces a request path to the endpoint it addresses: the query
+// string is dropped and segments that look like identifiers (numbers,
+// UUIDs, long hex strings or tokens) are replaced with {id}, so that
+// /users/42?tab=1 and /users/7 share the shape /users/{id}.
+func PathShape(path string) string {
+	p, _, _ := strings.Cut(path, "?")
+	p, _, _ = strings.Cut(p, "#")
+
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if numericSegment.MatchString(segment) || uuidSegment.MatchString(segment) ||
+			hexSegment.MatchString(segment) || tokenSegment.MatchString(segment) && strings.ContainsAny(segment, "0123456789") {
+			segments[i] = "{id}"
+		}
+	}
+
+	shape := strings.Join(segments, "/")
+	if shape == "" {
+		shape = "/"
+	}
+	return shape
+}
+
+// sourceName is the base name of a source file without its extension.
+func sourceName(source string) string {
+	return strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+}
+
+var supportedMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true,
+	"PATCH": true, "HEAD": true, "OPTIONS": true,
+}
+
+// isMethod reports whether method is one SentinelTest requests support.
+func isMethod(method string) bool {
+	return supportedMethods[method]
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	return headerKey(headers, name) != ""
+}
+
+// headerKey returns the key under which name is stored in headers,
+// compared case-insensitively, or "" if it is not present.
+func headerKey(headers map[string]string, name string) string {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"wafguard/internal/core/config"
+	"wafguard/internal/parser"
+)
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"920100-1", "920100-1"},
+		{"REQUEST-942-APPLICATION-ATTACK-SQLI", "request-942-application-attack-sqli"},
+		{"  Login form / POST  ", "login-form-post"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := sanitizeName(tt.input); got != tt.want {
+				t.Errorf("sanitizeName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	used := make(map[string]int)
+
+	names := []string{
+		uniqueName("test", used),
+		uniqueName("test", used),
+		uniqueName("other", used),
+		uniqueName("test", used),
+	}
+	want := []string{"test", "test-2", "other", "test-3"}
+
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("uniqueName() #%d = %q, want %q", i, names[i], want[i])
+		}
+	}
+}
+
+func TestPathShape(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "/"},
+		{"", "/"},
+		{"/users/42?tab=1", "/users/{id}"},
+		{"/users/42/orders/7", "/users/{id}/orders/{id}"},
+		{"/items/3f2504e0-4f89-11d3-9a0c-0305e82c3301", "/items/{id}"},
+		{"/blobs/0123456789abcdef0123", "/blobs/{id}"},
+		{"/reset/eyJhbGciOiJIUzI1NiJ9abc123xyz", "/reset/{id}"},
+		{"/static/app-v2.js", "/static/app-v2.js"},
+		{"/api/v1/search#top", "/api/v1/search"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := PathShape(tt.path); got != tt.want {
+				t.Errorf("PathShape(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	blocked := true
+	suite := newSuite("round-trip", "Round trip test", "https://example.com")
+	suite.Spec.Tests = []config.Test{
+		{
+			Name: "blocked-test",
+			Tags: []string{"942100"},
+			Request: config.Request{
+				Method: "POST",
+				Path:   "/login?user=' OR 1=1--",
+				Headers: map[string]string{
+					"Content-Type": "application/x-www-form-urlencoded",
+				},
+				Body: "a: b\r\nc",
+			},
+			Expected: config.Expected{
+				Blocked: &blocked,
+			},
+		},
+	}
+
+	data, err := Marshal(suite)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	parsed, err := parser.NewParser().ParseYAML(data)
+	if err != nil {
+		t.Fatalf("ParseYAML() error = %v\n%s", err, data)
+	}
+
+	got := parsed.Spec.Tests[0]
+	if got.Request.Path != suite.Spec.Tests[0].Request.Path {
+		t.Errorf("Path = %q, want %q", got.Request.Path, suite.Spec.Tests[0].Request.Path)
+	}
+	if got.Request.Body != suite.Spec.Tests[0].Request.Body {
+		t.Errorf("Body = %q, want %q", got.Request.Body, suite.Spec.Tests[0].Request.Body)
+	}
+	if got.Expected.Blocked == nil || !*got.Expected.Blocked {
+		t.Errorf("Blocked = %v, want true", got.Expected.Blocked)
+	}
+	if len(got.Tags) != 1 || got.Tags[0] != "942100" {
+		t.Errorf("Tags = %v, want [942100]", got.Tags)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "nested", "suite.yaml")
+
+	suite := newSuite("write-test", "", "https://example.com")
+	suite.Spec.Tests = []config.Test{
+		{
+			Name:     "test",
+			Request:  config.Request{Method: "GET", Path: "/"},
+			Expected: config.Expected{Status: []int{200}},
+		},
+	}
+
+	if err := WriteFile(path, suite); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected file to be written: %v", err)
+	}
+
+	if _, err := parser.NewParser().ParseFile(path); err != nil {
+		t.Errorf("ParseFile() error = %v", err)
+	}
+}
@@ -0,0 +1,711 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"wafguard/internal/core/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NucleiOptions controls how nuclei templates are converted.
+type NucleiOptions struct {
+	// BaseURL is the target for every request. Templates address the
+	// target through {{BaseURL}} and {{Hostname}}, so it is required.
+	BaseURL string
+	// Expected, when set, replaces the expectation derived from the
+	// template's matchers.
+	Expected *config.Expected
+}
+
+type nucleiTemplate struct {
+	ID   string `yaml:"id"`
+	Info struct {
+		Name string     `yaml:"name"`
+		Tags nucleiList `yaml:"tags"`
+	} `yaml:"info"`
+	Variables map[string]interface{} `yaml:"variables"`
+	HTTP      []nucleiRequest        `yaml:"http"`
+	// Requests is the name used for http before nuclei v3
+	Requests []nucleiRequest `yaml:"requests"`
+}
+
+type nucleiRequest struct {
+	Method            string                 `yaml:"method"`
+	Path              []string               `yaml:"path"`
+	Raw               []string               `yaml:"raw"`
+	Headers           map[string]string      `yaml:"headers"`
+	Body              string                 `yaml:"body"`
+	Payloads          map[string]interface{} `yaml:"payloads"`
+	Attack            string                 `yaml:"attack"`
+	MatchersCondition string                 `yaml:"matchers-condition"`
+	Matchers          []nucleiMatcher        `yaml:"matchers"`
+	Extractors        []interface{}          `yaml:"extractors"`
+	Fuzzing           []interface{}          `yaml:"fuzzing"`
+	ReqCondition      bool                   `yaml:"req-condition"`
+	CookieReuse       bool                   `yaml:"cookie-reuse"`
+	Race              bool                   `yaml:"race"`
+	Pipeline          bool                   `yaml:"pipeline"`
+	Unsafe            bool                   `yaml:"unsafe"`
+}
+
+type nucleiMatcher struct {
+	Type            string   `yaml:"type"`
+	Part            string   `yaml:"part"`
+	Condition       string   `yaml:"condition"`
+	Negative        bool     `yaml:"negative"`
+	Internal        bool     `yaml:"internal"`
+	CaseInsensitive bool     `yaml:"case-insensitive"`
+	Status          []int    `yaml:"status"`
+	Words           []string `yaml:"words"`
+	Regex           []string `yaml:"regex"`
+}
+
+// nucleiList is a list given either as a YAML sequence or as a
+// comma-separated string.
+type nucleiList []string
+
+func (l *nucleiList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		*l = items
+		return nil
+	}
+
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// nucleiProtocols are the template sections other than http.
+var nucleiProtocols = []string{
+	"code", "dns", "file", "flow", "headless", "javascript", "network",
+	"ssl", "tcp", "websocket", "whois", "workflows",
+}
+
+var nucleiPlaceholder = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// ImportNuclei converts the HTTP requests of a nuclei template into a
+// suite. Structured requests produce one test per path and raw requests
+// one test per request; inline payload lists are expanded with the
+// template's attack type. Matchers on status, body words and body regexes
+// become the expectation, and a template without a status matcher expects
+// the request not to be blocked, since its matchers only see responses
+// from the application. Everything else the template uses (other
+// protocols, DSL expressions, extractors, payload files, ...) is listed in
+// Result.Unsupported; requests depending on it are skipped.
+func ImportNuclei(data []byte, source string, opts NucleiOptions) (*Result, error) {
+	if opts.BaseURL == "" {
+		return nil, fmt.Errorf("nuclei templates do not name a target, a base URL is required")
+	}
+	base, err := url.Parse(opts.BaseURL)
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", opts.BaseURL)
+	}
+
+	var template nucleiTemplate
+	if err := yaml.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to parse nuclei template %s: %w", source, err)
+	}
+	var sections map[string]interface{}
+	if err := yaml.Unmarshal(data, &sections); err != nil {
+		return nil, fmt.Errorf("failed to parse nuclei template %s: %w", source, err)
+	}
+
+	result := &Result{Source: source}
+	noted := make(map[string]bool)
+	note := func(format string, args ...interface{}) {
+		if msg := fmt.Sprintf(format, args...); !noted[msg] {
+			noted[msg] = true
+			result.Unsupported = append(result.Unsupported, msg)
+		}
+	}
+
+	if template.ID == "" {
+		note("not a nuclei template (no id)")
+		return result, nil
+	}
+	for _, protocol := range nucleiProtocols {
+		if _, ok := sections[protocol]; ok {
+			note("%s: the %s protocol is not supported", template.ID, protocol)
+		}
+	}
+
+	vars := nucleiBaseVars(base)
+	for name, value := range template.Variables {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		// Variables may refer to the built-in ones, but not to helpers
+		if resolved, unknown := nucleiResolve(s, vars); len(unknown) == 0 {
+			vars[name] = resolved
+		}
+	}
+
+	requests := append(template.HTTP, template.Requests...)
+	tags := append([]string{template.ID}, template.Info.Tags...)
+	used := make(map[string]int)
+	var tests []config.Test
+
+	for i, request := range requests {
+		label := fmt.Sprintf("%s: request %d", template.ID, i+1)
+		reqNote := func(format string, args ...interface{}) {
+			note("%s: %s", label, fmt.Sprintf(format, args...))
+		}
+
+		if !nucleiSupported(request, reqNote) {
+			continue
+		}
+
+		combinations, err := nucleiCombinations(request.Payloads, request.Attack)
+		if err != nil {
+			reqNote("%v", err)
+			continue
+		}
+
+		for _, payloads := range combinations {
+			values := make(map[string]string, len(vars)+len(payloads))
+			for name, value := range vars {
+				values[name] = value
+			}
+			for name, value := range payloads {
+				values[name] = value
+			}
+
+			converted := nucleiTests(request, values, reqNote)
+
+			var expected config.Expected
+			if opts.Expected != nil {
+				expected = *opts.Expected
+			} else {
+				expected = nucleiExpected(request, values, reqNote)
+			}
+
+			for _, test := range converted {
+				test.Name = uniqueName(sanitizeName(template.ID), used)
+				test.Tags = tags
+				test.Expected = expected
+				tests = append(tests, test)
+			}
+		}
+	}
+
+	if len(tests) == 0 {
+		return result, nil
+	}
+
+	description := template.Info.Name
+	if description == "" {
+		description = fmt.Sprintf("Imported from nuclei template %s", filepath.Base(source))
+	}
+	result.Suite = newSuite(sanitizeName(template.ID), description, opts.BaseURL)
+	result.Suite.Spec.Tests = tests
+
+	return result, nil
+}
+
+// ImportNucleiDir converts every template under dir. Files that cannot be
+// parsed are reported in their Result instead of aborting the import.
+func ImportNucleiDir(dir string, opts NucleiOptions) ([]*Result, error) {
+	if opts.BaseURL == "" {
+		return nil, fmt.Errorf("nuclei templates do not name a target, a base URL is required")
+	}
+
+	var results []*Result
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || (filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", path, err)
+		}
+
+		result, err := ImportNuclei(data, path, opts)
+		if err != nil {
+			result = &Result{Source: path}
+			result.skip("%v", err)
+		}
+		results = append(results, result)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to import directory %s: %w", dir, err)
+	}
+
+	return results, nil
+}
+
+// nucleiBaseVars are the built-in variables nuclei derives from the target.
+func nucleiBaseVars(base *url.URL) map[string]string {
+	port := base.Port()
+	if port == "" {
+		port = "80"
+		if base.Scheme == "https" {
+			port = "443"
+		}
+	}
+	root := (&url.URL{Scheme: base.Scheme, Host: base.Host}).String()
+
+	return map[string]string{
+		"BaseURL":  strings.TrimSuffix(base.String(), "/"),
+		"RootURL":  root,
+		"Hostname": base.Host,
+		"Host":     base.Hostname(),
+		"Port":     port,
+		"Scheme":   base.Scheme,
+	}
+}
+
+// nucleiSupported reports whether a request can be converted, noting the
+// features that are dropped or prevent the conversion.
+func nucleiSupported(request nucleiRequest, note func(string, ...interface{})) bool {
+	if len(request.Extractors) > 0 {
+		note("extractors are ignored")
+	}
+	if request.Unsafe {
+		note("unsafe raw requests are sent through a regular HTTP client")
+	}
+	if request.Race || request.Pipeline {
+		note("race and pipeline modes are ignored")
+	}
+
+	switch {
+	case len(request.Fuzzing) > 0:
+		note("fuzzing rules are not supported")
+	case request.ReqCondition || request.CookieReuse:
+		note("requests depending on earlier responses are not supported")
+	case len(request.Path) == 0 && len(request.Raw) == 0:
+		note("no path or raw request")
+	default:
+		return true
+	}
+	return false
+}
+
+// nucleiCombinations expands inline payload lists according to the attack
+// type. Without payloads it returns a single empty combination.
+func nucleiCombinations(payloads map[string]interface{}, attack string) ([]map[string]string, error) {
+	if len(payloads) == 0 {
+		return []map[string]string{{}}, nil
+	}
+
+	names := make([]string, 0, len(payloads))
+	lists := make(map[string][]string)
+	for name, value := range payloads {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("payload %s: payload files are not supported", name)
+		}
+		for _, item := range items {
+			lists[name] = append(lists[name], fmt.Sprint(item))
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if attack == "" {
+		attack = "batteringram"
+		if len(names) > 1 {
+			attack = "clusterbomb"
+		}
+	}
+
+	var combinations []map[string]string
+	switch strings.ToLower(attack) {
+	case "batteringram", "sniper":
+		if len(names) > 1 {
+			return nil, fmt.Errorf("%s attack with %d payload sets", attack, len(names))
+		}
+		for _, value := range lists[names[0]] {
+			combinations = append(combinations, map[string]string{names[0]: value})
+		}
+	case "pitchfork":
+		n := len(lists[names[0]])
+		for _, name := range names {
+			if len(lists[name]) != n {
+				return nil, fmt.Errorf("pitchfork payload sets differ in length")
+			}
+		}
+		for i := 0; i < n; i++ {
+			combination := make(map[string]string, len(names))
+			for _, name := range names {
+				combination[name] = lists[name][i]
+			}
+			combinations = append(combinations, combination)
+		}
+	case "clusterbomb":
+		combinations = []map[string]string{{}}
+		for _, name := range names {
+			var next []map[string]string
+			for _, combination := range combinations {
+				for _, value := range lists[name] {
+					extended := make(map[string]string, len(combination)+1)
+					for k, v := range combination {
+						extended[k] = v
+					}
+					extended[name] = value
+					next = append(next, extended)
+				}
+			}
+			combinations = next
+		}
+	default:
+		return nil, fmt.Errorf("unknown attack type %q", attack)
+	}
+
+	return combinations, nil
+}
+
+// nucleiTests builds the tests for one payload combination of a request.
+// Paths and raw requests that cannot be converted are noted and skipped.
+func nucleiTests(request nucleiRequest, vars map[string]string, note func(string, ...interface{})) []config.Test {
+	var tests []config.Test
+
+	for _, path := range request.Path {
+		test, err := nucleiPathTest(request, path, vars)
+		if err != nil {
+			note("%v", err)
+			continue
+		}
+		tests = append(tests, test)
+	}
+
+	for _, raw := range request.Raw {
+		resolved, err := nucleiResolveAll(raw, vars)
+		if err != nil {
+			note("%v", err)
+			continue
+		}
+		test, err := nucleiRawTest(resolved, vars, note)
+		if err != nil {
+			note("%v", err)
+			continue
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+// nucleiPathTest builds the test for one path of a structured request.
+func nucleiPathTest(request nucleiRequest, path string, vars map[string]string) (config.Test, error) {
+	method := strings.ToUpper(request.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	target, err := nucleiResolveAll(path, vars)
+	if err != nil {
+		return config.Test{}, err
+	}
+	body, err := nucleiResolveAll(request.Body, vars)
+	if err != nil {
+		return config.Test{}, err
+	}
+
+	var headers map[string]string
+	for name, value := range request.Headers {
+		if harDroppedHeaders[strings.ToLower(name)] {
+			continue
+		}
+		resolved, err := nucleiResolveAll(value, vars)
+		if err != nil {
+			return config.Test{}, err
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = resolved
+	}
+
+	return nucleiTest(method, target, headers, body, vars)
+}
+
+// nucleiRawTest parses a raw HTTP request whose placeholders are resolved.
+func nucleiRawTest(raw string, vars map[string]string, note func(string, ...interface{})) (config.Test, error) {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(raw, "\n"), "\n")
+
+	// Skip blank lines and @annotations before the request line
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "@") {
+			note("annotation %s is ignored", line)
+			continue
+		}
+		if line != "" {
+			break
+		}
+	}
+	if i == len(lines) {
+		return config.Test{}, fmt.Errorf("empty raw request")
+	}
+
+	parts := strings.Fields(lines[i])
+	if len(parts) < 2 || len(parts) > 3 {
+		return config.Test{}, fmt.Errorf("malformed request line %q", lines[i])
+	}
+
+	var headers map[string]string
+	for i++; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		name, value, ok := strings.Cut(lines[i], ":")
+		if !ok {
+			return config.Test{}, fmt.Errorf("malformed header %q", lines[i])
+		}
+		name = strings.TrimSpace(name)
+		if harDroppedHeaders[strings.ToLower(name)] {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = strings.TrimSpace(value)
+	}
+
+	body := ""
+	if i < len(lines) {
+		body = strings.Join(lines[i+1:], "\n")
+	}
+
+	return nucleiTest(parts[0], parts[1], headers, body, vars)
+}
+
+// nucleiTest turns a resolved request into a test, reducing the target to
+// a path on the base URL.
+func nucleiTest(method, target string, headers map[string]string, body string, vars map[string]string) (config.Test, error) {
+	if !isMethod(method) {
+		return config.Test{}, fmt.Errorf("unsupported method %q", method)
+	}
+
+	path := target
+	for _, prefix := range []string{vars["BaseURL"], vars["RootURL"]} {
+		if rest, ok := strings.CutPrefix(target, prefix); ok {
+			path = rest
+			break
+		}
+	}
+	switch {
+	case path == "":
+		path = "/"
+	case strings.HasPrefix(path, "?"):
+		path = "/" + path
+	case !strings.HasPrefix(path, "/"):
+		return config.Test{}, fmt.Errorf("request target %q is not on the target", target)
+	}
+
+	return config.Test{
+		Request: config.Request{
+			Method:  method,
+			Path:    escapeLoggedPath(path),
+			Headers: headers,
+			Body:    body,
+		},
+	}, nil
+}
+
+// nucleiResolve replaces {{name}} placeholders with vars in a single pass,
+// so payloads that contain braces are left alone, and returns the names it
+// could not resolve.
+func nucleiResolve(s string, vars map[string]string) (string, []string) {
+	var unknown []string
+	resolved := nucleiPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		name := strings.TrimSpace(m[2 : len(m)-2])
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		unknown = append(unknown, m)
+		return m
+	})
+	return resolved, unknown
+}
+
+func nucleiResolveAll(s string, vars map[string]string) (string, error) {
+	resolved, unknown := nucleiResolve(s, vars)
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("nuclei expression %s is not supported", unknown[0])
+	}
+	return resolved, nil
+}
+
+// nucleiExpected maps the matchers of a request onto an expectation.
+// Matchers that cannot be expressed are noted and left out; with the or
+// condition only the first convertible matcher is kept, unless they all
+// match statuses.
+func nucleiExpected(request nucleiRequest, vars map[string]string, note func(string, ...interface{})) config.Expected {
+	and := strings.EqualFold(request.MatchersCondition, "and")
+
+	var parts []config.Expected
+	for _, matcher := range request.Matchers {
+		if matcher.Internal {
+			continue
+		}
+		part, err := nucleiMatcherExpected(matcher, vars)
+		if err != nil {
+			note("%v", err)
+			continue
+		}
+		parts = append(parts, part)
+	}
+
+	var expected config.Expected
+	for i, part := range parts {
+		if i > 0 && !and {
+			if len(part.Status) > 0 && part.Body == nil && len(expected.Status) > 0 && expected.Body == nil {
+				expected.Status = append(expected.Status, part.Status...)
+				continue
+			}
+			note("matchers-condition or: only the first matcher is asserted")
+			break
+		}
+		if err := mergeExpected(&expected, part); err != nil {
+			note("%v", err)
+		}
+	}
+
+	if len(expected.Status) == 0 {
+		allowed := false
+		expected.Blocked = &allowed
+	}
+	return expected
+}
+
+// nucleiMatcherExpected converts a single matcher.
+func nucleiMatcherExpected(matcher nucleiMatcher, vars map[string]string) (config.Expected, error) {
+	kind := strings.ToLower(matcher.Type)
+	part := strings.ToLower(matcher.Part)
+	if part == "" {
+		part = "body"
+	}
+	and := strings.EqualFold(matcher.Condition, "and")
+
+	switch kind {
+	case "status":
+		if matcher.Negative {
+			return config.Expected{}, fmt.Errorf("negative status matchers are not supported")
+		}
+		if len(matcher.Status) == 0 {
+			return config.Expected{}, fmt.Errorf("status matcher without statuses")
+		}
+		return config.Expected{Status: matcher.Status}, nil
+	case "word", "regex":
+		if part != "body" {
+			return config.Expected{}, fmt.Errorf("%s matchers on the %s part are not supported", kind, part)
+		}
+	default:
+		return config.Expected{}, fmt.Errorf("%s matchers are not supported", kind)
+	}
+
+	body := &config.BodyExpected{}
+	if kind == "word" {
+		if len(matcher.Words) == 0 {
+			return config.Expected{}, fmt.Errorf("word matcher without words")
+		}
+		words := make([]string, len(matcher.Words))
+		for i, word := range matcher.Words {
+			resolved, err := nucleiResolveAll(word, vars)
+			if err != nil {
+				return config.Expected{}, err
+			}
+			words[i] = resolved
+		}
+
+		switch {
+		case matcher.Negative && matcher.CaseInsensitive:
+			return config.Expected{}, fmt.Errorf("negative case-insensitive word matchers are not supported")
+		case matcher.Negative && and && len(words) > 1:
+			return config.Expected{}, fmt.Errorf("negative word matchers with condition and are not supported")
+		case matcher.Negative:
+			body.NotContains = words
+		case matcher.CaseInsensitive && and && len(words) > 1:
+			return config.Expected{}, fmt.Errorf("case-insensitive word matchers with condition and are not supported")
+		case matcher.CaseInsensitive || (!and && len(words) > 1):
+			quoted := make([]string, len(words))
+			for i, word := range words {
+				quoted[i] = regexp.QuoteMeta(word)
+			}
+			body.Regex = strings.Join(quoted, "|")
+			if matcher.CaseInsensitive {
+				body.Regex = "(?i)" + body.Regex
+			}
+		default:
+			body.Contains = words
+		}
+		return config.Expected{Body: body}, nil
+	}
+
+	switch {
+	case len(matcher.Regex) == 0:
+		return config.Expected{}, fmt.Errorf("regex matcher without patterns")
+	case matcher.Negative:
+		return config.Expected{}, fmt.Errorf("negative regex matchers are not supported")
+	case and && len(matcher.Regex) > 1:
+		return config.Expected{}, fmt.Errorf("regex matchers with condition and are not supported")
+	}
+
+	patterns := make([]string, len(matcher.Regex))
+	for i, pattern := range matcher.Regex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return config.Expected{}, fmt.Errorf("invalid regex %q: %v", pattern, err)
+		}
+		patterns[i] = "(?:" + pattern + ")"
+	}
+	body.Regex = strings.Join(patterns, "|")
+	if len(patterns) == 1 {
+		body.Regex = matcher.Regex[0]
+	}
+	if matcher.CaseInsensitive {
+		body.Regex = "(?i)" + body.Regex
+	}
+	return config.Expected{Body: body}, nil
+}
+
+// mergeExpected adds the checks of src to dst for the and condition.
+func mergeExpected(dst *config.Expected, src config.Expected) error {
+	if len(src.Status) > 0 {
+		if len(dst.Status) > 0 {
+			return fmt.Errorf("several status matchers with condition and are not supported")
+		}
+		dst.Status = src.Status
+	}
+	if src.Body == nil {
+		return nil
+	}
+
+	if dst.Body == nil {
+		dst.Body = &config.BodyExpected{}
+	}
+	if src.Body.Regex != "" {
+		if dst.Body.Regex != "" {
+			return fmt.Errorf("several regex matchers with condition and are not supported")
+		}
+		dst.Body.Regex = src.Body.Regex
+	}
+	dst.Body.Contains = append(dst.Body.Contains, src.Body.Contains...)
+	dst.Body.NotContains = append(dst.Body.NotContains, src.Body.NotContains...)
+	return nil
+}
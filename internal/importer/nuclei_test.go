@@ -0,0 +1,400 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"wafguard/internal/core/config"
+	"wafguard/internal/parser"
+)
+
+const structuredNuclei = `
+id: sqli-error-based
+info:
+  name: Error based SQL injection
+  severity: high
+  tags: sqli,generic
+http:
+  - method: GET
+    path:
+      - "{{BaseURL}}/search?q={{payload}}"
+      - "{{RootURL}}/items?id={{payload}}"
+    headers:
+      Referer: "{{BaseURL}}"
+    payloads:
+      payload:
+        - "1'"
+        - "1 OR 1=1"
+    matchers-condition: and
+    matchers:
+      - type: status
+        status: [500]
+      - type: word
+        words: ["SQL syntax", "mysql"]
+        condition: or
+        case-insensitive: true
+      - type: word
+        part: header
+        words: ["text/html"]
+    extractors:
+      - type: regex
+        regex: ["version ([0-9.]+)"]
+`
+
+const rawNuclei = `
+id: ssti-login
+info:
+  name: SSTI in login form
+requests:
+  - raw:
+      - |
+        POST /login HTTP/1.1
+        Host: {{Hostname}}
+        Content-Type: application/x-www-form-urlencoded
+        Content-Length: 18
+
+        user={{7*7}}&pw=x
+      - |
+        @timeout: 10s
+        POST /login HTTP/1.1
+        Host: {{Hostname}}
+        Content-Type: application/x-www-form-urlencoded
+
+        user={{ssti}}&pw=x
+    payloads:
+      ssti:
+        - "{{7*7}}"
+    matchers:
+      - type: word
+        words: ["49"]
+      - type: dsl
+        dsl: ["len(body) > 10"]
+`
+
+func TestImportNucleiStructured(t *testing.T) {
+	result, err := ImportNuclei([]byte(structuredNuclei), "sqli.yaml", NucleiOptions{BaseURL: "https://target.example.com"})
+	if err != nil {
+		t.Fatalf("ImportNuclei() error = %v", err)
+	}
+
+	if result.Suite == nil {
+		t.Fatalf("expected a suite, unsupported: %v", result.Unsupported)
+	}
+	if result.Suite.Metadata.Name != "sqli-error-based" || result.Suite.Metadata.Description != "Error based SQL injection" {
+		t.Errorf("unexpected metadata: %+v", result.Suite.Metadata)
+	}
+
+	tests := result.Suite.Spec.Tests
+	var paths []string
+	for _, test := range tests {
+		paths = append(paths, test.Request.Path)
+	}
+	wantPaths := []string{"/search?q=1'", "/items?id=1'", "/search?q=1%20OR%201=1", "/items?id=1%20OR%201=1"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("paths = %v, want %v", paths, wantPaths)
+	}
+
+	first := tests[0]
+	if first.Name != "sqli-error-based" || tests[1].Name != "sqli-error-based-2" {
+		t.Errorf("unexpected names %q, %q", first.Name, tests[1].Name)
+	}
+	if !reflect.DeepEqual(first.Tags, []string{"sqli-error-based", "sqli", "generic"}) {
+		t.Errorf("Tags = %v", first.Tags)
+	}
+	if first.Request.Headers["Referer"] != "https://target.example.com" {
+		t.Errorf("Referer = %q", first.Request.Headers["Referer"])
+	}
+
+	wantExpected := config.Expected{
+		Status: []int{500},
+		Body:   &config.BodyExpected{Regex: "(?i)SQL syntax|mysql"},
+	}
+	if !reflect.DeepEqual(first.Expected, wantExpected) {
+		t.Errorf("Expected = %+v, want %+v", first.Expected, wantExpected)
+	}
+
+	notes := strings.Join(result.Unsupported, "\n")
+	for _, want := range []string{"extractors are ignored", "word matchers on the header part are not supported"} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("Unsupported = %v, want a note containing %q", result.Unsupported, want)
+		}
+	}
+	if len(result.Unsupported) != 2 {
+		t.Errorf("expected notes once per template, got %v", result.Unsupported)
+	}
+
+	data, err := Marshal(result.Suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.NewParser().ParseYAML(data); err != nil {
+		t.Errorf("converted suite is invalid: %v", err)
+	}
+}
+
+func TestImportNucleiRaw(t *testing.T) {
+	result, err := ImportNuclei([]byte(rawNuclei), "ssti.yaml", NucleiOptions{BaseURL: "http://localhost:8080"})
+	if err != nil {
+		t.Fatalf("ImportNuclei() error = %v", err)
+	}
+
+	// The first raw request uses a DSL expression, the second gets the
+	// payload without evaluating it
+	if result.Suite == nil || len(result.Suite.Spec.Tests) != 1 {
+		t.Fatalf("expected one test, got %+v (unsupported: %v)", result.Suite, result.Unsupported)
+	}
+	test := result.Suite.Spec.Tests[0]
+	if test.Request.Body != "user={{7*7}}&pw=x" {
+		t.Errorf("Body = %q, want the payload as is", test.Request.Body)
+	}
+	if test.Expected.Body == nil || !reflect.DeepEqual(test.Expected.Body.Contains, []string{"49"}) {
+		t.Errorf("Expected = %+v", test.Expected)
+	}
+
+	notes := strings.Join(result.Unsupported, "\n")
+	for _, want := range []string{"annotation @timeout: 10s", "nuclei expression {{7*7}}", "dsl matchers are not supported"} {
+		if !strings.Contains(notes, want) {
+			t.Errorf("Unsupported = %v, want a note containing %q", result.Unsupported, want)
+		}
+	}
+}
+
+func TestImportNucleiRawRequest(t *testing.T) {
+	template := `
+id: raw-post
+info:
+  name: Raw POST
+http:
+  - raw:
+      - |
+        POST /login?next=/ HTTP/1.1
+        Host: {{Hostname}}
+        Content-Type: application/x-www-form-urlencoded
+        Content-Length: 18
+
+        user=admin'--&pw=x
+    matchers:
+      - type: status
+        status: [200]
+      - type: status
+        status: [302]
+`
+	result, err := ImportNuclei([]byte(template), "raw.yaml", NucleiOptions{BaseURL: "http://localhost:8080"})
+	if err != nil {
+		t.Fatalf("ImportNuclei() error = %v", err)
+	}
+	if result.Suite == nil || len(result.Suite.Spec.Tests) != 1 {
+		t.Fatalf("expected one test, got %+v (unsupported: %v)", result.Suite, result.Unsupported)
+	}
+
+	test := result.Suite.Spec.Tests[0]
+	wantRequest := config.Request{
+		Method:  "POST",
+		Path:    "/login?next=/",
+		Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+		Body:    "user=admin'--&pw=x",
+	}
+	if !reflect.DeepEqual(test.Request, wantRequest) {
+		t.Errorf("Request = %+v, want %+v", test.Request, wantRequest)
+	}
+	// Status matchers combined with or become a list of statuses
+	if !reflect.DeepEqual(test.Expected.Status, []int{200, 302}) || test.Expected.Blocked != nil {
+		t.Errorf("Expected = %+v", test.Expected)
+	}
+	if len(result.Unsupported) != 0 {
+		t.Errorf("unexpected notes: %v", result.Unsupported)
+	}
+}
+
+func TestImportNucleiUnsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "other protocol",
+			template: "id: dns-check\ninfo:\n  name: DNS\ndns:\n  - name: \"{{FQDN}}\"\n    type: A\n",
+			want:     "the dns protocol is not supported",
+		},
+		{
+			name:     "payload file",
+			template: "id: lfi\nhttp:\n  - path: [\"{{BaseURL}}/?f={{file}}\"]\n    payloads:\n      file: payloads/lfi.txt\n",
+			want:     "payload files are not supported",
+		},
+		{
+			name:     "interactsh",
+			template: "id: ssrf\nhttp:\n  - path: [\"{{BaseURL}}/?u=http://{{interactsh-url}}\"]\n",
+			want:     "nuclei expression {{interactsh-url}} is not supported",
+		},
+		{
+			name:     "not a template",
+			template: "apiVersion: sentinel-test/v1\nkind: SentinelTest\n",
+			want:     "not a nuclei template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ImportNuclei([]byte(tt.template), "t.yaml", NucleiOptions{BaseURL: "https://target.example.com"})
+			if err != nil {
+				t.Fatalf("ImportNuclei() error = %v", err)
+			}
+			if result.Suite != nil {
+				t.Errorf("expected no suite, got %+v", result.Suite.Spec.Tests)
+			}
+			if !strings.Contains(strings.Join(result.Unsupported, "\n"), tt.want) {
+				t.Errorf("Unsupported = %v, want a note containing %q", result.Unsupported, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportNucleiExpectedOverride(t *testing.T) {
+	blocked := true
+	result, err := ImportNuclei([]byte(structuredNuclei), "sqli.yaml", NucleiOptions{
+		BaseURL:  "https://target.example.com",
+		Expected: &config.Expected{Blocked: &blocked},
+	})
+	if err != nil {
+		t.Fatalf("ImportNuclei() error = %v", err)
+	}
+
+	for _, test := range result.Suite.Spec.Tests {
+		if test.Expected.Blocked == nil || !*test.Expected.Blocked || test.Expected.Body != nil {
+			t.Errorf("%s: Expected = %+v, want blocked", test.Name, test.Expected)
+		}
+	}
+	// Matchers are not converted, so they cannot be unsupported
+	for _, note := range result.Unsupported {
+		if strings.Contains(note, "matcher") {
+			t.Errorf("unexpected matcher note %q", note)
+		}
+	}
+}
+
+func TestNucleiMatcherExpected(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher nucleiMatcher
+		want    config.Expected
+		wantErr bool
+	}{
+		{
+			name:    "words and",
+			matcher: nucleiMatcher{Type: "word", Words: []string{"root:", "bin:"}, Condition: "and"},
+			want:    config.Expected{Body: &config.BodyExpected{Contains: []string{"root:", "bin:"}}},
+		},
+		{
+			name:    "negative words",
+			matcher: nucleiMatcher{Type: "word", Words: []string{"error", "denied"}, Negative: true},
+			want:    config.Expected{Body: &config.BodyExpected{NotContains: []string{"error", "denied"}}},
+		},
+		{
+			name:    "words with variables",
+			matcher: nucleiMatcher{Type: "word", Words: []string{"{{Host}}"}},
+			want:    config.Expected{Body: &config.BodyExpected{Contains: []string{"target.example.com"}}},
+		},
+		{
+			name:    "regex or",
+			matcher: nucleiMatcher{Type: "regex", Regex: []string{`uid=\d+`, `gid=\d+`}},
+			want:    config.Expected{Body: &config.BodyExpected{Regex: `(?:uid=\d+)|(?:gid=\d+)`}},
+		},
+		{
+			name:    "negative status",
+			matcher: nucleiMatcher{Type: "status", Status: []int{404}, Negative: true},
+			wantErr: true,
+		},
+		{
+			name:    "regex and",
+			matcher: nucleiMatcher{Type: "regex", Regex: []string{"a", "b"}, Condition: "and"},
+			wantErr: true,
+		},
+		{
+			name:    "size",
+			matcher: nucleiMatcher{Type: "size"},
+			wantErr: true,
+		},
+	}
+
+	vars := map[string]string{"Host": "target.example.com"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nucleiMatcherExpected(tt.matcher, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nucleiMatcherExpected() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nucleiMatcherExpected() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNucleiCombinations(t *testing.T) {
+	payloads := map[string]interface{}{
+		"user": []interface{}{"admin", "root"},
+		"pass": []interface{}{"x", 1},
+	}
+
+	clusterbomb, err := nucleiCombinations(payloads, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusterbomb) != 4 {
+		t.Errorf("clusterbomb produced %d combinations, want 4", len(clusterbomb))
+	}
+
+	pitchfork, err := nucleiCombinations(payloads, "pitchfork")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{{"user": "admin", "pass": "x"}, {"user": "root", "pass": "1"}}
+	if !reflect.DeepEqual(pitchfork, want) {
+		t.Errorf("pitchfork = %v, want %v", pitchfork, want)
+	}
+
+	if _, err := nucleiCombinations(payloads, "batteringram"); err == nil {
+		t.Error("expected an error for batteringram with two payload sets")
+	}
+}
+
+func TestImportNucleiDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sqli"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sqli", "error.yaml"), []byte(structuredNuclei), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("id: [unclosed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ImportNucleiDir(dir, NucleiOptions{BaseURL: "https://target.example.com"})
+	if err != nil {
+		t.Fatalf("ImportNucleiDir() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	for _, result := range results {
+		switch filepath.Base(result.Source) {
+		case "broken.yaml":
+			if result.Suite != nil || len(result.Unsupported) != 1 {
+				t.Errorf("broken template: %+v", result)
+			}
+		case "error.yaml":
+			if result.Suite == nil {
+				t.Errorf("expected a suite for %s", result.Source)
+			}
+		}
+	}
+
+	if _, err := ImportNucleiDir(dir, NucleiOptions{}); err == nil {
+		t.Error("expected an error without a base URL")
+	}
+}
@@ -0,0 +1,665 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"wafguard/internal/core/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIOptions controls test generation from an OpenAPI document.
+type OpenAPIOptions struct {
+	// BaseURL overrides the first server listed in the document.
+	BaseURL string
+	// Expected is applied to every generated test.
+	Expected config.Expected
+}
+
+type oaDocument struct {
+	OpenAPI string `yaml:"openapi"`
+	Info    struct {
+		Title string `yaml:"title"`
+	} `yaml:"info"`
+	Servers    []oaServer             `yaml:"servers"`
+	Paths      map[string]*oaPathItem `yaml:"paths"`
+	Components struct {
+		Schemas       map[string]*oaSchema      `yaml:"schemas"`
+		Parameters    map[string]*oaParameter   `yaml:"parameters"`
+		RequestBodies map[string]*oaRequestBody `yaml:"requestBodies"`
+	} `yaml:"components"`
+}
+
+type oaServer struct {
+	URL       string `yaml:"url"`
+	Variables map[string]struct {
+		Default string `yaml:"default"`
+	} `yaml:"variables"`
+}
+
+type oaPathItem struct {
+	Parameters []*oaParameter `yaml:"parameters"`
+	Get        *oaOperation   `yaml:"get"`
+	Put        *oaOperation   `yaml:"put"`
+	Post       *oaOperation   `yaml:"post"`
+	Delete     *oaOperation   `yaml:"delete"`
+	Patch      *oaOperation   `yaml:"patch"`
+	Head       *oaOperation   `yaml:"head"`
+	Options    *oaOperation   `yaml:"options"`
+}
+
+type oaOperation struct {
+	OperationID string         `yaml:"operationId"`
+	Parameters  []*oaParameter `yaml:"parameters"`
+	RequestBody *oaRequestBody `yaml:"requestBody"`
+}
+
+type oaParameter struct {
+	Ref     string      `yaml:"$ref"`
+	Name    string      `yaml:"name"`
+	In      string      `yaml:"in"`
+	Schema  *oaSchema   `yaml:"schema"`
+	Example interface{} `yaml:"example"`
+}
+
+type oaRequestBody struct {
+	Ref     string                 `yaml:"$ref"`
+	Content map[string]oaMediaType `yaml:"content"`
+}
+
+type oaMediaType struct {
+	Schema  *oaSchema   `yaml:"schema"`
+	Example interface{} `yaml:"example"`
+}
+
+type oaSchema struct {
+	Ref        string               `yaml:"$ref"`
+	Type       oaType               `yaml:"type"`
+	Format     string               `yaml:"format"`
+	Enum       []interface{}        `yaml:"enum"`
+	Default    interface{}          `yaml:"default"`
+	Example    interface{}          `yaml:"example"`
+	Properties map[string]*oaSchema `yaml:"properties"`
+	Items      *oaSchema            `yaml:"items"`
+	AllOf      []*oaSchema          `yaml:"allOf"`
+	OneOf      []*oaSchema          `yaml:"oneOf"`
+	AnyOf      []*oaSchema          `yaml:"anyOf"`
+}
+
+// oaType accepts both the OpenAPI 3.0 string form and the 3.1 list form
+// of a schema type, keeping the first non-null entry.
+type oaType string
+
+func (t *oaType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		var types []string
+		if err := node.Decode(&types); err != nil {
+			return err
+		}
+		for _, typ := range types {
+			if typ != "null" {
+				*t = oaType(typ)
+				return nil
+			}
+		}
+		return nil
+	}
+
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	*t = oaType(s)
+	return nil
+}
+
+// ignoredHeaderParams are described by other parts of an OpenAPI document
+// and ignored when listed as parameters, as required by the specification.
+var ignoredHeaderParams = map[string]bool{
+	"accept":        true,
+	"content-type":  true,
+	"authorization": true,
+}
+
+// maxSchemaDepth bounds $ref expansion for recursive schemas.
+const maxSchemaDepth = 8
+
+// injectionPoint is one place a payload can be put into an operation's
+// request.
+type injectionPoint struct {
+	location string   // path, query, header, cookie or json
+	name     string   // parameter name, or JSON path for bodies
+	jsonPath jsonPath // set for json
+}
+
+func (p injectionPoint) tag() string {
+	return p.location + ":" + p.name
+}
+
+// openAPIOperation is an operation with its parameters resolved to
+// baseline values.
+type openAPIOperation struct {
+	method   string
+	path     string
+	params   []openAPIParam
+	jsonBody interface{}
+}
+
+type openAPIParam struct {
+	in       string
+	name     string
+	baseline string
+}
+
+// GenerateOpenAPI builds injection tests from an OpenAPI 3 document: every
+// payload is placed into every path, query, header and cookie parameter and
+// every string field of JSON request bodies, with baseline values from the
+// document everywhere else. One suite is produced per API path.
+func GenerateOpenAPI(data []byte, source string, payloads []string, opts OpenAPIOptions) ([]*Result, error) {
+	var doc oaDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document %s: %w", source, err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%s is not an OpenAPI 3 document (openapi: %q)", source, doc.OpenAPI)
+	}
+	if len(payloads) == 0 {
+		return nil, fmt.Errorf("no payloads given")
+	}
+
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		var err error
+		if baseURL, err = doc.serverURL(); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var results []*Result
+	for _, path := range paths {
+		result := &Result{Source: source}
+		used := make(map[string]int)
+		var tests []config.Test
+
+		for _, op := range doc.operations(path, result) {
+			points := op.injectionPoints()
+			if len(points) == 0 {
+				result.skip("%s %s: no parameters to inject into", op.method, op.path)
+				continue
+			}
+
+			for _, point := range points {
+				for i, payload := range payloads {
+					request, err := op.request(point, payload)
+					if err != nil {
+						result.skip("%s %s %s: %v", op.method, op.path, point.tag(), err)
+						break
+					}
+
+					name := fmt.Sprintf("%s-%s-%d", testNameFor(op.method, op.path), sanitizeName(point.tag()), i+1)
+					tests = append(tests, config.Test{
+						Name:     uniqueName(name, used),
+						Tags:     []string{point.location, point.tag()},
+						Request:  request,
+						Expected: opts.Expected,
+					})
+				}
+			}
+		}
+
+		if len(tests) > 0 {
+			name := sanitizeName(path)
+			if name == "" {
+				name = "root"
+			}
+			title := doc.Info.Title
+			if title == "" {
+				title = source
+			}
+			result.Suite = newSuite("openapi-"+name, fmt.Sprintf("Injection tests for %s generated from %s", path, title), baseURL)
+			result.Suite.Spec.Tests = tests
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (d *oaDocument) serverURL() (string, error) {
+	if len(d.Servers) == 0 {
+		return "", fmt.Errorf("document lists no servers, a base URL is required")
+	}
+
+	server := d.Servers[0]
+	raw := server.URL
+	for name, variable := range server.Variables {
+		raw = strings.ReplaceAll(raw, "{"+name+"}", variable.Default)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("server URL %q is not absolute, a base URL is required", server.URL)
+	}
+
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+func (d *oaDocument) operations(path string, result *Result) []openAPIOperation {
+	item := d.Paths[path]
+	if item == nil {
+		return nil
+	}
+
+	methods := []struct {
+		name string
+		op   *oaOperation
+	}{
+		{"GET", item.Get}, {"POST", item.Post}, {"PUT", item.Put}, {"PATCH", item.Patch},
+		{"DELETE", item.Delete}, {"HEAD", item.Head}, {"OPTIONS", item.Options},
+	}
+
+	var ops []openAPIOperation
+	for _, m := range methods {
+		if m.op == nil {
+			continue
+		}
+
+		op := openAPIOperation{method: m.name, path: path}
+
+		// Operation parameters override path-level ones with the same
+		// name and location.
+		byKey := make(map[string]int)
+		for _, list := range [][]*oaParameter{item.Parameters, m.op.Parameters} {
+			for _, p := range list {
+				param := d.resolveParameter(p)
+				if param == nil {
+					result.skip("%s %s: unresolved parameter %s", m.name, path, p.Ref)
+					continue
+				}
+				if param.In == "header" && ignoredHeaderParams[strings.ToLower(param.Name)] {
+					continue
+				}
+				resolved := openAPIParam{in: param.In, name: param.Name, baseline: d.paramBaseline(param)}
+				key := param.In + ":" + param.Name
+				if i, ok := byKey[key]; ok {
+					op.params[i] = resolved
+					continue
+				}
+				byKey[key] = len(op.params)
+				op.params = append(op.params, resolved)
+			}
+		}
+
+		if body := d.resolveRequestBody(m.op.RequestBody); body != nil {
+			for mediaType, media := range body.Content {
+				if !strings.Contains(mediaType, "json") {
+					continue
+				}
+				op.jsonBody = media.Example
+				if op.jsonBody == nil {
+					op.jsonBody = d.baselineValue(media.Schema, make(map[string]bool))
+				}
+				break
+			}
+			if op.jsonBody == nil {
+				result.skip("%s %s: only JSON request bodies are supported", m.name, path)
+			}
+		}
+
+		ops = append(ops, op)
+	}
+
+	return ops
+}
+
+func (d *oaDocument) resolveParameter(p *oaParameter) *oaParameter {
+	for depth := 0; p != nil && p.Ref != ""; depth++ {
+		if depth > maxSchemaDepth {
+			return nil
+		}
+		p = d.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+	}
+	return p
+}
+
+func (d *oaDocument) resolveRequestBody(b *oaRequestBody) *oaRequestBody {
+	for depth := 0; b != nil && b.Ref != ""; depth++ {
+		if depth > maxSchemaDepth {
+			return nil
+		}
+		b = d.Components.RequestBodies[strings.TrimPrefix(b.Ref, "#/components/requestBodies/")]
+	}
+	return b
+}
+
+// resolveSchema follows $ref and merges allOf, picking the first
+// alternative of oneOf/anyOf. It returns nil past maxSchemaDepth.
+func (d *oaDocument) resolveSchema(s *oaSchema, depth int) *oaSchema {
+	for s != nil && s.Ref != "" {
+		if depth > maxSchemaDepth {
+			return nil
+		}
+		depth++
+		s = d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	if s == nil {
+		return nil
+	}
+
+	if len(s.AllOf) > 0 {
+		merged := *s
+		merged.AllOf = nil
+		merged.Properties = make(map[string]*oaSchema)
+		for name, prop := range s.Properties {
+			merged.Properties[name] = prop
+		}
+		for _, part := range s.AllOf {
+			part = d.resolveSchema(part, depth+1)
+			if part == nil {
+				continue
+			}
+			if merged.Type == "" {
+				merged.Type = part.Type
+			}
+			for name, prop := range part.Properties {
+				merged.Properties[name] = prop
+			}
+		}
+		return &merged
+	}
+
+	for _, alternatives := range [][]*oaSchema{s.OneOf, s.AnyOf} {
+		if len(alternatives) > 0 && s.Type == "" && len(s.Properties) == 0 {
+			return d.resolveSchema(alternatives[0], depth+1)
+		}
+	}
+
+	return s
+}
+
+// baselineValue returns a valid value for s: its example, default or first
+// enum value, or a placeholder matching its type and format. Recursive
+// schemas are cut off at the first repeated $ref, which yields nil.
+func (d *oaDocument) baselineValue(s *oaSchema, seen map[string]bool) interface{} {
+	if s != nil && s.Ref != "" {
+		if seen[s.Ref] {
+			return nil
+		}
+		seen[s.Ref] = true
+		defer delete(seen, s.Ref)
+	}
+
+	s = d.resolveSchema(s, 0)
+	if s == nil {
+		return "test"
+	}
+
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	}
+
+	switch s.Type {
+	case "integer":
+		return 1
+	case "number":
+		return 1.5
+	case "boolean":
+		return true
+	case "array":
+		item := d.baselineValue(s.Items, seen)
+		if item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{item}
+	case "object", "":
+		if len(s.Properties) == 0 {
+			if s.Type == "object" {
+				return map[string]interface{}{}
+			}
+			return "test"
+		}
+		obj := make(map[string]interface{}, len(s.Properties))
+		for name, prop := range s.Properties {
+			if value := d.baselineValue(prop, seen); value != nil {
+				obj[name] = value
+			}
+		}
+		return obj
+	}
+
+	switch s.Format {
+	case "uuid":
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "email":
+		return "user@example.com"
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "uri", "url":
+		return "https://example.com/"
+	case "ipv4":
+		return "192.0.2.1"
+	}
+	return "test"
+}
+
+func (d *oaDocument) paramBaseline(p *oaParameter) string {
+	if p.Example != nil {
+		return scalarString(p.Example)
+	}
+	return scalarString(d.baselineValue(p.Schema, make(map[string]bool)))
+}
+
+func scalarString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = scalarString(item)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (op *openAPIOperation) injectionPoints() []injectionPoint {
+	var points []injectionPoint
+	for _, p := range op.params {
+		switch p.in {
+		case "path", "query", "header", "cookie":
+			points = append(points, injectionPoint{location: p.in, name: p.name})
+		}
+	}
+	for _, path := range jsonStringPaths(op.jsonBody, nil) {
+		points = append(points, injectionPoint{location: "json", name: path.String(), jsonPath: path})
+	}
+	return points
+}
+
+// jsonPath addresses a value in a decoded JSON document; each step is an
+// object key (string) or array index (int).
+type jsonPath []interface{}
+
+func (p jsonPath) String() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, step := range p {
+		switch step := step.(type) {
+		case string:
+			b.WriteString("." + step)
+		case int:
+			fmt.Fprintf(&b, "[%d]", step)
+		}
+	}
+	return b.String()
+}
+
+// jsonStringPaths lists the paths of every string leaf in v, in a stable
+// order. Arrays contribute their first element.
+func jsonStringPaths(v interface{}, prefix jsonPath) []jsonPath {
+	switch v := v.(type) {
+	case string:
+		return []jsonPath{prefix}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var paths []jsonPath
+		for _, key := range keys {
+			paths = append(paths, jsonStringPaths(v[key], append(prefix[:len(prefix):len(prefix)], key))...)
+		}
+		return paths
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		return jsonStringPaths(v[0], append(prefix[:len(prefix):len(prefix)], 0))
+	}
+	return nil
+}
+
+// request builds the request for op with payload at point and baseline
+// values everywhere else.
+func (op *openAPIOperation) request(point injectionPoint, payload string) (config.Request, error) {
+	path := op.path
+	query := url.Values{}
+	var rawQuery []string
+	var cookies []string
+	headers := make(map[string]string)
+
+	for _, p := range op.params {
+		value := p.baseline
+		injected := point.location == p.in && point.name == p.name
+		if injected {
+			value = payload
+		}
+
+		switch p.in {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.name+"}", url.PathEscape(value))
+		case "query":
+			if injected {
+				// Keep the payload readable; only escape what would
+				// otherwise break the query string apart.
+				rawQuery = append(rawQuery, url.QueryEscape(p.name)+"="+escapeQueryPayload(value))
+			} else {
+				query.Add(p.name, value)
+			}
+		case "header":
+			headers[p.name] = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+		case "cookie":
+			cookies = append(cookies, p.name+"="+value)
+		}
+	}
+
+	if encoded := query.Encode(); encoded != "" {
+		rawQuery = append([]string{encoded}, rawQuery...)
+	}
+	if len(rawQuery) > 0 {
+		path += "?" + strings.Join(rawQuery, "&")
+	}
+	if len(cookies) > 0 {
+		headers["Cookie"] = strings.Join(cookies, "; ")
+	}
+
+	body := ""
+	if op.jsonBody != nil {
+		value := op.jsonBody
+		if point.location == "json" {
+			value = setJSONPath(deepCopy(op.jsonBody), point.jsonPath, payload)
+		}
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(value); err != nil {
+			return config.Request{}, fmt.Errorf("failed to encode JSON body: %w", err)
+		}
+		body = strings.TrimSuffix(buf.String(), "\n")
+		headers["Content-Type"] = "application/json"
+	}
+
+	if len(headers) == 0 {
+		headers = nil
+	}
+
+	return config.Request{
+		Method:  op.method,
+		Path:    path,
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
+// escapeQueryPayload escapes the characters that would end or split a
+// query value, and those that are not allowed in a request line.
+func escapeQueryPayload(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte("&#%+", c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			c[key] = deepCopy(value)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, value := range v {
+			c[i] = deepCopy(value)
+		}
+		return c
+	}
+	return v
+}
+
+// setJSONPath replaces the value at path in root and returns the new root.
+func setJSONPath(root interface{}, path jsonPath, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+
+	switch step := path[0].(type) {
+	case string:
+		if obj, ok := root.(map[string]interface{}); ok {
+			obj[step] = setJSONPath(obj[step], path[1:], value)
+		}
+	case int:
+		if arr, ok := root.([]interface{}); ok && step < len(arr) {
+			arr[step] = setJSONPath(arr[step], path[1:], value)
+		}
+	}
+	return root
+}
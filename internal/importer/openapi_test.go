@@ -0,0 +1,270 @@
+package importer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"wafguard/internal/core/config"
+	"wafguard/internal/parser"
+)
+
+const testOpenAPI = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+servers:
+  - url: https://{env}.example.com/v1
+    variables:
+      env:
+        default: api
+paths:
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    get:
+      parameters:
+        - name: fields
+          in: query
+          schema:
+            type: string
+            enum: [name, tag]
+        - name: limit
+          in: query
+          schema:
+            type: integer
+        - name: X-Request-ID
+          in: header
+          schema:
+            type: string
+            format: uuid
+        - name: Accept
+          in: header
+          schema:
+            type: string
+        - name: session
+          in: cookie
+          example: abc123
+  /pets:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+  /health:
+    get: {}
+components:
+  parameters:
+    PetId:
+      name: petId
+      in: path
+      required: true
+      schema:
+        type: integer
+  schemas:
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/Named'
+        - type: object
+          properties:
+            age:
+              type: integer
+            tags:
+              type: array
+              items:
+                type: string
+            owner:
+              $ref: '#/components/schemas/Owner'
+    Named:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Rex
+    Owner:
+      type: object
+      properties:
+        email:
+          type: string
+          format: email
+        pets:
+          type: array
+          items:
+            $ref: '#/components/schemas/Pet'
+`
+
+func TestGenerateOpenAPI(t *testing.T) {
+	blocked := true
+	payloads := []string{"' OR 1=1--", "<script>alert(1)</script>"}
+
+	results, err := GenerateOpenAPI([]byte(testOpenAPI), "petstore.yaml", payloads, OpenAPIOptions{Expected: config.Expected{Blocked: &blocked}})
+	if err != nil {
+		t.Fatalf("GenerateOpenAPI() error = %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want one per path", len(results))
+	}
+
+	// Paths are processed in sorted order: /health, /pets, /pets/{petId}
+	health, pets, pet := results[0], results[1], results[2]
+
+	if health.Suite != nil {
+		t.Error("/health has nothing to inject into and should not produce a suite")
+	}
+	if len(health.Unsupported) != 1 {
+		t.Errorf("/health Unsupported = %v, want 1 note", health.Unsupported)
+	}
+
+	if pet.Suite.Spec.Target.BaseURL != "https://api.example.com/v1" {
+		t.Errorf("BaseURL = %q", pet.Suite.Spec.Target.BaseURL)
+	}
+	if pet.Suite.Metadata.Name != "openapi-pets-petid" {
+		t.Errorf("Metadata.Name = %q", pet.Suite.Metadata.Name)
+	}
+
+	// path, 2x query, header and cookie (Accept is ignored), per payload
+	if len(pet.Suite.Spec.Tests) != 5*len(payloads) {
+		t.Fatalf("got %d tests for /pets/{petId}, want %d", len(pet.Suite.Spec.Tests), 5*len(payloads))
+	}
+
+	byTag := make(map[string]config.Test)
+	for _, test := range pet.Suite.Spec.Tests {
+		byTag[test.Tags[1]+"#"+test.Name[len(test.Name)-1:]] = test
+		if test.Expected.Blocked == nil || !*test.Expected.Blocked {
+			t.Errorf("%s: expectation not applied", test.Name)
+		}
+	}
+
+	pathTest := byTag["path:petId#1"]
+	if pathTest.Request.Path != "/pets/%27%20OR%201=1--?fields=name&limit=1" {
+		t.Errorf("path injection = %q", pathTest.Request.Path)
+	}
+	if !reflect.DeepEqual(pathTest.Tags, []string{"path", "path:petId"}) {
+		t.Errorf("Tags = %v", pathTest.Tags)
+	}
+
+	queryTest := byTag["query:fields#2"]
+	if queryTest.Request.Path != "/pets/1?limit=1&fields=<script>alert(1)</script>" {
+		t.Errorf("query injection = %q", queryTest.Request.Path)
+	}
+	if queryTest.Request.Headers["X-Request-ID"] != "3fa85f64-5717-4562-b3fc-2c963f66afa6" {
+		t.Errorf("baseline header = %q", queryTest.Request.Headers["X-Request-ID"])
+	}
+	if queryTest.Request.Headers["Cookie"] != "session=abc123" {
+		t.Errorf("baseline cookie = %q", queryTest.Request.Headers["Cookie"])
+	}
+	if _, ok := queryTest.Request.Headers["Accept"]; ok {
+		t.Error("Accept parameter should be ignored")
+	}
+
+	if byTag["header:X-Request-ID#1"].Request.Headers["X-Request-ID"] != payloads[0] {
+		t.Errorf("header injection = %v", byTag["header:X-Request-ID#1"].Request.Headers)
+	}
+	if byTag["cookie:session#2"].Request.Headers["Cookie"] != "session="+payloads[1] {
+		t.Errorf("cookie injection = %v", byTag["cookie:session#2"].Request.Headers)
+	}
+
+	// JSON body: name, tags[0], owner.email (recursive pets are cut off)
+	var jsonTags []string
+	for _, test := range pets.Suite.Spec.Tests {
+		if strings.HasSuffix(test.Name, "-1") {
+			jsonTags = append(jsonTags, test.Tags[1])
+		}
+	}
+	wantTags := []string{"json:$.name", "json:$.owner.email", "json:$.tags[0]"}
+	if !reflect.DeepEqual(jsonTags, wantTags) {
+		t.Errorf("JSON injection points = %v, want %v", jsonTags, wantTags)
+	}
+
+	nameTest := pets.Suite.Spec.Tests[1]
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(nameTest.Request.Body), &body); err != nil {
+		t.Fatalf("body is not valid JSON: %v", err)
+	}
+	if body["name"] != payloads[1] || body["age"] != float64(1) {
+		t.Errorf("JSON body = %s", nameTest.Request.Body)
+	}
+	if !strings.Contains(nameTest.Request.Body, "<script>") {
+		t.Errorf("payload should not be HTML-escaped: %s", nameTest.Request.Body)
+	}
+	if nameTest.Request.Headers["Content-Type"] != "application/json" {
+		t.Errorf("Content-Type = %q", nameTest.Request.Headers["Content-Type"])
+	}
+
+	for _, result := range []*Result{pets, pet} {
+		data, err := Marshal(result.Suite)
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+		if _, err := parser.NewParser().ParseYAML(data); err != nil {
+			t.Errorf("generated suite does not parse: %v", err)
+		}
+	}
+}
+
+func TestGenerateOpenAPIErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		payloads []string
+		opts     OpenAPIOptions
+	}{
+		{"swagger 2", "swagger: '2.0'\npaths: {}", []string{"x"}, OpenAPIOptions{}},
+		{"invalid yaml", "openapi: [", []string{"x"}, OpenAPIOptions{}},
+		{"no payloads", testOpenAPI, nil, OpenAPIOptions{}},
+		{"relative server", "openapi: 3.0.0\nservers:\n  - url: /api\npaths: {}", []string{"x"}, OpenAPIOptions{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GenerateOpenAPI([]byte(tt.doc), "spec.yaml", tt.payloads, tt.opts); err == nil {
+				t.Error("GenerateOpenAPI() expected error")
+			}
+		})
+	}
+
+	if _, err := GenerateOpenAPI([]byte("openapi: 3.1.0\nservers:\n  - url: /api\npaths: {}"), "spec.yaml", []string{"x"}, OpenAPIOptions{BaseURL: "https://example.com"}); err != nil {
+		t.Errorf("a base URL should make relative servers acceptable: %v", err)
+	}
+}
+
+func TestJSONPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": map[string]interface{}{"b": "x"},
+		"c": []interface{}{[]interface{}{"y"}},
+		"d": 1,
+	}
+
+	paths := jsonStringPaths(doc, nil)
+	var got []string
+	for _, path := range paths {
+		got = append(got, path.String())
+	}
+	if !reflect.DeepEqual(got, []string{"$.a.b", "$.c[0][0]"}) {
+		t.Fatalf("jsonStringPaths() = %v", got)
+	}
+
+	updated := setJSONPath(deepCopy(doc), paths[1], "payload").(map[string]interface{})
+	if updated["c"].([]interface{})[0].([]interface{})[0] != "payload" {
+		t.Errorf("setJSONPath() = %v", updated)
+	}
+	if doc["c"].([]interface{})[0].([]interface{})[0] != "y" {
+		t.Error("setJSONPath() modified the original document")
+	}
+}
+
+func TestReadPayloads(t *testing.T) {
+	input := "# SQLi\n' OR 1=1--\r\n\n  \n<script>\n  padded  \n"
+
+	payloads, err := ReadPayloads(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadPayloads() error = %v", err)
+	}
+
+	want := []string{"' OR 1=1--", "<script>", "  padded  "}
+	if !reflect.DeepEqual(payloads, want) {
+		t.Errorf("ReadPayloads() = %q, want %q", payloads, want)
+	}
+}
@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadPayloads reads one payload per line. Blank lines and lines starting
+// with "#" are ignored; payloads are otherwise kept byte for byte.
+func ReadPayloads(r io.Reader) ([]string, error) {
+	var payloads []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		payloads = append(payloads, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read payloads: %w", err)
+	}
+
+	return payloads, nil
+}
+
+// ReadPayloadFile reads a payload list from path, see ReadPayloads.
+func ReadPayloadFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open payload file %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	payloads, err := ReadPayloads(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(payloads) == 0 {
+		return nil, fmt.Errorf("payload file %s is empty", path)
+	}
+
+	return payloads, nil
+}
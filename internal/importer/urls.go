@@ -0,0 +1,96 @@
+package importer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"wafguard/internal/core/config"
+)
+
+// URLListOptions controls how URL lists are converted.
+type URLListOptions struct {
+	// Name is used for the suite; it defaults to one derived from the source.
+	Name string
+	// BaseURL is the target for every URL. Without it, the origin of the
+	// first absolute URL is used and URLs for other origins are skipped.
+	BaseURL  string
+	Expected config.Expected
+}
+
+// ImportURLList converts a newline-separated list of URLs into a suite of
+// requests. Each line holds an absolute URL or a path, optionally preceded
+// by a method ("POST /login"); blank lines and "#" comments are ignored.
+func ImportURLList(data []byte, source string, opts URLListOptions) (*Result, error) {
+	result := &Result{Source: source}
+
+	lines, err := ReadPayloads(strings.NewReader(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	baseURL := opts.BaseURL
+	used := make(map[string]int)
+	var tests []config.Test
+
+	for i, line := range lines {
+		method := "GET"
+		target := strings.TrimSpace(line)
+		if m, rest, ok := strings.Cut(target, " "); ok && isMethod(strings.ToUpper(m)) {
+			method = strings.ToUpper(m)
+			target = strings.TrimSpace(rest)
+		}
+
+		u, err := url.Parse(target)
+		if err != nil {
+			result.skip("line %d: invalid URL %q", i+1, target)
+			continue
+		}
+
+		if u.IsAbs() {
+			if u.Scheme != "http" && u.Scheme != "https" {
+				result.skip("line %d: unsupported URL %q", i+1, target)
+				continue
+			}
+			origin := (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
+			if baseURL == "" {
+				baseURL = origin
+			} else if opts.BaseURL == "" && origin != baseURL {
+				result.skip("line %d: %s is not on %s", i+1, target, baseURL)
+				continue
+			}
+		} else if !strings.HasPrefix(target, "/") {
+			result.skip("line %d: %q is neither an absolute URL nor a path", i+1, target)
+			continue
+		}
+
+		path := u.EscapedPath()
+		if path == "" {
+			path = "/"
+		}
+		if u.RawQuery != "" {
+			path += "?" + u.RawQuery
+		}
+
+		tests = append(tests, config.Test{
+			Name:     uniqueName(testNameFor(method, path), used),
+			Request:  config.Request{Method: method, Path: path},
+			Expected: opts.Expected,
+		})
+	}
+
+	if len(tests) == 0 {
+		return result, nil
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("%s only contains paths, a target base URL is required", source)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = "urls-" + sourceName(source)
+	}
+	result.Suite = newSuite(sanitizeName(name), fmt.Sprintf("Imported from URL list %s", source), baseURL)
+	result.Suite.Spec.Tests = tests
+
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"wafguard/internal/core/config"
+	"wafguard/internal/parser"
+)
+
+func TestImportURLList(t *testing.T) {
+	list := `# crawl of the shop
+https://shop.example.com/
+https://shop.example.com/products?id=1&sort=price
+POST https://shop.example.com/cart
+https://other.example.com/elsewhere
+/relative/path
+ftp://shop.example.com/file
+not a url
+`
+	allowed := false
+	result, err := ImportURLList([]byte(list), "crawl.txt", URLListOptions{Expected: config.Expected{Blocked: &allowed}})
+	if err != nil {
+		t.Fatalf("ImportURLList() error = %v", err)
+	}
+
+	suite := result.Suite
+	if suite.Metadata.Name != "urls-crawl" || suite.Spec.Target.BaseURL != "https://shop.example.com" {
+		t.Errorf("suite = %s on %s", suite.Metadata.Name, suite.Spec.Target.BaseURL)
+	}
+
+	var got []string
+	for _, test := range suite.Spec.Tests {
+		got = append(got, test.Request.Method+" "+test.Request.Path)
+		if test.Expected.Blocked == nil || *test.Expected.Blocked {
+			t.Errorf("test %s should expect to be allowed", test.Name)
+		}
+	}
+	want := "GET / | GET /products?id=1&sort=price | POST /cart | GET /relative/path"
+	if strings.Join(got, " | ") != want {
+		t.Errorf("requests = %s, want %s", strings.Join(got, " | "), want)
+	}
+
+	if len(result.Unsupported) != 3 {
+		t.Errorf("Unsupported = %v, want 3 notes", result.Unsupported)
+	}
+
+	data, err := Marshal(suite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.NewParser().ParseYAML(data); err != nil {
+		t.Errorf("imported suite is invalid: %v", err)
+	}
+}
+
+func TestImportURLListBaseURL(t *testing.T) {
+	result, err := ImportURLList([]byte("https://a.example.com/x\nhttps://b.example.com/y\n/z\n"), "urls.txt", URLListOptions{BaseURL: "https://staging.example.com"})
+	if err != nil {
+		t.Fatalf("ImportURLList() error = %v", err)
+	}
+	if len(result.Suite.Spec.Tests) != 3 || result.Suite.Spec.Target.BaseURL != "https://staging.example.com" {
+		t.Errorf("unexpected suite: %+v", result.Suite.Spec)
+	}
+}
+
+func TestImportURLListPathsOnly(t *testing.T) {
+	if _, err := ImportURLList([]byte("/a\n/b\n"), "paths.txt", URLListOptions{}); err == nil {
+		t.Error("ImportURLList() expected error without a base URL")
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"os"
 
 	"github.com/sirupsen/logrus"
@@ -43,6 +44,10 @@ func SetFormatter(format string) {
 	}
 }
 
+func SetOutput(w io.Writer) {
+	log.SetOutput(w)
+}
+
 func GetLogger() *logrus.Logger {
 	return log
 }
@@ -0,0 +1,331 @@
+// Package minimize shrinks a request that bypasses the WAF to the smallest
+// request that still does, using delta debugging over the path, the body
+// and the header values.
+package minimize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/logger"
+	"wafguard/internal/validator"
+
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultBudget = 500
+
+// Options bounds a minimization. A candidate reproduces the bypass when
+// the WAF does not block it and the origin answers with the same status as
+// for the original request and a body containing Marker. The marker is
+// required: an unblocked status alone is also what a harmless request
+// gets, so without it the payload would be reduced away.
+type Options struct {
+	Budget int
+	Marker string
+}
+
+// Result is the smallest reproducing request found.
+type Result struct {
+	Test       *config.Test
+	Original   config.Request
+	StatusCode int
+	Requests   int
+	// Exhausted is set when the budget ran out before the request was
+	// fully minimized.
+	Exhausted bool
+}
+
+type Minimizer struct {
+	executor  executor.Executor
+	validator *validator.ResponseValidator
+	options   Options
+}
+
+func NewMinimizer(httpExecutor executor.Executor, responseValidator *validator.ResponseValidator, options Options) *Minimizer {
+	if options.Budget <= 0 {
+		options.Budget = DefaultBudget
+	}
+
+	return &Minimizer{
+		executor:  httpExecutor,
+		validator: responseValidator,
+		options:   options,
+	}
+}
+
+// ErrNoMarker is returned by Minimize when Options.Marker is empty.
+var ErrNoMarker = errors.New("a marker is required to tell a bypass from a harmless request")
+
+// errBudget stops a reduction once the request budget is spent.
+var errBudget = errors.New("request budget exhausted")
+
+type run struct {
+	m        *Minimizer
+	ctx      context.Context
+	target   config.Target
+	test     config.Test
+	status   int
+	requests int
+	seen     map[string]bool
+}
+
+// Minimize checks that the test's request bypasses the WAF and then
+// removes as much of its path, body and headers as possible. It returns an
+// error when the original request is blocked or cannot be sent.
+func (m *Minimizer) Minimize(ctx context.Context, test *config.Test, target config.Target) (*Result, error) {
+	if m.options.Marker == "" {
+		return nil, ErrNoMarker
+	}
+
+	r := &run{
+		m:      m,
+		ctx:    ctx,
+		target: target,
+		test:   *test,
+		seen:   make(map[string]bool),
+	}
+	r.test.Request.Headers = copyHeaders(test.Request.Headers)
+
+	response, err := m.executor.ExecuteTest(ctx, &r.test, target.BaseURL)
+	r.requests++
+	if err != nil {
+		return nil, fmt.Errorf("original request failed: %w", err)
+	}
+	if m.validator.WithBlockStatus(target.BlockStatus).IsBlocked(response) {
+		return nil, fmt.Errorf("original request is blocked (status %d), there is no bypass to minimize", response.StatusCode)
+	}
+	if !strings.Contains(response.Body, m.options.Marker) {
+		return nil, fmt.Errorf("original response does not contain %q", m.options.Marker)
+	}
+	r.status = response.StatusCode
+
+	result := &Result{Original: test.Request, StatusCode: r.status}
+	err = r.reduce()
+	if err != nil && err != errBudget {
+		return nil, err
+	}
+
+	result.Test = &r.test
+	result.Requests = r.requests
+	result.Exhausted = err == errBudget
+	return result, nil
+}
+
+// reduce repeats a pass over every part of the request until a pass makes
+// no progress.
+func (r *run) reduce() error {
+	for {
+		before := RequestSize(r.test.Request)
+
+		if err := r.reduceHeaders(); err != nil {
+			return err
+		}
+		if err := r.reduceBody(); err != nil {
+			return err
+		}
+		if err := r.reducePath(); err != nil {
+			return err
+		}
+
+		if RequestSize(r.test.Request) == before {
+			return nil
+		}
+	}
+}
+
+func (r *run) reduceHeaders() error {
+	for _, name := range sortedKeys(r.test.Request.Headers) {
+		value := r.test.Request.Headers[name]
+
+		// Try dropping the header altogether before shrinking its value
+		ok, err := r.try(func(request *config.Request) { delete(request.Headers, name) })
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+
+		reduced, err := ddmin([]rune(value), func(candidate []rune) (bool, error) {
+			return r.try(func(request *config.Request) { request.Headers[name] = string(candidate) })
+		})
+		if err != nil {
+			return err
+		}
+		r.test.Request.Headers[name] = string(reduced)
+	}
+	return nil
+}
+
+func (r *run) reduceBody() error {
+	reduced, err := ddmin([]rune(r.test.Request.Body), func(candidate []rune) (bool, error) {
+		return r.try(func(request *config.Request) { request.Body = string(candidate) })
+	})
+	if err != nil {
+		return err
+	}
+	r.test.Request.Body = string(reduced)
+	return nil
+}
+
+// reducePath shrinks everything after the leading slash, which has to
+// stay for the path to resolve against the base URL.
+func (r *run) reducePath() error {
+	path := strings.TrimPrefix(r.test.Request.Path, "/")
+	reduced, err := ddmin([]rune(path), func(candidate []rune) (bool, error) {
+		return r.try(func(request *config.Request) { request.Path = "/" + string(candidate) })
+	})
+	if err != nil {
+		return err
+	}
+	r.test.Request.Path = "/" + string(reduced)
+	return nil
+}
+
+// try sends the current request with change applied and reports whether
+// it still reproduces the bypass. Requests that were already sent are
+// answered from memory.
+func (r *run) try(change func(*config.Request)) (bool, error) {
+	candidate := r.test
+	candidate.Request.Headers = copyHeaders(r.test.Request.Headers)
+	change(&candidate.Request)
+
+	key := fmt.Sprintf("%s\x00%v\x00%s", candidate.Request.Path, candidate.Request.Headers, candidate.Request.Body)
+	if ok, seen := r.seen[key]; seen {
+		if ok {
+			r.test = candidate
+		}
+		return ok, nil
+	}
+
+	if err := r.ctx.Err(); err != nil {
+		return false, err
+	}
+	if r.requests >= r.m.options.Budget {
+		return false, errBudget
+	}
+
+	r.requests++
+	response, err := r.m.executor.ExecuteTest(r.ctx, &candidate, r.target.BaseURL)
+	ok := err == nil && r.reproduces(response)
+	r.seen[key] = ok
+
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"test_name": r.test.Name,
+			"error":     err.Error(),
+		}).Debug("Candidate request failed")
+	}
+	if ok {
+		r.test = candidate
+	}
+	return ok, nil
+}
+
+func (r *run) reproduces(response *executor.Response) bool {
+	if r.m.validator.WithBlockStatus(r.target.BlockStatus).IsBlocked(response) {
+		return false
+	}
+	if response.StatusCode != r.status {
+		return false
+	}
+	return strings.Contains(response.Body, r.m.options.Marker)
+}
+
+// ddmin returns a 1-minimal subsequence of input for which test holds,
+// assuming it holds for input itself. test is expected to adopt every
+// candidate it accepts.
+func ddmin(input []rune, test func([]rune) (bool, error)) ([]rune, error) {
+	if len(input) == 0 {
+		return input, nil
+	}
+	if ok, err := test(nil); err != nil || ok {
+		return nil, err
+	}
+
+	n := 2
+	for len(input) >= 2 {
+		chunks := split(input, n)
+		reduced := false
+
+		for i := range chunks {
+			complement := make([]rune, 0, len(input)-len(chunks[i]))
+			for j, chunk := range chunks {
+				if j != i {
+					complement = append(complement, chunk...)
+				}
+			}
+
+			ok, err := test(complement)
+			if err != nil {
+				return input, err
+			}
+			if ok {
+				input = complement
+				if n > 2 {
+					n--
+				}
+				reduced = true
+				break
+			}
+		}
+
+		if !reduced {
+			if n >= len(input) {
+				break
+			}
+			n *= 2
+			if n > len(input) {
+				n = len(input)
+			}
+		}
+	}
+
+	return input, nil
+}
+
+// split cuts input into n chunks of nearly equal length.
+func split(input []rune, n int) [][]rune {
+	chunks := make([][]rune, 0, n)
+	start := 0
+	for i := 0; i < n; i++ {
+		end := start + (len(input)-start)/(n-i)
+		chunks = append(chunks, input[start:end])
+		start = end
+	}
+	return chunks
+}
+
+// RequestSize is the number of bytes in the path, body and headers.
+func RequestSize(request config.Request) int {
+	size := len(request.Path) + len(request.Body)
+	for name, value := range request.Headers {
+		size += len(name) + len(value)
+	}
+	return size
+}
+
+func copyHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(headers))
+	for name, value := range headers {
+		copied[name] = value
+	}
+	return copied
+}
+
+func sortedKeys(headers map[string]string) []string {
+	keys := make([]string, 0, len(headers))
+	for name := range headers {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	return keys
+}
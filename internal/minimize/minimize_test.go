@@ -0,0 +1,186 @@
+package minimize
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/validator"
+)
+
+// bypassServer blocks requests containing "UNION SELECT" and answers 200
+// with a database error when the body contains the bypass "UNION/**/SELECT"
+func bypassServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if strings.Contains(string(body), "UNION SELECT") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if !strings.Contains(string(body), "UNION/**/SELECT") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("X-Session") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("SQL syntax error"))
+	}))
+}
+
+func newTestMinimizer(options Options) *Minimizer {
+	return NewMinimizer(executor.NewHTTPExecutor(5*time.Second), validator.NewResponseValidator(), options)
+}
+
+func TestMinimize(t *testing.T) {
+	server := bypassServer()
+	defer server.Close()
+
+	test := &config.Test{
+		Name: "bypass",
+		Request: config.Request{
+			Method: "POST",
+			Path:   "/api/search?page=1&sort=desc",
+			Headers: map[string]string{
+				"X-Session":  "abcdef",
+				"User-Agent": "Mozilla/5.0 (X11; Linux x86_64)",
+			},
+			Body: "filter=" + strings.Repeat("a", 200) + "' UNION/**/SELECT password FROM users--" + strings.Repeat("b", 100),
+		},
+	}
+
+	result, err := newTestMinimizer(Options{Marker: "SQL syntax"}).Minimize(context.Background(), test, config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Minimize() error = %v", err)
+	}
+
+	got := result.Test.Request
+	if got.Body != "UNION/**/SELECT" {
+		t.Errorf("Body = %q, want %q", got.Body, "UNION/**/SELECT")
+	}
+	if got.Path != "/" {
+		t.Errorf("Path = %q, want /", got.Path)
+	}
+	if len(got.Headers) != 1 || len(got.Headers["X-Session"]) != 1 {
+		t.Errorf("Headers = %v, want X-Session cut to one character", got.Headers)
+	}
+	if result.StatusCode != 200 || result.Exhausted {
+		t.Errorf("StatusCode = %d, Exhausted = %v", result.StatusCode, result.Exhausted)
+	}
+	if test.Request.Headers["User-Agent"] == "" {
+		t.Error("Minimize() modified the original test")
+	}
+}
+
+func TestMinimizeMarker(t *testing.T) {
+	server := bypassServer()
+	defer server.Close()
+
+	test := &config.Test{
+		Name: "bypass",
+		Request: config.Request{
+			Method:  "POST",
+			Path:    "/",
+			Headers: map[string]string{"X-Session": "a"},
+			Body:    "x UNION/**/SELECT y",
+		},
+	}
+
+	if _, err := newTestMinimizer(Options{Marker: "not in the body"}).Minimize(context.Background(), test, config.Target{BaseURL: server.URL}); err == nil {
+		t.Error("Minimize() expected error when the marker is missing")
+	}
+
+	result, err := newTestMinimizer(Options{Marker: "SQL syntax"}).Minimize(context.Background(), test, config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Minimize() error = %v", err)
+	}
+	if result.Test.Request.Body != "UNION/**/SELECT" {
+		t.Errorf("Body = %q", result.Test.Request.Body)
+	}
+}
+
+func TestMinimizeRequiresMarker(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	test := &config.Test{
+		Name:    "bypass",
+		Request: config.Request{Method: "POST", Path: "/", Body: "x UNION/**/SELECT y"},
+	}
+
+	if _, err := newTestMinimizer(Options{}).Minimize(context.Background(), test, config.Target{BaseURL: server.URL}); !errors.Is(err, ErrNoMarker) {
+		t.Errorf("Minimize() error = %v, want ErrNoMarker", err)
+	}
+	if requests != 0 {
+		t.Errorf("Minimize() sent %d requests without a marker", requests)
+	}
+}
+
+func TestMinimizeBlockedOriginal(t *testing.T) {
+	server := bypassServer()
+	defer server.Close()
+
+	test := &config.Test{
+		Name:    "blocked",
+		Request: config.Request{Method: "POST", Path: "/", Body: "UNION SELECT"},
+	}
+
+	if _, err := newTestMinimizer(Options{Marker: "SQL syntax"}).Minimize(context.Background(), test, config.Target{BaseURL: server.URL}); err == nil {
+		t.Error("Minimize() expected error for a blocked request")
+	}
+}
+
+func TestMinimizeBudget(t *testing.T) {
+	server := bypassServer()
+	defer server.Close()
+
+	test := &config.Test{
+		Name: "bypass",
+		Request: config.Request{
+			Method:  "POST",
+			Path:    "/",
+			Headers: map[string]string{"X-Session": "a"},
+			Body:    strings.Repeat("a", 500) + "UNION/**/SELECT",
+		},
+	}
+
+	result, err := newTestMinimizer(Options{Budget: 5, Marker: "SQL syntax"}).Minimize(context.Background(), test, config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("Minimize() error = %v", err)
+	}
+	if !result.Exhausted || result.Requests != 5 {
+		t.Errorf("Requests = %d, Exhausted = %v, want 5 and true", result.Requests, result.Exhausted)
+	}
+	if !strings.Contains(result.Test.Request.Body, "UNION/**/SELECT") {
+		t.Errorf("partial result no longer reproduces: %q", result.Test.Request.Body)
+	}
+}
+
+func TestDDMin(t *testing.T) {
+	calls := 0
+	got, err := ddmin([]rune("xxaxxbxx"), func(candidate []rune) (bool, error) {
+		calls++
+		s := string(candidate)
+		return strings.Contains(s, "a") && strings.Contains(s, "b"), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "ab" {
+		t.Errorf("ddmin() = %q, want %q", string(got), "ab")
+	}
+	if calls == 0 {
+		t.Error("ddmin() never called the test")
+	}
+}
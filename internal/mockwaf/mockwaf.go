@@ -0,0 +1,252 @@
+// Package mockwaf is a rule-based stand-in for a WAF. Requests matching a
+// rule get a block page; everything else is echoed back, so suites can run
+// hermetically without a real WAF or origin.
+package mockwaf
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"wafguard/internal/echo"
+	"wafguard/internal/logger"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rules.yaml
+var defaultRules []byte
+
+// DefaultBlockPage is used when neither the rule nor the rule file sets a
+// block page. Like ModSecurity's audit messages it names the rule, so
+// tools that extract rule IDs from block pages work against the mock.
+const DefaultBlockPage = `<html><head><title>{{status}} {{reason}}</title></head>
+<body><h1>Request blocked</h1>
+<p>The request was blocked by the wafguard mock WAF. [id "{{id}}"] [msg "{{msg}}"]</p>
+</body></html>
+`
+
+// Targets a rule can inspect.
+const (
+	TargetPath    = "path"
+	TargetArgs    = "args"
+	TargetHeaders = "headers"
+	TargetBody    = "body"
+	// TargetHeaderPrefix selects a single header, e.g. header:User-Agent.
+	TargetHeaderPrefix = "header:"
+)
+
+// Rules is a mock WAF rule file. BlockStatus and BlockPage are defaults
+// for rules that do not set their own.
+type Rules struct {
+	BlockStatus int    `yaml:"blockStatus,omitempty"`
+	BlockPage   string `yaml:"blockPage,omitempty"`
+	Rules       []Rule `yaml:"rules"`
+}
+
+// Rule blocks requests in which Pattern matches one of Targets (all of
+// them by default). Targets are inspected as received and percent-decoded
+// up to twice, so encoded payloads are caught too. The block page may use
+// the placeholders {{id}}, {{msg}}, {{status}} and {{reason}}.
+type Rule struct {
+	ID        string   `yaml:"id"`
+	Message   string   `yaml:"msg,omitempty"`
+	Pattern   string   `yaml:"pattern"`
+	Targets   []string `yaml:"targets,omitempty"`
+	Status    int      `yaml:"status,omitempty"`
+	BlockPage string   `yaml:"blockPage,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// DefaultRules returns the built-in rules, which block every pack shipped
+// with wafguard.
+func DefaultRules() *Rules {
+	rules, err := ParseRules(defaultRules)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default mock WAF rules: %v", err))
+	}
+	return rules
+}
+
+// LoadRules reads a rule file.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules %s: %w", path, err)
+	}
+
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseRules parses and compiles a rule file.
+func ParseRules(data []byte) (*Rules, error) {
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+
+	if rules.BlockStatus == 0 {
+		rules.BlockStatus = http.StatusForbidden
+	}
+	if rules.BlockPage == "" {
+		rules.BlockPage = DefaultBlockPage
+	}
+	if err := checkStatus(rules.BlockStatus); err != nil {
+		return nil, err
+	}
+	if len(rules.Rules) == 0 {
+		return nil, fmt.Errorf("no rules defined")
+	}
+
+	for i := range rules.Rules {
+		rule := &rules.Rules[i]
+		if rule.ID == "" {
+			rule.ID = strconv.Itoa(i + 1)
+		}
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("rule %s: pattern is required", rule.ID)
+		}
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid pattern: %w", rule.ID, err)
+		}
+		rule.re = re
+
+		for _, target := range rule.Targets {
+			switch {
+			case target == TargetPath, target == TargetArgs, target == TargetHeaders, target == TargetBody:
+			case strings.HasPrefix(target, TargetHeaderPrefix) && len(target) > len(TargetHeaderPrefix):
+			default:
+				return nil, fmt.Errorf("rule %s: unknown target %q (use path, args, headers, body or header:<name>)", rule.ID, target)
+			}
+		}
+		if len(rule.Targets) == 0 {
+			rule.Targets = []string{TargetPath, TargetArgs, TargetHeaders, TargetBody}
+		}
+
+		if rule.Status == 0 {
+			rule.Status = rules.BlockStatus
+		}
+		if err := checkStatus(rule.Status); err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+		if rule.BlockPage == "" {
+			rule.BlockPage = rules.BlockPage
+		}
+	}
+
+	return &rules, nil
+}
+
+func checkStatus(status int) error {
+	if status < 100 || status > 599 {
+		return fmt.Errorf("invalid block status %d", status)
+	}
+	return nil
+}
+
+// Match returns the first rule matching r, whose body has already been
+// read into body, or nil.
+func (rs *Rules) Match(r *http.Request, body []byte) *Rule {
+	for i := range rs.Rules {
+		rule := &rs.Rules[i]
+		for _, target := range rule.Targets {
+			for _, value := range inspect(r, body, target) {
+				if rule.re.MatchString(value) {
+					return rule
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// inspect returns the values of a target, each in its received and
+// decoded forms.
+func inspect(r *http.Request, body []byte, target string) []string {
+	var values []string
+	add := func(s string, unescape func(string) (string, error)) {
+		values = append(values, s)
+		for i := 0; i < 2; i++ {
+			decoded, err := unescape(s)
+			if err != nil || decoded == s {
+				return
+			}
+			values = append(values, decoded)
+			s = decoded
+		}
+	}
+
+	switch {
+	case target == TargetPath:
+		path, _, _ := strings.Cut(r.RequestURI, "?")
+		add(path, url.PathUnescape)
+	case target == TargetArgs:
+		add(r.URL.RawQuery, url.QueryUnescape)
+	case target == TargetBody:
+		add(string(body), url.QueryUnescape)
+	case target == TargetHeaders:
+		for name, headerValues := range r.Header {
+			for _, value := range headerValues {
+				add(name+": "+value, url.PathUnescape)
+			}
+		}
+	default:
+		name := strings.TrimPrefix(target, TargetHeaderPrefix)
+		for _, value := range r.Header.Values(name) {
+			add(value, url.PathUnescape)
+		}
+	}
+
+	return values
+}
+
+// Handler blocks requests matching rules and echoes the others.
+func Handler(rules *Rules) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := echo.ReadBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		rule := rules.Match(r, body)
+		if rule == nil {
+			logger.WithFields(logrus.Fields{
+				"method": r.Method,
+				"uri":    r.RequestURI,
+			}).Info("Request allowed")
+			echo.Write(w, echo.Capture(r, body))
+			return
+		}
+
+		logger.WithFields(logrus.Fields{
+			"method":  r.Method,
+			"uri":     r.RequestURI,
+			"rule_id": rule.ID,
+			"status":  rule.Status,
+		}).Info("Request blocked")
+
+		page := strings.NewReplacer(
+			"{{id}}", rule.ID,
+			"{{msg}}", rule.Message,
+			"{{status}}", strconv.Itoa(rule.Status),
+			"{{reason}}", http.StatusText(rule.Status),
+		).Replace(rule.BlockPage)
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(rule.Status)
+		_, _ = w.Write([]byte(page))
+	})
+}
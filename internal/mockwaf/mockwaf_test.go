@@ -0,0 +1,167 @@
+package mockwaf
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wafguard/internal/core/config"
+	"wafguard/internal/echo"
+	"wafguard/internal/executor"
+	"wafguard/internal/packs"
+)
+
+func TestDefaultRulesBlockPacks(t *testing.T) {
+	server := httptest.NewServer(Handler(DefaultRules()))
+	defer server.Close()
+
+	exec := executor.NewHTTPExecutor(0)
+	list, err := packs.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pack := range list {
+		suite, err := packs.Load(pack.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range suite.Spec.Tests {
+			test := &suite.Spec.Tests[i]
+			resp, err := exec.ExecuteTest(context.Background(), test, server.URL)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", pack.Name, test.Name, err)
+			}
+			if resp.StatusCode != 403 {
+				t.Errorf("%s/%s: status %d, want 403", pack.Name, test.Name, resp.StatusCode)
+			}
+		}
+	}
+}
+
+func TestDefaultRulesAllowBenign(t *testing.T) {
+	server := httptest.NewServer(Handler(DefaultRules()))
+	defer server.Close()
+
+	requests := []config.Request{
+		{Method: "GET", Path: "/"},
+		{Method: "GET", Path: "/search?q=red+shoes&page=2"},
+		{Method: "GET", Path: "/products/42?ref=home", Headers: map[string]string{"Referer": "https://shop.example.com/"}},
+		{Method: "POST", Path: "/login", Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, Body: "user=alice&password=correct+horse"},
+		{Method: "POST", Path: "/api/orders", Headers: map[string]string{"Content-Type": "application/json"}, Body: `{"item": 42, "note": "leave at the door"}`},
+	}
+
+	exec := executor.NewHTTPExecutor(0)
+	for _, request := range requests {
+		test := &config.Test{Name: "benign", Request: request}
+		resp, err := exec.ExecuteTest(context.Background(), test, server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != 200 {
+			t.Errorf("%s %s: status %d, want 200: %s", request.Method, request.Path, resp.StatusCode, resp.Body)
+			continue
+		}
+
+		var echoed echo.Request
+		if err := json.Unmarshal([]byte(resp.Body), &echoed); err != nil {
+			t.Fatalf("response is not an echo: %v", err)
+		}
+		if echoed.Method != request.Method || echoed.URI != request.Path || echoed.Body != request.Body {
+			t.Errorf("echo = %+v, want %s %s %q", echoed, request.Method, request.Path, request.Body)
+		}
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+blockStatus: 406
+blockPage: "denied by {{id}}"
+rules:
+  - id: scanner
+    pattern: (?i)sqlmap|nikto
+    targets: [header:User-Agent]
+  - id: admin
+    msg: Admin area
+    pattern: ^/admin
+    targets: [path]
+    status: 401
+    blockPage: "{{status}} {{reason}}: {{msg}}"
+`))
+	if err != nil {
+		t.Fatalf("ParseRules() error = %v", err)
+	}
+
+	server := httptest.NewServer(Handler(rules))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		request config.Request
+		status  int
+		body    string
+	}{
+		{
+			name:    "user agent",
+			request: config.Request{Method: "GET", Path: "/", Headers: map[string]string{"User-Agent": "sqlmap/1.7"}},
+			status:  406,
+			body:    "denied by scanner",
+		},
+		{
+			name:    "other header",
+			request: config.Request{Method: "GET", Path: "/", Headers: map[string]string{"X-Tool": "sqlmap"}},
+			status:  200,
+		},
+		{
+			name:    "path",
+			request: config.Request{Method: "GET", Path: "/admin/users"},
+			status:  401,
+			body:    "401 Unauthorized: Admin area",
+		},
+		{
+			name:    "path rule ignores args",
+			request: config.Request{Method: "GET", Path: "/?next=/admin"},
+			status:  200,
+		},
+	}
+
+	exec := executor.NewHTTPExecutor(0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := exec.ExecuteTest(context.Background(), &config.Test{Name: tt.name, Request: tt.request}, server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.body != "" && resp.Body != tt.body {
+				t.Errorf("body = %q, want %q", resp.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  string
+	}{
+		{"no rules", "blockStatus: 403\n", "no rules defined"},
+		{"missing pattern", "rules:\n  - id: a\n", "pattern is required"},
+		{"bad pattern", "rules:\n  - pattern: '('\n", "invalid pattern"},
+		{"bad target", "rules:\n  - pattern: x\n    targets: [cookies]\n", "unknown target"},
+		{"bad status", "rules:\n  - pattern: x\n    status: 9000\n", "invalid block status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules([]byte(tt.rules))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseRules() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Package packs provides the curated attack payload packs embedded in the
+// wafguard binary.
+package packs
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"wafguard/internal/core/config"
+	"wafguard/internal/parser"
+)
+
+// Prefix marks a pack reference on the command line, e.g. pack:sqli.
+const Prefix = "pack:"
+
+//go:embed data/*.yaml
+var files embed.FS
+
+// Pack describes an embedded pack.
+type Pack struct {
+	Name        string
+	Version     string
+	Description string
+	Tests       int
+}
+
+// List returns every embedded pack, sorted by name.
+func List() ([]Pack, error) {
+	names, err := packNames()
+	if err != nil {
+		return nil, err
+	}
+
+	packs := make([]Pack, 0, len(names))
+	for _, name := range names {
+		suite, err := Load(name)
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, Pack{
+			Name:        suite.Metadata.Name,
+			Version:     suite.Metadata.Version,
+			Description: suite.Metadata.Description,
+			Tests:       len(suite.Spec.Tests),
+		})
+	}
+
+	return packs, nil
+}
+
+// Load parses the named pack. Packs target http://localhost; callers set
+// the real target before running them.
+func Load(name string) (*config.SentinelTest, error) {
+	data, err := Raw(name)
+	if err != nil {
+		return nil, err
+	}
+
+	suite, err := parser.NewParser().ParseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pack %s: %w", name, err)
+	}
+
+	return suite, nil
+}
+
+// Raw returns the YAML source of the named pack.
+func Raw(name string) ([]byte, error) {
+	data, err := files.ReadFile(path.Join("data", name+".yaml"))
+	if err != nil {
+		available, _ := packNames()
+		return nil, fmt.Errorf("unknown pack %q (available: %s)", name, strings.Join(available, ", "))
+	}
+	return data, nil
+}
+
+// ParseRef returns the pack names in a reference such as "pack:sqli" or
+// "pack:sqli,xss". "pack:all" selects every pack. ok is false when ref is
+// not a pack reference.
+func ParseRef(ref string) (names []string, ok bool, err error) {
+	if !strings.HasPrefix(ref, Prefix) {
+		return nil, false, nil
+	}
+
+	list := strings.TrimPrefix(ref, Prefix)
+	if list == "all" {
+		all, err := packNames()
+		return all, true, err
+	}
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, true, fmt.Errorf("no pack named in %q", ref)
+	}
+
+	return names, true, nil
+}
+
+func packNames() ([]string, error) {
+	entries, err := fs.ReadDir(files, "data")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list packs: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
@@ -0,0 +1,143 @@
+package packs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wafguard/internal/executor"
+	"wafguard/internal/validator"
+)
+
+func TestList(t *testing.T) {
+	packs, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	want := []string{"command-injection", "file-inclusion", "jndi", "nosql", "path-traversal", "sqli", "ssrf", "ssti", "xss", "xxe"}
+	if len(packs) != len(want) {
+		t.Fatalf("List() returned %d packs, want %d", len(packs), len(want))
+	}
+
+	for i, pack := range packs {
+		if pack.Name != want[i] {
+			t.Errorf("pack %d name = %q, want %q (metadata name must match the file name)", i, pack.Name, want[i])
+		}
+		if pack.Version == "" || pack.Description == "" {
+			t.Errorf("pack %s is missing a version or description", pack.Name)
+		}
+		if pack.Tests == 0 {
+			t.Errorf("pack %s has no tests", pack.Name)
+		}
+	}
+}
+
+func TestPackConventions(t *testing.T) {
+	packs, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	seen := make(map[string]string)
+	for _, pack := range packs {
+		suite, err := Load(pack.Name)
+		if err != nil {
+			t.Fatalf("Load(%s) error = %v", pack.Name, err)
+		}
+
+		for _, test := range suite.Spec.Tests {
+			if other, ok := seen[test.Name]; ok {
+				t.Errorf("test name %s is used by packs %s and %s", test.Name, other, pack.Name)
+			}
+			seen[test.Name] = pack.Name
+
+			if len(test.Tags) == 0 || test.Tags[0] != pack.Name {
+				t.Errorf("%s/%s: first tag should be the pack name, got %v", pack.Name, test.Name, test.Tags)
+			}
+			if test.Expected.Blocked == nil || !*test.Expected.Blocked {
+				t.Errorf("%s/%s: pack tests should expect blocked: true", pack.Name, test.Name)
+			}
+		}
+	}
+}
+
+// Every request in every pack must be sendable as written, otherwise a
+// "blocked" result could come from a malformed request instead of the WAF.
+func TestPackRequestsAreWellFormed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	packs, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	httpExecutor := executor.NewHTTPExecutor(5 * time.Second)
+	responseValidator := validator.NewResponseValidator()
+
+	for _, pack := range packs {
+		suite, err := Load(pack.Name)
+		if err != nil {
+			t.Fatalf("Load(%s) error = %v", pack.Name, err)
+		}
+
+		for _, test := range suite.Spec.Tests {
+			t.Run(test.Name, func(t *testing.T) {
+				response, err := httpExecutor.ExecuteTest(context.Background(), &test, server.URL)
+				if err != nil {
+					t.Fatalf("request failed: %v", err)
+				}
+				result := responseValidator.Validate(response, &test.Expected, test.Name)
+				if !result.Passed {
+					t.Errorf("server rejected the request itself: status %d", response.StatusCode)
+				}
+			})
+		}
+	}
+}
+
+func TestLoadUnknownPack(t *testing.T) {
+	if _, err := Load("does-not-exist"); err == nil {
+		t.Error("Load() expected error for unknown pack")
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		ref       string
+		wantNames []string
+		wantOK    bool
+		wantErr   bool
+	}{
+		{ref: "tests/", wantOK: false},
+		{ref: "pack:sqli", wantNames: []string{"sqli"}, wantOK: true},
+		{ref: "pack:sqli, xss", wantNames: []string{"sqli", "xss"}, wantOK: true},
+		{ref: "pack:", wantOK: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			names, ok, err := ParseRef(tt.ref)
+			if ok != tt.wantOK || (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRef() ok = %v, err = %v", ok, err)
+			}
+			if len(names) != len(tt.wantNames) {
+				t.Fatalf("ParseRef() names = %v, want %v", names, tt.wantNames)
+			}
+			for i := range names {
+				if names[i] != tt.wantNames[i] {
+					t.Errorf("ParseRef() names = %v, want %v", names, tt.wantNames)
+				}
+			}
+		})
+	}
+
+	all, ok, err := ParseRef("pack:all")
+	if !ok || err != nil || len(all) != 10 {
+		t.Errorf("ParseRef(pack:all) = %v, %v, %v", all, ok, err)
+	}
+}
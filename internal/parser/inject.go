@@ -0,0 +1,414 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"wafguard/internal/core/config"
+	"wafguard/internal/transform"
+)
+
+// expandInjections replaces every test that has an inject block with one
+// test per payload and location, in payload order.
+func expandInjections(suite *config.SentinelTest) error {
+	var tests []config.Test
+
+	for _, test := range suite.Spec.Tests {
+		if test.Inject == nil {
+			if len(test.Transforms) > 0 {
+				return fmt.Errorf("test %s: transforms require an inject block", test.Name)
+			}
+			tests = append(tests, test)
+			continue
+		}
+
+		expanded, err := expandInject(test)
+		if err != nil {
+			return fmt.Errorf("test %s: %w", test.Name, err)
+		}
+		tests = append(tests, expanded...)
+	}
+
+	suite.Spec.Tests = tests
+	return nil
+}
+
+func expandInject(test config.Test) ([]config.Test, error) {
+	inject := test.Inject
+	if len(inject.Payloads) == 0 {
+		return nil, fmt.Errorf("inject requires at least one payload")
+	}
+	if len(inject.Locations) == 0 {
+		return nil, fmt.Errorf("inject requires at least one location")
+	}
+	if test.RateLimitCheck != nil {
+		return nil, fmt.Errorf("inject cannot be combined with rateLimitCheck")
+	}
+
+	if err := transform.Validate(test.Transforms); err != nil {
+		return nil, err
+	}
+
+	for _, location := range inject.Locations {
+		if _, _, err := parseLocation(location); err != nil {
+			return nil, err
+		}
+	}
+
+	tests := make([]config.Test, 0, len(inject.Payloads)*len(inject.Locations))
+	for i, payload := range inject.Payloads {
+		// Seeding from the test and payload keeps randomised transforms
+		// stable across runs and identical for every location
+		rng := rand.New(rand.NewSource(transform.Seed(test.Name, payload)))
+		encoded, err := transform.Apply(test.Transforms, payload, rng)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, location := range inject.Locations {
+			request, err := InjectPayload(test.Request, location, encoded)
+			if err != nil {
+				return nil, fmt.Errorf("location %s: %w", location, err)
+			}
+
+			expanded := test
+			expanded.Name = fmt.Sprintf("%s/%s/%d", test.Name, location, i+1)
+			expanded.Tags = append(append([]string(nil), test.Tags...), location)
+			expanded.Request = request
+			expanded.Inject = nil
+			expanded.Transforms = nil
+			expanded.Injection = &config.Injection{
+				Test:     test.Name,
+				Payload:  payload,
+				Location: location,
+				Template: test.Request,
+			}
+			if len(test.Transforms) > 0 {
+				expanded.Injection.Transforms = test.Transforms
+				expanded.Injection.Encoded = encoded
+			}
+			tests = append(tests, expanded)
+		}
+	}
+
+	return tests, nil
+}
+
+// parseLocation splits a location such as "query:q" into its kind and
+// name. "path" is the only location without a name.
+func parseLocation(location string) (kind, name string, err error) {
+	if location == "path" {
+		return "path", "", nil
+	}
+
+	kind, name, ok := strings.Cut(location, ":")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid inject location %q, expected <kind>:<name> or path", location)
+	}
+
+	switch kind {
+	case "query", "header", "cookie", "form":
+	case "json":
+		if !strings.HasPrefix(name, "$") {
+			return "", "", fmt.Errorf("invalid inject location %q, JSON paths start with $", location)
+		}
+	default:
+		return "", "", fmt.Errorf("unknown inject location kind %q in %q", kind, location)
+	}
+
+	return kind, name, nil
+}
+
+// InjectPayload returns a copy of request with payload placed at location.
+// Payloads are inserted as written, except for the few characters that
+// would otherwise break the request apart, which are percent-encoded.
+// Payloads that still cannot be sent are rejected: in the path or query, a
+// '%' that does not start a percent-encoded byte, such as the unicode
+// transform's %uXXXX; in a header or cookie, control characters such as
+// the null-byte transform's NUL.
+func InjectPayload(request config.Request, location, payload string) (config.Request, error) {
+	kind, name, err := parseLocation(location)
+	if err != nil {
+		return request, err
+	}
+
+	headers := make(map[string]string, len(request.Headers)+1)
+	for key, value := range request.Headers {
+		headers[key] = value
+	}
+	request.Headers = headers
+
+	switch kind {
+	case "path":
+		path, query, hasQuery := strings.Cut(request.Path, "?")
+		value := escapeInjected(payload, "?#")
+		if err := checkURLEscapes(value); err != nil {
+			return request, err
+		}
+		if strings.Contains(path, "{payload}") {
+			path = strings.ReplaceAll(path, "{payload}", value)
+		} else {
+			path = strings.TrimSuffix(path, "/") + "/" + value
+		}
+		request.Path = path
+		if hasQuery {
+			request.Path += "?" + query
+		}
+
+	case "query":
+		path, query, _ := strings.Cut(request.Path, "?")
+		value := escapeInjected(payload, "&#+")
+		if err := checkURLEscapes(value); err != nil {
+			return request, err
+		}
+		request.Path = path + "?" + setPair(query, name, value)
+
+	case "form":
+		request.Body = setPair(request.Body, name, escapeInjected(payload, "&#+"))
+		setDefaultHeader(headers, "Content-Type", "application/x-www-form-urlencoded")
+
+	case "header":
+		value := stripNewlines(payload)
+		if err := checkHeaderValue(value); err != nil {
+			return request, err
+		}
+		headers[headerKey(headers, name)] = value
+
+	case "cookie":
+		value := stripNewlines(payload)
+		if err := checkHeaderValue(value); err != nil {
+			return request, err
+		}
+		key := headerKey(headers, "Cookie")
+		headers[key] = setCookie(headers[key], name, value)
+
+	case "json":
+		body, err := setJSONField(request.Body, name, payload)
+		if err != nil {
+			return request, err
+		}
+		request.Body = body
+		setDefaultHeader(headers, "Content-Type", "application/json")
+	}
+
+	if len(headers) == 0 {
+		request.Headers = nil
+	}
+
+	return request, nil
+}
+
+// escapeInjected percent-encodes control characters, spaces, non-ASCII
+// bytes and any byte in extra. Everything else, including '%', is kept so
+// that pre-encoded payloads reach the target unchanged.
+func escapeInjected(s, extra string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(extra, c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// checkURLEscapes rejects a '%' that is not followed by two hex digits,
+// which would make the request URL invalid.
+func checkURLEscapes(s string) error {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && (i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2])) {
+			return fmt.Errorf("payload %q cannot be sent in a URL: %q does not start a percent-encoded byte, write a literal %% as %%25", s, s[i:min(i+3, len(s))])
+		}
+	}
+	return nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// checkHeaderValue rejects control characters other than tab, which HTTP
+// clients refuse to send in a header value.
+func checkHeaderValue(s string) error {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < ' ' && c != '\t') || c == 0x7f {
+			return fmt.Errorf("payload %q cannot be sent in a header: control character 0x%02X is not allowed", s, c)
+		}
+	}
+	return nil
+}
+
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// setPair sets name=value in an &-separated list, replacing the first pair
+// with that name or appending a new one. Other pairs keep their order and
+// encoding.
+func setPair(list, name, value string) string {
+	var pairs []string
+	if list != "" {
+		pairs = strings.Split(list, "&")
+	}
+
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if key == name {
+			pairs[i] = name + "=" + value
+			return strings.Join(pairs, "&")
+		}
+	}
+
+	return strings.Join(append(pairs, name+"="+value), "&")
+}
+
+func setCookie(header, name, value string) string {
+	var cookies []string
+	if header != "" {
+		cookies = strings.Split(header, "; ")
+	}
+
+	for i, cookie := range cookies {
+		key, _, _ := strings.Cut(cookie, "=")
+		if strings.TrimSpace(key) == name {
+			cookies[i] = name + "=" + value
+			return strings.Join(cookies, "; ")
+		}
+	}
+
+	return strings.Join(append(cookies, name+"="+value), "; ")
+}
+
+// headerKey returns the existing key for name, compared
+// case-insensitively, or name itself.
+func headerKey(headers map[string]string, name string) string {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+func setDefaultHeader(headers map[string]string, name, value string) {
+	key := headerKey(headers, name)
+	if _, ok := headers[key]; !ok {
+		headers[key] = value
+	}
+}
+
+// setJSONField sets the string value at path (e.g. $.user.name or
+// $.items[0].id) in the JSON document body, creating missing objects. An
+// empty body is treated as {}.
+func setJSONField(body, path, value string) (string, error) {
+	var doc interface{} = map[string]interface{}{}
+	if strings.TrimSpace(body) != "" {
+		decoder := json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return "", fmt.Errorf("request body is not valid JSON: %w", err)
+		}
+	}
+
+	steps, err := parseJSONPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	doc, err = setJSONStep(doc, steps, value)
+	if err != nil {
+		return "", fmt.Errorf("cannot set %s: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return "", fmt.Errorf("failed to encode JSON body: %w", err)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// parseJSONPath parses the subset of JSONPath used by inject locations:
+// dotted object keys and numeric array indexes.
+func parseJSONPath(path string) ([]interface{}, error) {
+	rest := strings.TrimPrefix(path, "$")
+	var steps []interface{}
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("invalid JSON path %q", path)
+			}
+			steps = append(steps, key)
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid array index in JSON path %q", path)
+			}
+			steps = append(steps, index)
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q", path)
+		}
+	}
+
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("JSON path %q does not name a field", path)
+	}
+
+	return steps, nil
+}
+
+func setJSONStep(node interface{}, steps []interface{}, value string) (interface{}, error) {
+	if len(steps) == 0 {
+		return value, nil
+	}
+
+	switch step := steps[0].(type) {
+	case string:
+		if node == nil {
+			node = map[string]interface{}{}
+		}
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%q is not inside an object", step)
+		}
+		child, err := setJSONStep(obj[step], steps[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		obj[step] = child
+		return obj, nil
+	case int:
+		arr, ok := node.([]interface{})
+		if !ok || step >= len(arr) {
+			return nil, fmt.Errorf("index %d is out of range", step)
+		}
+		child, err := setJSONStep(arr[step], steps[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		arr[step] = child
+		return arr, nil
+	}
+
+	return node, nil
+}
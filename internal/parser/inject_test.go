@@ -0,0 +1,377 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"wafguard/internal/core/config"
+)
+
+func TestParseYAMLExpandsInject(t *testing.T) {
+	yaml := `
+apiVersion: sentinel-test/v1
+kind: SentinelTest
+metadata:
+  name: inject
+spec:
+  target:
+    baseUrl: https://example.com
+  tests:
+    - name: plain
+      request:
+        method: GET
+        path: /
+      expected:
+        status: [200]
+    - name: sqli
+      tags: [sqli]
+      request:
+        method: POST
+        path: /search?page=1
+        headers:
+          Cookie: session=abc
+        body: '{"user": {"name": "bob", "age": 3}}'
+      expected:
+        blocked: true
+      inject:
+        payloads: ["' OR 1=1--", "<script>"]
+        locations: [query:q, header:User-Agent, cookie:session, json:$.user.name, path]
+`
+	suite, err := NewParser().ParseYAML([]byte(yaml))
+	if err != nil {
+		t.Fatalf("ParseYAML() error = %v", err)
+	}
+
+	if len(suite.Spec.Tests) != 1+2*5 {
+		t.Fatalf("got %d tests, want 11", len(suite.Spec.Tests))
+	}
+	if suite.Spec.Tests[0].Name != "plain" || suite.Spec.Tests[0].Injection != nil {
+		t.Errorf("tests without inject should be kept as they are")
+	}
+
+	first := suite.Spec.Tests[1]
+	if first.Name != "sqli/query:q/1" {
+		t.Errorf("Name = %q, want %q", first.Name, "sqli/query:q/1")
+	}
+	if !reflect.DeepEqual(first.Tags, []string{"sqli", "query:q"}) {
+		t.Errorf("Tags = %v", first.Tags)
+	}
+	if first.Inject != nil {
+		t.Error("expanded tests should not keep the inject block")
+	}
+	want := &config.Injection{
+		Test:     "sqli",
+		Payload:  "' OR 1=1--",
+		Location: "query:q",
+		Template: config.Request{
+			Method:  "POST",
+			Path:    "/search?page=1",
+			Headers: map[string]string{"Cookie": "session=abc"},
+			Body:    `{"user": {"name": "bob", "age": 3}}`,
+		},
+	}
+	if !reflect.DeepEqual(first.Injection, want) {
+		t.Errorf("Injection = %+v, want %+v", first.Injection, want)
+	}
+	if first.Request.Path != "/search?page=1&q='%20OR%201=1--" {
+		t.Errorf("Path = %q", first.Request.Path)
+	}
+	if first.Expected.Blocked == nil || !*first.Expected.Blocked {
+		t.Error("expanded tests should keep the expectation")
+	}
+
+	last := suite.Spec.Tests[10]
+	if last.Name != "sqli/path/2" || last.Request.Path != "/search/<script>?page=1" {
+		t.Errorf("last test = %s %s", last.Name, last.Request.Path)
+	}
+
+	// The template request must not be shared between expanded tests
+	header := suite.Spec.Tests[2]
+	if header.Request.Headers["User-Agent"] != "' OR 1=1--" {
+		t.Errorf("User-Agent = %q", header.Request.Headers["User-Agent"])
+	}
+	if _, ok := suite.Spec.Tests[1].Request.Headers["User-Agent"]; ok {
+		t.Error("header injection leaked into another test")
+	}
+}
+
+func TestParseYAMLInvalidInject(t *testing.T) {
+	tests := []struct {
+		name   string
+		inject string
+	}{
+		{"unknown kind", "{payloads: [x], locations: [body:x]}"},
+		{"missing name", "{payloads: [x], locations: ['query:']}"},
+		{"bad json path", "{payloads: [x], locations: ['json:user']}"},
+		{"no payloads", "{payloads: [], locations: [path]}"},
+		{"no locations", "{payloads: [x]}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+apiVersion: sentinel-test/v1
+kind: SentinelTest
+metadata:
+  name: inject
+spec:
+  target:
+    baseUrl: https://example.com
+  tests:
+    - name: t
+      request:
+        method: GET
+        path: /
+      expected:
+        status: [403]
+      inject: ` + tt.inject
+
+			if _, err := NewParser().ParseYAML([]byte(yaml)); err == nil {
+				t.Error("ParseYAML() expected error")
+			}
+		})
+	}
+}
+
+func TestInjectPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  config.Request
+		location string
+		payload  string
+		want     config.Request
+		wantErr  bool
+	}{
+		{
+			name:     "query appends parameter",
+			request:  config.Request{Method: "GET", Path: "/"},
+			location: "query:q",
+			payload:  "a b&c#d+e%27",
+			want:     config.Request{Method: "GET", Path: "/?q=a%20b%26c%23d%2Be%27"},
+		},
+		{
+			name:     "query replaces parameter",
+			request:  config.Request{Method: "GET", Path: "/s?q=test&page=2"},
+			location: "query:q",
+			payload:  "<x>",
+			want:     config.Request{Method: "GET", Path: "/s?q=<x>&page=2"},
+		},
+		{
+			name:     "path placeholder",
+			request:  config.Request{Method: "GET", Path: "/users/{payload}/profile?x=1"},
+			location: "path",
+			payload:  "../etc/passwd?",
+			want:     config.Request{Method: "GET", Path: "/users/../etc/passwd%3F/profile?x=1"},
+		},
+		{
+			name:     "header strips newlines",
+			request:  config.Request{Method: "GET", Path: "/", Headers: map[string]string{"user-agent": "curl"}},
+			location: "header:User-Agent",
+			payload:  "x\r\nInjected: 1",
+			want:     config.Request{Method: "GET", Path: "/", Headers: map[string]string{"user-agent": "xInjected: 1"}},
+		},
+		{
+			name:     "cookie replaces value",
+			request:  config.Request{Method: "GET", Path: "/", Headers: map[string]string{"Cookie": "a=1; session=abc; b=2"}},
+			location: "cookie:session",
+			payload:  "' OR 1=1",
+			want:     config.Request{Method: "GET", Path: "/", Headers: map[string]string{"Cookie": "a=1; session=' OR 1=1; b=2"}},
+		},
+		{
+			name:     "cookie added",
+			request:  config.Request{Method: "GET", Path: "/"},
+			location: "cookie:session",
+			payload:  "x",
+			want:     config.Request{Method: "GET", Path: "/", Headers: map[string]string{"Cookie": "session=x"}},
+		},
+		{
+			name:     "form field",
+			request:  config.Request{Method: "POST", Path: "/", Body: "user=bob&comment=hi"},
+			location: "form:comment",
+			payload:  "<b onmouseover=x>",
+			want: config.Request{Method: "POST", Path: "/", Body: "user=bob&comment=<b%20onmouseover=x>",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"}},
+		},
+		{
+			name:     "json nested field created",
+			request:  config.Request{Method: "POST", Path: "/", Body: `{"id": 12345678901234567890}`},
+			location: "json:$.user.name",
+			payload:  "<script>",
+			want: config.Request{Method: "POST", Path: "/", Body: `{"id":12345678901234567890,"user":{"name":"<script>"}}`,
+				Headers: map[string]string{"Content-Type": "application/json"}},
+		},
+		{
+			name:     "json array element",
+			request:  config.Request{Method: "POST", Path: "/", Body: `{"items": [{"id": "a"}]}`, Headers: map[string]string{"content-type": "application/vnd.api+json"}},
+			location: "json:$.items[0].id",
+			payload:  "x",
+			want: config.Request{Method: "POST", Path: "/", Body: `{"items":[{"id":"x"}]}`,
+				Headers: map[string]string{"content-type": "application/vnd.api+json"}},
+		},
+		{
+			name:     "query rejects invalid escape",
+			request:  config.Request{Method: "GET", Path: "/"},
+			location: "query:q",
+			payload:  "100%",
+			wantErr:  true,
+		},
+		{
+			name:     "path rejects unicode escape",
+			request:  config.Request{Method: "GET", Path: "/"},
+			location: "path",
+			payload:  "%u003Cscript",
+			wantErr:  true,
+		},
+		{
+			name:     "header rejects null byte",
+			request:  config.Request{Method: "GET", Path: "/"},
+			location: "header:X-Id",
+			payload:  "\x00' OR 1=1",
+			wantErr:  true,
+		},
+		{
+			name:     "cookie rejects control character",
+			request:  config.Request{Method: "GET", Path: "/"},
+			location: "cookie:session",
+			payload:  "a\vb",
+			wantErr:  true,
+		},
+		{
+			name:     "header keeps tab",
+			request:  config.Request{Method: "GET", Path: "/"},
+			location: "header:X-Id",
+			payload:  "a\tb",
+			want:     config.Request{Method: "GET", Path: "/", Headers: map[string]string{"X-Id": "a\tb"}},
+		},
+		{
+			name:     "json array out of range",
+			request:  config.Request{Method: "POST", Path: "/", Body: `{"items": []}`},
+			location: "json:$.items[0]",
+			payload:  "x",
+			wantErr:  true,
+		},
+		{
+			name:     "json invalid body",
+			request:  config.Request{Method: "POST", Path: "/", Body: `not json`},
+			location: "json:$.a",
+			payload:  "x",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InjectPayload(tt.request, tt.location, tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("InjectPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("InjectPayload() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInjectPayloadDoesNotModifyTemplate(t *testing.T) {
+	template := config.Request{Method: "GET", Path: "/", Headers: map[string]string{"Cookie": "a=1"}}
+
+	if _, err := InjectPayload(template, "cookie:a", "x"); err != nil {
+		t.Fatal(err)
+	}
+
+	if template.Headers["Cookie"] != "a=1" {
+		t.Errorf("template headers were modified: %v", template.Headers)
+	}
+	if !strings.HasPrefix(template.Path, "/") {
+		t.Errorf("template path was modified: %q", template.Path)
+	}
+}
+
+func TestParseYAMLInjectTransforms(t *testing.T) {
+	yaml := `
+apiVersion: sentinel-test/v1
+kind: SentinelTest
+metadata:
+  name: inject
+spec:
+  target:
+    baseUrl: https://example.com
+  tests:
+    - name: sqli
+      request:
+        method: GET
+        path: /
+      expected:
+        blocked: true
+      transforms: [sql-comments, url]
+      inject:
+        payloads: ["1 OR 1"]
+        locations: [query:q, header:X-Id]
+`
+	suite, err := NewParser().ParseYAML([]byte(yaml))
+	if err != nil {
+		t.Fatalf("ParseYAML() error = %v", err)
+	}
+
+	query := suite.Spec.Tests[0]
+	if query.Request.Path != "/?q=1%2F%2A%2A%2FOR%2F%2A%2A%2F1" {
+		t.Errorf("Path = %q", query.Request.Path)
+	}
+	if query.Transforms != nil {
+		t.Error("expanded tests should not keep the transforms")
+	}
+	want := &config.Injection{
+		Test:       "sqli",
+		Payload:    "1 OR 1",
+		Location:   "query:q",
+		Transforms: []string{"sql-comments", "url"},
+		Encoded:    "1%2F%2A%2A%2FOR%2F%2A%2A%2F1",
+		Template:   config.Request{Method: "GET", Path: "/"},
+	}
+	if !reflect.DeepEqual(query.Injection, want) {
+		t.Errorf("Injection = %+v, want %+v", query.Injection, want)
+	}
+	if got := suite.Spec.Tests[1].Request.Headers["X-Id"]; got != want.Encoded {
+		t.Errorf("X-Id = %q, want %q", got, want.Encoded)
+	}
+}
+
+func TestParseYAMLInvalidTransforms(t *testing.T) {
+	tests := []struct {
+		name string
+		test string
+	}{
+		{"unknown transform", "transforms: [rot13]\n      inject: {payloads: [x], locations: [path]}"},
+		{"without inject", "transforms: [url]"},
+		{"unicode in query", "transforms: [unicode]\n      inject: {payloads: [\"<x>\"], locations: [\"query:q\"]}"},
+		{"null byte in header", "transforms: [null-byte]\n      inject: {payloads: [x], locations: [\"header:X-Id\"]}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := `
+apiVersion: sentinel-test/v1
+kind: SentinelTest
+metadata:
+  name: inject
+spec:
+  target:
+    baseUrl: https://example.com
+  tests:
+    - name: t
+      request:
+        method: GET
+        path: /
+      expected:
+        status: [403]
+      ` + tt.test
+
+			if _, err := NewParser().ParseYAML([]byte(yaml)); err == nil {
+				t.Error("ParseYAML() expected error")
+			}
+		})
+	}
+}
@@ -40,9 +40,31 @@ func (p *Parser) ParseYAML(data []byte) (*config.SentinelTest, error) {
 		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
+	if err := p.validateRetries(&sentinelTest); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := expandInjections(&sentinelTest); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
 	return &sentinelTest, nil
 }
 
+// validateRetries checks the retry block of each test, which struct
+// validation does not reach inside the tests list.
+func (p *Parser) validateRetries(sentinelTest *config.SentinelTest) error {
+	for _, test := range sentinelTest.Spec.Tests {
+		if test.Retry == nil {
+			continue
+		}
+		if err := p.validator.Struct(test.Retry); err != nil {
+			return fmt.Errorf("test %s: invalid retry: %w", test.Name, err)
+		}
+	}
+	return nil
+}
+
 func (p *Parser) ParseDirectory(dir string) ([]*config.SentinelTest, error) {
 	var tests []*config.SentinelTest
 	
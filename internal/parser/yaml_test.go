@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -149,6 +150,35 @@ spec:
 	}
 }
 
+func TestParseYAMLValidatesTestRetry(t *testing.T) {
+	yaml := `
+apiVersion: waf-test/v1
+kind: SentinelTest
+metadata:
+  name: test
+spec:
+  target:
+    baseUrl: https://example.com
+  tests:
+    - name: test1
+      request:
+        method: GET
+        path: /
+      expected:
+        status: [200]
+      retry:
+        retries: %d
+`
+	parser := NewParser()
+
+	if _, err := parser.ParseYAML([]byte(fmt.Sprintf(yaml, -5))); err == nil {
+		t.Error("ParseYAML() accepted negative retries")
+	}
+	if _, err := parser.ParseYAML([]byte(fmt.Sprintf(yaml, 2))); err != nil {
+		t.Errorf("ParseYAML() error = %v", err)
+	}
+}
+
 func TestParseFile(t *testing.T) {
 	parser := NewParser()
 
@@ -0,0 +1,123 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"wafguard/internal/core/config"
+)
+
+// CoverageGrid summarises the tests expanded from one inject block, with
+// one row per payload and one column per location. Each cell holds the
+// test status, or "" if that combination has no report.
+type CoverageGrid struct {
+	Test      string     `json:"test"`
+	Payloads  []string   `json:"payloads"`
+	Locations []string   `json:"locations"`
+	Results   [][]string `json:"results"`
+}
+
+// maxGridPayload is the width payloads are truncated to in text grids.
+const maxGridPayload = 40
+
+// buildCoverage groups injected test reports by their originating test.
+// Payloads and locations keep the order in which they were first seen.
+func buildCoverage(testReports []TestReport) []CoverageGrid {
+	var grids []CoverageGrid
+	index := make(map[string]int)
+
+	type cell struct {
+		payload, location, status string
+	}
+	cells := make(map[string][]cell)
+
+	for _, report := range testReports {
+		injection := report.Injection
+		if injection == nil {
+			continue
+		}
+
+		i, ok := index[injection.Test]
+		if !ok {
+			i = len(grids)
+			index[injection.Test] = i
+			grids = append(grids, CoverageGrid{Test: injection.Test})
+		}
+
+		grid := &grids[i]
+		if !containsString(grid.Payloads, injection.Payload) {
+			grid.Payloads = append(grid.Payloads, injection.Payload)
+		}
+		if !containsString(grid.Locations, injection.Location) {
+			grid.Locations = append(grid.Locations, injection.Location)
+		}
+		cells[injection.Test] = append(cells[injection.Test], cell{injection.Payload, injection.Location, report.Status})
+	}
+
+	for i := range grids {
+		grid := &grids[i]
+		grid.Results = make([][]string, len(grid.Payloads))
+		for row := range grid.Results {
+			grid.Results[row] = make([]string, len(grid.Locations))
+		}
+		for _, c := range cells[grid.Test] {
+			grid.Results[indexOf(grid.Payloads, c.payload)][indexOf(grid.Locations, c.location)] = c.status
+		}
+	}
+
+	return grids
+}
+
+func (r *Reporter) printTextInjection(injection *config.Injection) {
+	fmt.Printf("Injection: %s at %s\n", displayPayload(injection.Payload), injection.Location)
+	if len(injection.Transforms) > 0 {
+		fmt.Printf("Transforms: %s -> %s\n", strings.Join(injection.Transforms, " | "), displayPayload(injection.Encoded))
+	}
+}
+
+func (r *Reporter) printTextCoverage(grids []CoverageGrid) {
+	for _, grid := range grids {
+		fmt.Printf("Coverage: %s\n", grid.Test)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "  PAYLOAD\t%s\n", strings.Join(grid.Locations, "\t"))
+		for row, payload := range grid.Payloads {
+			statuses := make([]string, len(grid.Locations))
+			for col, status := range grid.Results[row] {
+				if status == "" {
+					status = "-"
+				}
+				statuses[col] = status
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", displayPayload(payload), strings.Join(statuses, "\t"))
+		}
+		_ = w.Flush()
+
+		fmt.Println("====================================")
+	}
+}
+
+// displayPayload makes a payload safe for a single table cell.
+func displayPayload(payload string) string {
+	quoted := strconv.Quote(payload)
+	quoted = quoted[1 : len(quoted)-1]
+	if len(quoted) > maxGridPayload {
+		quoted = quoted[:maxGridPayload-3] + "..."
+	}
+	return quoted
+}
+
+func containsString(list []string, s string) bool {
+	return indexOf(list, s) >= 0
+}
+
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
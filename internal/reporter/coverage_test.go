@@ -0,0 +1,60 @@
+package reporter
+
+import (
+	"reflect"
+	"testing"
+	"wafguard/internal/core/config"
+)
+
+func injected(payload, location, status string) TestReport {
+	return TestReport{
+		TestName:  "sqli/" + location,
+		Status:    status,
+		Injection: &config.Injection{Test: "sqli", Payload: payload, Location: location},
+	}
+}
+
+func TestBuildCoverage(t *testing.T) {
+	reports := []TestReport{
+		{TestName: "plain", Status: StatusPass},
+		injected("' OR 1=1", "query:q", StatusPass),
+		injected("' OR 1=1", "header:X", StatusFail),
+		injected("<script>", "query:q", StatusFail),
+	}
+
+	grids := buildCoverage(reports)
+	if len(grids) != 1 {
+		t.Fatalf("got %d grids, want 1", len(grids))
+	}
+
+	want := CoverageGrid{
+		Test:      "sqli",
+		Payloads:  []string{"' OR 1=1", "<script>"},
+		Locations: []string{"query:q", "header:X"},
+		Results: [][]string{
+			{StatusPass, StatusFail},
+			{StatusFail, ""},
+		},
+	}
+	if !reflect.DeepEqual(grids[0], want) {
+		t.Errorf("buildCoverage() = %+v, want %+v", grids[0], want)
+	}
+}
+
+func TestBuildCoverageWithoutInjection(t *testing.T) {
+	grids := buildCoverage([]TestReport{{TestName: "plain", Status: StatusPass}})
+	if grids != nil {
+		t.Errorf("buildCoverage() = %+v, want nil", grids)
+	}
+}
+
+func TestDisplayPayload(t *testing.T) {
+	if got := displayPayload("a\nb"); got != `a\nb` {
+		t.Errorf("displayPayload() = %q", got)
+	}
+
+	long := displayPayload(string(make([]byte, 100)))
+	if len(long) != maxGridPayload {
+		t.Errorf("displayPayload() length = %d, want %d", len(long), maxGridPayload)
+	}
+}
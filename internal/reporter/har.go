@@ -0,0 +1,262 @@
+package reporter
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"wafguard/internal/executor"
+)
+
+// HAR 1.2 structures, limited to the fields wafguard can fill in. See
+// http://www.softwareishard.com/blog/har-12-spec/.
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harTimings struct {
+	DNS     float64 `json:"dns,omitempty"`
+	Connect float64 `json:"connect,omitempty"`
+	SSL     float64 `json:"ssl,omitempty"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// buildHAR converts every executed request in the suite into a HAR entry:
+// each attempt of a retried test and each request of a rate-limit check
+// gets its own. Skipped tests and requests that got no response have no
+// entry.
+func buildHAR(report *SuiteReport) *harDocument {
+	doc := &harDocument{
+		Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{Name: "wafguard", Version: "1.0"},
+			Entries: []harEntry{},
+		},
+	}
+
+	for i := range report.Tests {
+		test := &report.Tests[i]
+		if test.Request == nil {
+			continue
+		}
+
+		responses := testResponses(test)
+		for n, resp := range responses {
+			comment := fmt.Sprintf("%s: %s", test.TestName, test.Status)
+			if len(responses) > 1 {
+				comment += fmt.Sprintf(" (%s %d of %d)", exchangeKind(test), n+1, len(responses))
+			}
+			doc.Log.Entries = append(doc.Log.Entries, harEntryFor(test, resp, comment))
+		}
+	}
+
+	return doc
+}
+
+// testResponses returns every response received while running test, in
+// the order the requests were sent.
+func testResponses(test *TestReport) []*executor.Response {
+	var responses []*executor.Response
+	switch {
+	case test.RateLimit != nil:
+		responses = test.RateLimit.Responses
+	case len(test.Attempts) > 0:
+		for _, attempt := range test.Attempts {
+			if attempt.Response != nil {
+				responses = append(responses, attempt.Response)
+			}
+		}
+	}
+	if len(responses) == 0 && test.Response != nil {
+		responses = []*executor.Response{test.Response}
+	}
+	return responses
+}
+
+func exchangeKind(test *TestReport) string {
+	if test.RateLimit != nil {
+		return "request"
+	}
+	return "attempt"
+}
+
+func harEntryFor(test *TestReport, resp *executor.Response, comment string) harEntry {
+	req := test.Request
+	millis := durationMillis(resp.Duration)
+
+	httpVersion := resp.Proto
+	if httpVersion == "" {
+		httpVersion = "HTTP/1.1"
+	}
+
+	request := harRequest{
+		Method:      req.Method,
+		URL:         resp.URL,
+		HTTPVersion: httpVersion,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(req.Headers),
+		QueryString: []harNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(req.Body),
+	}
+	if request.URL == "" {
+		request.URL = req.Path
+	}
+
+	if u, err := url.Parse(request.URL); err == nil {
+		for name, values := range u.Query() {
+			for _, value := range values {
+				request.QueryString = append(request.QueryString, harNameValue{Name: name, Value: value})
+			}
+		}
+		sort.Slice(request.QueryString, func(i, j int) bool {
+			return request.QueryString[i].Name < request.QueryString[j].Name
+		})
+	}
+
+	if req.Body != "" {
+		request.PostData = &harPostData{
+			MimeType: headerValue(req.Headers, "Content-Type"),
+			Text:     req.Body,
+		}
+	}
+
+	if cookie := headerValue(req.Headers, "Cookie"); cookie != "" {
+		parsed := &http.Request{Header: http.Header{"Cookie": {cookie}}}
+		for _, c := range parsed.Cookies() {
+			request.Cookies = append(request.Cookies, harNameValue{Name: c.Name, Value: c.Value})
+		}
+	}
+
+	response := harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: httpVersion,
+		Cookies:     []harNameValue{},
+		Headers:     harHeaders(resp.Headers),
+		Content: harContent{
+			Size:     len(resp.Body),
+			MimeType: headerValue(resp.Headers, "Content-Type"),
+			Text:     resp.Body,
+		},
+		RedirectURL: headerValue(resp.Headers, "Location"),
+		HeadersSize: -1,
+		BodySize:    len(resp.Body),
+	}
+
+	timings := harTimings{Wait: millis}
+	if t := resp.Timings; t != nil {
+		// HAR counts the TLS handshake as part of connect
+		timings = harTimings{
+			DNS:     durationMillis(t.DNS),
+			Connect: durationMillis(t.Connect + t.TLS),
+			SSL:     durationMillis(t.TLS),
+			Wait:    durationMillis(t.Wait()),
+			Receive: durationMillis(t.Transfer),
+		}
+	}
+
+	started := resp.Started
+	if started.IsZero() {
+		started = test.Timestamp.Add(-resp.Duration)
+	}
+
+	return harEntry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Time:            millis,
+		Request:         request,
+		Response:        response,
+		Timings:         timings,
+		Comment:         comment,
+	}
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func harHeaders(headers map[string]string) []harNameValue {
+	result := make([]harNameValue, 0, len(headers))
+	for name, value := range headers {
+		result = append(result, harNameValue{Name: name, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,227 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/validator"
+)
+
+func TestBuildHAR(t *testing.T) {
+	rep := NewReporter("har", "")
+	passed := &validator.ValidationResult{Passed: true}
+
+	executed := rep.GenerateTestReport(
+		"sqli",
+		&config.Request{
+			Method:  "POST",
+			Path:    "/login?next=/home",
+			Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded", "Cookie": "session=abc; theme=dark"},
+			Body:    "user=' OR 1=1--",
+		},
+		&executor.Response{
+			URL:        "https://example.com/login?next=/home",
+			Proto:      "HTTP/2.0",
+			StatusCode: 403,
+			Headers:    map[string]string{"Content-Type": "text/html"},
+			Body:       "blocked",
+			Duration:   250 * time.Millisecond,
+		},
+		passed,
+		250*time.Millisecond,
+	)
+	skipped := rep.GenerateSkippedTestReport("skipped", &config.Request{Method: "GET", Path: "/"}, "run interrupted")
+
+	suite := rep.GenerateSuiteReport("All Tests", []TestReport{*executed, *skipped}, time.Second)
+	doc := buildHAR(suite)
+
+	if doc.Log.Version != "1.2" {
+		t.Errorf("Version = %q, want 1.2", doc.Log.Version)
+	}
+	if len(doc.Log.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1 (skipped tests have no traffic)", len(doc.Log.Entries))
+	}
+
+	entry := doc.Log.Entries[0]
+	if entry.Request.URL != "https://example.com/login?next=/home" || entry.Request.Method != "POST" {
+		t.Errorf("Request = %s %s", entry.Request.Method, entry.Request.URL)
+	}
+	if entry.Request.HTTPVersion != "HTTP/2.0" {
+		t.Errorf("HTTPVersion = %q", entry.Request.HTTPVersion)
+	}
+	if len(entry.Request.QueryString) != 1 || entry.Request.QueryString[0].Value != "/home" {
+		t.Errorf("QueryString = %v", entry.Request.QueryString)
+	}
+	if len(entry.Request.Cookies) != 2 {
+		t.Errorf("Cookies = %v, want 2", entry.Request.Cookies)
+	}
+	if entry.Request.PostData == nil || entry.Request.PostData.Text != "user=' OR 1=1--" {
+		t.Errorf("PostData = %+v", entry.Request.PostData)
+	}
+	if entry.Response.Status != 403 || entry.Response.StatusText != "Forbidden" {
+		t.Errorf("Response status = %d %q", entry.Response.Status, entry.Response.StatusText)
+	}
+	if entry.Response.Content.Text != "blocked" || entry.Response.Content.MimeType != "text/html" {
+		t.Errorf("Content = %+v", entry.Response.Content)
+	}
+	if entry.Time != 250 {
+		t.Errorf("Time = %v, want 250", entry.Time)
+	}
+	if entry.Comment != "sqli: PASS" {
+		t.Errorf("Comment = %q", entry.Comment)
+	}
+}
+
+func TestBuildHAREveryRequest(t *testing.T) {
+	rep := NewReporter("har", "")
+	request := &config.Request{Method: "GET", Path: "/"}
+	response := func(status int) *executor.Response {
+		return &executor.Response{URL: "https://example.com/", StatusCode: status, Started: time.Now(), Duration: time.Millisecond}
+	}
+
+	retried := rep.GenerateTestReport("retried", request, response(200), &validator.ValidationResult{Passed: true}, time.Second)
+	retried.RecordAttempts([]Attempt{
+		{Number: 1, StatusCode: 503, Response: response(503)},
+		{Number: 2, Error: "connection reset"},
+		{Number: 3, StatusCode: 200, Passed: true, Response: retried.Response},
+	})
+
+	burst := []*executor.Response{response(200), response(200), response(429)}
+	checked := rep.GenerateTestReport("rate-limit", request, burst[2], &validator.ValidationResult{Passed: true}, time.Second)
+	checked.RateLimit = &executor.RateLimitResult{RequestsSent: 3, BlockedAt: 3, Response: burst[2], Responses: burst}
+
+	doc := buildHAR(rep.GenerateSuiteReport("All Tests", []TestReport{*retried, *checked}, time.Second))
+
+	want := []struct {
+		status  int
+		comment string
+	}{
+		{503, "retried: FLAKY (attempt 1 of 2)"},
+		{200, "retried: FLAKY (attempt 2 of 2)"},
+		{200, "rate-limit: PASS (request 1 of 3)"},
+		{200, "rate-limit: PASS (request 2 of 3)"},
+		{429, "rate-limit: PASS (request 3 of 3)"},
+	}
+	if len(doc.Log.Entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(doc.Log.Entries), len(want))
+	}
+	for i, w := range want {
+		entry := doc.Log.Entries[i]
+		if entry.Response.Status != w.status || entry.Comment != w.comment {
+			t.Errorf("entry %d = %d %q, want %d %q", i, entry.Response.Status, entry.Comment, w.status, w.comment)
+		}
+	}
+}
+
+func TestSaveSuiteReportHAR(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "run.har")
+	rep := NewReporter("har", output)
+
+	report := rep.GenerateTestReport(
+		"test",
+		&config.Request{Method: "GET", Path: "/"},
+		&executor.Response{URL: "https://example.com/", StatusCode: 200},
+		&validator.ValidationResult{Passed: true},
+		time.Millisecond,
+	)
+	suite := rep.GenerateSuiteReport("All Tests", []TestReport{*report}, time.Millisecond)
+
+	if err := rep.SaveSuiteReport(suite); err != nil {
+		t.Fatalf("SaveSuiteReport() error = %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Log struct {
+			Creator struct {
+				Name string `json:"name"`
+			} `json:"creator"`
+			Entries []map[string]interface{} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("saved HAR is not valid JSON: %v", err)
+	}
+	if doc.Log.Creator.Name != "wafguard" || len(doc.Log.Entries) != 1 {
+		t.Errorf("unexpected HAR document: %s", data)
+	}
+}
+
+func TestPrintTestReportHAR(t *testing.T) {
+	rep := NewReporter("har", "")
+	report := rep.GenerateTestReport(
+		"test",
+		&config.Request{Method: "GET", Path: "/"},
+		&executor.Response{URL: "https://example.com/", StatusCode: 200},
+		&validator.ValidationResult{Passed: true},
+		time.Millisecond,
+	)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	rep.PrintTestReport(report)
+	os.Stdout = stdout
+	_ = w.Close()
+
+	// Per-test output would corrupt the HAR document on stdout
+	if out, _ := io.ReadAll(r); len(out) != 0 {
+		t.Errorf("PrintTestReport() wrote %q in har format", out)
+	}
+}
+
+func TestBuildHARTimings(t *testing.T) {
+	rep := NewReporter("har", "")
+	report := rep.GenerateTestReport(
+		"timed",
+		&config.Request{Method: "GET", Path: "/"},
+		&executor.Response{
+			URL:        "https://example.com/",
+			StatusCode: 200,
+			Duration:   100 * time.Millisecond,
+			Timings: &executor.Timings{
+				DNS:      10 * time.Millisecond,
+				Connect:  20 * time.Millisecond,
+				TLS:      30 * time.Millisecond,
+				TTFB:     90 * time.Millisecond,
+				Transfer: 10 * time.Millisecond,
+			},
+		},
+		&validator.ValidationResult{Passed: true},
+		100*time.Millisecond,
+	)
+
+	doc := buildHAR(rep.GenerateSuiteReport("All Tests", []TestReport{*report}, time.Second))
+	want := harTimings{DNS: 10, Connect: 50, SSL: 30, Wait: 30, Receive: 10}
+	if got := doc.Log.Entries[0].Timings; got != want {
+		t.Errorf("Timings = %+v, want %+v", got, want)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Response struct {
+			Timings map[string]any
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Response.Timings["ttfb"] != float64(90*time.Millisecond) {
+		t.Errorf("JSON timings = %v", decoded.Response.Timings)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 	"wafguard/internal/logger"
 	"wafguard/internal/core/config"
@@ -13,6 +15,24 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	StatusPass    = "PASS"
+	StatusFail    = "FAIL"
+	StatusFlaky   = "FLAKY"
+	StatusSkipped = "SKIPPED"
+)
+
+// Attempt records a single execution of a test when retries are enabled.
+type Attempt struct {
+	Number     int           `json:"number"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Passed     bool          `json:"passed"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	// Response is the response to this attempt, if one was received.
+	Response *executor.Response `json:"-"`
+}
+
 type TestReport struct {
 	TestName         string                   `json:"test_name"`
 	Status           string                   `json:"status"`
@@ -20,17 +40,27 @@ type TestReport struct {
 	Request          *config.Request          `json:"request"`
 	Response         *executor.Response       `json:"response"`
 	ValidationResult *validator.ValidationResult `json:"validation_result"`
+	Attempts         []Attempt                `json:"attempts,omitempty"`
+	RateLimit        *executor.RateLimitResult `json:"rate_limit,omitempty"`
+	SkipReason       string                   `json:"skip_reason,omitempty"`
+	Injection        *config.Injection        `json:"injection,omitempty"`
+	Dump             *executor.Dump           `json:"dump,omitempty"`
 	Timestamp        time.Time                `json:"timestamp"`
 }
 
 type SuiteReport struct {
-	SuiteName    string        `json:"suite_name"`
-	TotalTests   int           `json:"total_tests"`
-	PassedTests  int           `json:"passed_tests"`
-	FailedTests  int           `json:"failed_tests"`
-	Duration     time.Duration `json:"duration"`
-	Tests        []TestReport  `json:"tests"`
-	Timestamp    time.Time     `json:"timestamp"`
+	SuiteName    string         `json:"suite_name"`
+	TotalTests   int            `json:"total_tests"`
+	PassedTests  int            `json:"passed_tests"`
+	FailedTests  int            `json:"failed_tests"`
+	FlakyTests   int            `json:"flaky_tests"`
+	SkippedTests int            `json:"skipped_tests"`
+	Interrupted  bool           `json:"interrupted"`
+	StoppedEarly bool           `json:"stopped_early"`
+	Duration     time.Duration  `json:"duration"`
+	Tests        []TestReport   `json:"tests"`
+	Coverage     []CoverageGrid `json:"coverage,omitempty"`
+	Timestamp    time.Time      `json:"timestamp"`
 }
 
 type Reporter struct {
@@ -46,9 +76,9 @@ func NewReporter(format, output string) *Reporter {
 }
 
 func (r *Reporter) GenerateTestReport(testName string, request *config.Request, response *executor.Response, validation *validator.ValidationResult, duration time.Duration) *TestReport {
-	status := "PASS"
+	status := StatusPass
 	if !validation.Passed {
-		status = "FAIL"
+		status = StatusFail
 	}
 
 	return &TestReport{
@@ -62,26 +92,75 @@ func (r *Reporter) GenerateTestReport(testName string, request *config.Request,
 	}
 }
 
+// RecordAttempts attaches the attempt history to the report. A test that
+// passed only after a retry is marked as flaky.
+func (t *TestReport) RecordAttempts(attempts []Attempt) {
+	t.Attempts = attempts
+	if t.Status == StatusPass && len(attempts) > 1 {
+		t.Status = StatusFlaky
+	}
+}
+
+// GenerateSkippedTestReport builds a report for a test that was never
+// executed, e.g. because the run was interrupted before it started.
+func (r *Reporter) GenerateSkippedTestReport(testName string, request *config.Request, reason string) *TestReport {
+	return &TestReport{
+		TestName:   testName,
+		Status:     StatusSkipped,
+		Request:    request,
+		SkipReason: reason,
+		Timestamp:  time.Now(),
+	}
+}
+
+// GenerateErrorTestReport builds a failed report for a test whose request
+// could not be executed, e.g. because the connection was refused.
+func (r *Reporter) GenerateErrorTestReport(testName string, request *config.Request, err error, duration time.Duration) *TestReport {
+	return &TestReport{
+		TestName: testName,
+		Status:   StatusFail,
+		Duration: duration,
+		Request:  request,
+		ValidationResult: &validator.ValidationResult{
+			Passed: false,
+			Errors: []string{fmt.Sprintf("Request failed: %v", err)},
+		},
+		Timestamp: time.Now(),
+	}
+}
+
 func (r *Reporter) GenerateSuiteReport(suiteName string, testReports []TestReport, totalDuration time.Duration) *SuiteReport {
 	passed := 0
 	failed := 0
+	flaky := 0
+	skipped := 0
 
 	for _, report := range testReports {
-		if report.Status == "PASS" {
+		switch report.Status {
+		case StatusPass:
 			passed++
-		} else {
+		case StatusFlaky:
+			// Flaky tests did pass eventually, so they count towards passed
+			passed++
+			flaky++
+		case StatusSkipped:
+			skipped++
+		default:
 			failed++
 		}
 	}
 
 	return &SuiteReport{
-		SuiteName:   suiteName,
-		TotalTests:  len(testReports),
-		PassedTests: passed,
-		FailedTests: failed,
-		Duration:    totalDuration,
-		Tests:       testReports,
-		Timestamp:   time.Now(),
+		SuiteName:    suiteName,
+		TotalTests:   len(testReports),
+		PassedTests:  passed,
+		FailedTests:  failed,
+		FlakyTests:   flaky,
+		SkippedTests: skipped,
+		Duration:     totalDuration,
+		Tests:        testReports,
+		Coverage:     buildCoverage(testReports),
+		Timestamp:    time.Now(),
 	}
 }
 
@@ -89,6 +168,8 @@ func (r *Reporter) PrintTestReport(report *TestReport) {
 	switch r.format {
 	case "json":
 		r.printJSONTestReport(report)
+	case "har":
+		// A HAR log is a single document, written with the suite report
 	case "text":
 		r.printTextTestReport(report)
 	default:
@@ -100,6 +181,8 @@ func (r *Reporter) PrintSuiteReport(report *SuiteReport) {
 	switch r.format {
 	case "json":
 		r.printJSONSuiteReport(report)
+	case "har":
+		r.printHARSuiteReport(report)
 	case "text":
 		r.printTextSuiteReport(report)
 	default:
@@ -118,6 +201,8 @@ func (r *Reporter) SaveSuiteReport(report *SuiteReport) error {
 	switch r.format {
 	case "json":
 		data, err = json.MarshalIndent(report, "", "  ")
+	case "har":
+		data, err = json.MarshalIndent(buildHAR(report), "", "  ")
 	default:
 		data, err = json.MarshalIndent(report, "", "  ")
 	}
@@ -152,8 +237,52 @@ func (r *Reporter) printTextTestReport(report *TestReport) {
 	fmt.Printf("Status: %s\n", report.Status)
 	fmt.Printf("Duration: %s\n", report.Duration)
 	fmt.Printf("Request: %s %s\n", report.Request.Method, report.Request.Path)
-	fmt.Printf("Response Status: %d\n", report.Response.StatusCode)
+	if report.Injection != nil {
+		r.printTextInjection(report.Injection)
+	}
+
+	if report.Status == StatusSkipped {
+		fmt.Printf("Skipped: %s\n", report.SkipReason)
+		fmt.Println("---")
+		return
+	}
+
+	// Tests whose request failed have no response
+	if report.Response != nil {
+		fmt.Printf("Response Status: %d\n", report.Response.StatusCode)
+		if report.Response.Truncated {
+			fmt.Printf("Response Body: truncated to %d bytes\n", len(report.Response.Body))
+		}
+		if timings := report.Response.Timings; timings != nil {
+			fmt.Printf("Timings: dns %s, connect %s, tls %s, ttfb %s, transfer %s\n",
+				roundDuration(timings.DNS), roundDuration(timings.Connect), roundDuration(timings.TLS),
+				roundDuration(timings.TTFB), roundDuration(timings.Transfer))
+		}
+	}
+
+	if report.RateLimit != nil {
+		r.printTextRateLimit(report.RateLimit)
+	}
+
+	if len(report.Attempts) > 1 {
+		fmt.Printf("Attempts: %d\n", len(report.Attempts))
+		for _, attempt := range report.Attempts {
+			outcome := "failed"
+			if attempt.Passed {
+				outcome = "passed"
+			}
+			if attempt.Error != "" {
+				fmt.Printf("  #%d %s: %s\n", attempt.Number, outcome, attempt.Error)
+			} else {
+				fmt.Printf("  #%d %s: status %d in %s\n", attempt.Number, outcome, attempt.StatusCode, attempt.Duration)
+			}
+		}
+	}
 	
+	if report.Dump != nil {
+		r.printTextDump(report.Dump)
+	}
+
 	if len(report.ValidationResult.Errors) > 0 {
 		fmt.Println("Validation Errors:")
 		for _, err := range report.ValidationResult.Errors {
@@ -171,6 +300,50 @@ func (r *Reporter) printTextTestReport(report *TestReport) {
 	fmt.Println("---")
 }
 
+func (r *Reporter) printTextDump(dump *executor.Dump) {
+	for _, side := range []struct{ name, raw string }{{"Request", dump.Request}, {"Response", dump.Response}} {
+		fmt.Printf("%s Dump:\n", side.name)
+		for _, line := range strings.Split(strings.TrimRight(side.raw, "\r\n"), "\n") {
+			if line = strings.TrimRight(line, "\r"); line == "" {
+				fmt.Println("  |")
+			} else {
+				fmt.Printf("  | %s\n", line)
+			}
+		}
+	}
+}
+
+// roundDuration keeps phase timings readable; sub-microsecond precision
+// is noise at network scale.
+func roundDuration(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
+
+func (r *Reporter) printTextRateLimit(result *executor.RateLimitResult) {
+	fmt.Printf("Requests Sent: %d\n", result.RequestsSent)
+	if result.BlockedAt > 0 {
+		fmt.Printf("Blocked At: request %d after %s\n", result.BlockedAt, result.BlockedAfter)
+	} else {
+		fmt.Println("Blocked At: never")
+	}
+
+	statuses := make([]int, 0, len(result.StatusHistogram))
+	for status := range result.StatusHistogram {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+
+	counts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		counts = append(counts, fmt.Sprintf("%d x%d", status, result.StatusHistogram[status]))
+	}
+	fmt.Printf("Status Histogram: %s\n", strings.Join(counts, ", "))
+
+	if result.Errors > 0 {
+		fmt.Printf("Request Errors: %d\n", result.Errors)
+	}
+}
+
 func (r *Reporter) printJSONSuiteReport(report *SuiteReport) {
 	data, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
@@ -180,11 +353,32 @@ func (r *Reporter) printJSONSuiteReport(report *SuiteReport) {
 	fmt.Println(string(data))
 }
 
+func (r *Reporter) printHARSuiteReport(report *SuiteReport) {
+	data, err := json.MarshalIndent(buildHAR(report), "", "  ")
+	if err != nil {
+		logger.Error("Failed to marshal suite report to HAR:", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
 func (r *Reporter) printTextSuiteReport(report *SuiteReport) {
 	fmt.Printf("Suite: %s\n", report.SuiteName)
 	fmt.Printf("Total Tests: %d\n", report.TotalTests)
 	fmt.Printf("Passed: %d\n", report.PassedTests)
 	fmt.Printf("Failed: %d\n", report.FailedTests)
+	if report.FlakyTests > 0 {
+		fmt.Printf("Flaky: %d\n", report.FlakyTests)
+	}
+	if report.SkippedTests > 0 {
+		fmt.Printf("Skipped: %d\n", report.SkippedTests)
+	}
+	if report.Interrupted {
+		fmt.Println("Interrupted: yes (partial results)")
+	}
+	if report.StoppedEarly {
+		fmt.Println("Stopped Early: failure limit reached")
+	}
 	fmt.Printf("Duration: %s\n", report.Duration)
 	fmt.Printf("Success Rate: %.2f%%\n", float64(report.PassedTests)/float64(report.TotalTests)*100)
 	fmt.Println("====================================")
@@ -192,4 +386,6 @@ func (r *Reporter) printTextSuiteReport(report *SuiteReport) {
 	for _, test := range report.Tests {
 		r.printTextTestReport(&test)
 	}
+
+	r.printTextCoverage(report.Coverage)
 }
\ No newline at end of file
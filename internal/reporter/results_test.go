@@ -2,6 +2,7 @@ package reporter
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -223,6 +224,162 @@ func TestGenerateSuiteReportEmpty(t *testing.T) {
 	}
 }
 
+func TestGenerateSkippedTestReport(t *testing.T) {
+	reporter := NewReporter("text", "")
+
+	request := &config.Request{
+		Method: "GET",
+		Path:   "/skipped",
+	}
+
+	report := reporter.GenerateSkippedTestReport("skipped-test", request, "run interrupted")
+
+	if report.Status != StatusSkipped {
+		t.Errorf("GenerateSkippedTestReport() Status = %s, want %s", report.Status, StatusSkipped)
+	}
+
+	if report.SkipReason != "run interrupted" {
+		t.Errorf("GenerateSkippedTestReport() SkipReason = %s, want 'run interrupted'", report.SkipReason)
+	}
+
+	if report.Response != nil {
+		t.Error("GenerateSkippedTestReport() Response should be nil")
+	}
+
+	// Skipped reports carry no response and must still print cleanly
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("PrintTestReport() panicked on skipped report: %v", r)
+		}
+	}()
+
+	reporter.PrintTestReport(report)
+}
+
+func TestGenerateErrorTestReport(t *testing.T) {
+	reporter := NewReporter("text", "")
+
+	request := &config.Request{
+		Method: "GET",
+		Path:   "/unreachable",
+	}
+
+	report := reporter.GenerateErrorTestReport("error-test", request, errors.New("connection refused"), time.Second)
+
+	if report.Status != StatusFail {
+		t.Errorf("GenerateErrorTestReport() Status = %s, want %s", report.Status, StatusFail)
+	}
+
+	if report.ValidationResult.Passed || len(report.ValidationResult.Errors) != 1 ||
+		!strings.Contains(report.ValidationResult.Errors[0], "connection refused") {
+		t.Errorf("GenerateErrorTestReport() ValidationResult = %+v, want the request error", report.ValidationResult)
+	}
+
+	// Failed requests carry no response and must still print cleanly
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("PrintTestReport() panicked on error report: %v", r)
+		}
+	}()
+
+	reporter.PrintTestReport(report)
+}
+
+func TestGenerateSuiteReportWithSkipped(t *testing.T) {
+	reporter := NewReporter("json", "")
+
+	testReports := []TestReport{
+		{TestName: "test1", Status: StatusPass},
+		{TestName: "test2", Status: StatusFail},
+		{TestName: "test3", Status: StatusSkipped},
+		{TestName: "test4", Status: StatusSkipped},
+	}
+
+	suiteReport := reporter.GenerateSuiteReport("partial-suite", testReports, time.Second)
+
+	if suiteReport.TotalTests != 4 {
+		t.Errorf("GenerateSuiteReport() TotalTests = %d, want 4", suiteReport.TotalTests)
+	}
+
+	if suiteReport.PassedTests != 1 {
+		t.Errorf("GenerateSuiteReport() PassedTests = %d, want 1", suiteReport.PassedTests)
+	}
+
+	if suiteReport.FailedTests != 1 {
+		t.Errorf("GenerateSuiteReport() FailedTests = %d, want 1", suiteReport.FailedTests)
+	}
+
+	if suiteReport.SkippedTests != 2 {
+		t.Errorf("GenerateSuiteReport() SkippedTests = %d, want 2", suiteReport.SkippedTests)
+	}
+}
+
+func TestRecordAttempts(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		attempts   []Attempt
+		wantStatus string
+	}{
+		{
+			name:       "single passing attempt",
+			status:     StatusPass,
+			attempts:   []Attempt{{Number: 1, Passed: true}},
+			wantStatus: StatusPass,
+		},
+		{
+			name:       "passed after retry",
+			status:     StatusPass,
+			attempts:   []Attempt{{Number: 1, Error: "connection reset"}, {Number: 2, Passed: true}},
+			wantStatus: StatusFlaky,
+		},
+		{
+			name:       "failed after retries",
+			status:     StatusFail,
+			attempts:   []Attempt{{Number: 1}, {Number: 2}},
+			wantStatus: StatusFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &TestReport{TestName: tt.name, Status: tt.status}
+			report.RecordAttempts(tt.attempts)
+
+			if report.Status != tt.wantStatus {
+				t.Errorf("RecordAttempts() status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			if len(report.Attempts) != len(tt.attempts) {
+				t.Errorf("RecordAttempts() attempts = %d, want %d", len(report.Attempts), len(tt.attempts))
+			}
+		})
+	}
+}
+
+func TestGenerateSuiteReportWithFlaky(t *testing.T) {
+	reporter := NewReporter("json", "")
+
+	testReports := []TestReport{
+		{TestName: "test1", Status: StatusPass},
+		{TestName: "test2", Status: StatusFlaky},
+		{TestName: "test3", Status: StatusFail},
+	}
+
+	suiteReport := reporter.GenerateSuiteReport("flaky-suite", testReports, time.Second)
+
+	if suiteReport.PassedTests != 2 {
+		t.Errorf("GenerateSuiteReport() PassedTests = %d, want 2", suiteReport.PassedTests)
+	}
+
+	if suiteReport.FlakyTests != 1 {
+		t.Errorf("GenerateSuiteReport() FlakyTests = %d, want 1", suiteReport.FlakyTests)
+	}
+
+	if suiteReport.FailedTests != 1 {
+		t.Errorf("GenerateSuiteReport() FailedTests = %d, want 1", suiteReport.FailedTests)
+	}
+}
+
 func TestSaveSuiteReport(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "reporter_test")
 	if err != nil {
@@ -0,0 +1,215 @@
+// Package runner executes individual tests, combining the executor,
+// validator and reporter and applying the test's retry policy.
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/logger"
+	"wafguard/internal/reporter"
+	"wafguard/internal/validator"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Runner struct {
+	executor  executor.Executor
+	validator *validator.ResponseValidator
+	reporter  *reporter.Reporter
+	dump      DumpOptions
+}
+
+// DumpOptions controls capturing the raw HTTP exchange of tests into
+// their reports. Tests with debug: true are always dumped.
+type DumpOptions struct {
+	// All dumps every test.
+	All bool
+	// Dir, if set, also receives each dump as <test name>.http.
+	Dir string
+	// MaxSize is how many bytes of the request and of the response are
+	// kept; 0 means executor.DefaultDumpSize.
+	MaxSize int
+}
+
+func NewRunner(httpExecutor executor.Executor, responseValidator *validator.ResponseValidator, rep *reporter.Reporter) *Runner {
+	return &Runner{
+		executor:  httpExecutor,
+		validator: responseValidator,
+		reporter:  rep,
+	}
+}
+
+// WithDump returns a runner that dumps tests as described by options.
+func (r *Runner) WithDump(options DumpOptions) *Runner {
+	dumping := *r
+	dumping.dump = options
+	return &dumping
+}
+
+// RunTest executes a test against the target, retrying according to the
+// test's retry policy, and returns its report. A test whose last attempt
+// could not be executed gets a failed report carrying the error and the
+// attempts; an error is returned only when ctx was cancelled first, so
+// callers can report the test as skipped.
+func (r *Runner) RunTest(ctx context.Context, test *config.Test, target config.Target) (*reporter.TestReport, error) {
+	if test.RateLimitCheck != nil {
+		return r.runRateLimitCheck(ctx, test, target)
+	}
+
+	policy := test.RetryPolicy(target)
+	start := time.Now()
+
+	var opts []executor.RequestOption
+	if target.MaxBodySize > 0 {
+		opts = append(opts, executor.WithMaxBodySize(target.MaxBodySize))
+	}
+	dump := test.Debug || r.dump.All
+	if dump {
+		maxSize := r.dump.MaxSize
+		if maxSize <= 0 {
+			maxSize = executor.DefaultDumpSize
+		}
+		opts = append(opts, executor.WithDump(maxSize))
+	}
+
+	var attempts []reporter.Attempt
+	var response *executor.Response
+	var validation *validator.ValidationResult
+	var err error
+
+	for number := 1; ; number++ {
+		attemptStart := time.Now()
+		response, err = r.executor.ExecuteTest(ctx, test, target.BaseURL, opts...)
+
+		attempt := reporter.Attempt{Number: number}
+		if err != nil {
+			validation = nil
+			attempt.Error = err.Error()
+		} else {
+			validation = r.validator.WithBlockStatus(target.BlockStatus).Validate(response, &test.Expected, test.Name)
+			attempt.StatusCode = response.StatusCode
+			attempt.Passed = validation.Passed
+			attempt.Response = response
+		}
+		attempt.Duration = time.Since(attemptStart)
+		attempts = append(attempts, attempt)
+
+		retryable := err != nil || (!validation.Passed && policy.OnValidationFailure)
+		if !retryable || number > policy.Retries || ctx.Err() != nil {
+			break
+		}
+
+		wait := policy.BackoffFor(number)
+		logger.WithFields(logrus.Fields{
+			"test_name": test.Name,
+			"attempt":   number,
+			"backoff":   wait.String(),
+		}).Warn("Test attempt failed, retrying")
+
+		if !sleep(ctx, wait) {
+			break
+		}
+	}
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		report := r.reporter.GenerateErrorTestReport(test.Name, &test.Request, err, time.Since(start))
+		report.RecordAttempts(attempts)
+		report.Injection = test.Injection
+		return report, nil
+	}
+
+	report := r.reporter.GenerateTestReport(test.Name, &test.Request, response, validation, time.Since(start))
+	report.RecordAttempts(attempts)
+	report.Injection = test.Injection
+
+	if dump && response.Dump != nil {
+		report.Dump = response.Dump
+		if err := r.writeDump(test.Name, response.Dump); err != nil {
+			logger.WithFields(logrus.Fields{
+				"test_name": test.Name,
+				"error":     err,
+			}).Warn("Failed to write dump file")
+		}
+	}
+
+	return report, nil
+}
+
+// writeDump saves the exchange to the dump directory, if one is set.
+func (r *Runner) writeDump(testName string, dump *executor.Dump) error {
+	if r.dump.Dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(r.dump.Dir, 0755); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	b.WriteString(dump.Request)
+	if !strings.HasSuffix(dump.Request, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(dump.Response)
+
+	return os.WriteFile(filepath.Join(r.dump.Dir, dumpFileName(testName)), []byte(b.String()), 0644)
+}
+
+// dumpFileName turns a test name into a file name, replacing characters
+// that are unsafe in paths.
+func dumpFileName(testName string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, testName)
+	return strings.TrimLeft(name, ".") + ".http"
+}
+
+// runRateLimitCheck fires the request repeatedly and reports when the WAF
+// started blocking. Rate-limit checks are never retried.
+func (r *Runner) runRateLimitCheck(ctx context.Context, test *config.Test, target config.Target) (*reporter.TestReport, error) {
+	start := time.Now()
+
+	result, err := r.executor.ExecuteRateLimitCheck(ctx, test, target.BaseURL, target.BlockStatus)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return r.reporter.GenerateErrorTestReport(test.Name, &test.Request, err, time.Since(start)), nil
+	}
+
+	validation := r.validator.WithBlockStatus(target.BlockStatus).ValidateRateLimit(result, test)
+	report := r.reporter.GenerateTestReport(test.Name, &test.Request, result.Response, validation, time.Since(start))
+	report.RateLimit = result
+
+	return report, nil
+}
+
+// sleep waits for d or until ctx is done, reporting whether the full
+// duration elapsed.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
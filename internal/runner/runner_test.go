@@ -0,0 +1,430 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+	"wafguard/internal/core/config"
+	"wafguard/internal/executor"
+	"wafguard/internal/reporter"
+	"wafguard/internal/validator"
+)
+
+func newTestRunner() *Runner {
+	return NewRunner(executor.NewHTTPExecutor(5*time.Second), validator.NewResponseValidator(), reporter.NewReporter("text", ""))
+}
+
+// flakyServer fails the first n requests with a 500 and then returns 200
+func flakyServer(n int32) (*httptest.Server, *int32) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) <= n {
+			w.WriteHeader(500)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	return server, &count
+}
+
+func TestRunTestPassesFirstTime(t *testing.T) {
+	server, count := flakyServer(0)
+	defer server.Close()
+
+	test := &config.Test{
+		Name:     "stable",
+		Request:  config.Request{Method: "GET", Path: "/"},
+		Expected: config.Expected{Status: []int{200}},
+		Retry:    &config.Retry{Retries: 3, OnValidationFailure: true},
+	}
+
+	report, err := newTestRunner().RunTest(context.Background(), test, config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+
+	if report.Status != reporter.StatusPass {
+		t.Errorf("RunTest() status = %s, want %s", report.Status, reporter.StatusPass)
+	}
+	if len(report.Attempts) != 1 {
+		t.Errorf("RunTest() attempts = %d, want 1", len(report.Attempts))
+	}
+	if *count != 1 {
+		t.Errorf("server received %d requests, want 1", *count)
+	}
+}
+
+func TestRunTestRetriesValidationFailure(t *testing.T) {
+	tests := []struct {
+		name         string
+		retry        *config.Retry
+		wantStatus   string
+		wantAttempts int
+	}{
+		{
+			name:         "no retry policy",
+			retry:        nil,
+			wantStatus:   reporter.StatusFail,
+			wantAttempts: 1,
+		},
+		{
+			name:         "validation failures not retried",
+			retry:        &config.Retry{Retries: 3},
+			wantStatus:   reporter.StatusFail,
+			wantAttempts: 1,
+		},
+		{
+			name:         "passes after retries is flaky",
+			retry:        &config.Retry{Retries: 3, OnValidationFailure: true},
+			wantStatus:   reporter.StatusFlaky,
+			wantAttempts: 3,
+		},
+		{
+			name:         "retries exhausted",
+			retry:        &config.Retry{Retries: 1, OnValidationFailure: true},
+			wantStatus:   reporter.StatusFail,
+			wantAttempts: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := flakyServer(2)
+			defer server.Close()
+
+			test := &config.Test{
+				Name:     tt.name,
+				Request:  config.Request{Method: "GET", Path: "/"},
+				Expected: config.Expected{Status: []int{200}},
+				Retry:    tt.retry,
+			}
+
+			report, err := newTestRunner().RunTest(context.Background(), test, config.Target{BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("RunTest() failed: %v", err)
+			}
+
+			if report.Status != tt.wantStatus {
+				t.Errorf("RunTest() status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			if len(report.Attempts) != tt.wantAttempts {
+				t.Errorf("RunTest() attempts = %d, want %d", len(report.Attempts), tt.wantAttempts)
+			}
+			for _, attempt := range report.Attempts {
+				if attempt.Response == nil || attempt.Response.StatusCode != attempt.StatusCode {
+					t.Errorf("attempt %d does not carry its response", attempt.Number)
+				}
+			}
+		})
+	}
+}
+
+func TestRunTestRetriesExecutionErrors(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			// Drop the connection to simulate an edge reset
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				_ = conn.Close()
+			}
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	test := &config.Test{
+		Name:     "dropped-connection",
+		Request:  config.Request{Method: "GET", Path: "/"},
+		Expected: config.Expected{Status: []int{200}},
+	}
+	target := config.Target{
+		BaseURL: server.URL,
+		Retry:   &config.Retry{Retries: 2, Backoff: time.Millisecond},
+	}
+
+	report, err := newTestRunner().RunTest(context.Background(), test, target)
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+
+	if report.Status != reporter.StatusFlaky {
+		t.Errorf("RunTest() status = %s, want %s", report.Status, reporter.StatusFlaky)
+	}
+	if len(report.Attempts) != 2 {
+		t.Fatalf("RunTest() attempts = %d, want 2", len(report.Attempts))
+	}
+	if report.Attempts[0].Error == "" {
+		t.Error("first attempt should record the execution error")
+	}
+}
+
+func TestRunTestRecordsInjection(t *testing.T) {
+	server, _ := flakyServer(0)
+	defer server.Close()
+
+	injection := &config.Injection{Test: "sqli", Payload: "'", Location: "query:q"}
+	test := &config.Test{
+		Name:      "sqli/query:q/1",
+		Request:   config.Request{Method: "GET", Path: "/?q='"},
+		Expected:  config.Expected{Status: []int{200}},
+		Injection: injection,
+	}
+
+	report, err := newTestRunner().RunTest(context.Background(), test, config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+
+	if report.Injection != injection {
+		t.Errorf("RunTest() injection = %+v, want %+v", report.Injection, injection)
+	}
+}
+
+func TestRunTestExecutionErrorWithoutRetries(t *testing.T) {
+	test := &config.Test{
+		Name:     "unreachable",
+		Request:  config.Request{Method: "GET", Path: "/"},
+		Expected: config.Expected{Status: []int{200}},
+	}
+
+	report, err := newTestRunner().RunTest(context.Background(), test, config.Target{BaseURL: "http://localhost:99999"})
+	if err != nil {
+		t.Fatalf("RunTest() error = %v, want a failed report", err)
+	}
+
+	if report.Status != reporter.StatusFail || report.Response != nil {
+		t.Errorf("RunTest() status = %s, response = %v, want %s and none", report.Status, report.Response, reporter.StatusFail)
+	}
+	if len(report.ValidationResult.Errors) != 1 || !strings.Contains(report.ValidationResult.Errors[0], "Request failed") {
+		t.Errorf("RunTest() errors = %v, want the execution error", report.ValidationResult.Errors)
+	}
+}
+
+func TestRunTestExecutionErrorKeepsAttempts(t *testing.T) {
+	test := &config.Test{
+		Name:     "unreachable",
+		Request:  config.Request{Method: "GET", Path: "/"},
+		Expected: config.Expected{Status: []int{200}},
+	}
+	target := config.Target{
+		BaseURL: "http://localhost:99999",
+		Retry:   &config.Retry{Retries: 2, Backoff: time.Millisecond},
+	}
+
+	report, err := newTestRunner().RunTest(context.Background(), test, target)
+	if err != nil {
+		t.Fatalf("RunTest() error = %v, want a failed report", err)
+	}
+
+	if report.Status != reporter.StatusFail {
+		t.Errorf("RunTest() status = %s, want %s", report.Status, reporter.StatusFail)
+	}
+	if len(report.Attempts) != 3 {
+		t.Fatalf("RunTest() attempts = %d, want 3", len(report.Attempts))
+	}
+	for _, attempt := range report.Attempts {
+		if attempt.Error == "" || attempt.Passed {
+			t.Errorf("attempt %d = %+v, want a recorded error", attempt.Number, attempt)
+		}
+	}
+}
+
+func TestRunTestCancelledDuringBackoff(t *testing.T) {
+	server, count := flakyServer(10)
+	defer server.Close()
+
+	test := &config.Test{
+		Name:     "cancelled",
+		Request:  config.Request{Method: "GET", Path: "/"},
+		Expected: config.Expected{Status: []int{200}},
+		Retry:    &config.Retry{Retries: 5, Backoff: time.Hour, OnValidationFailure: true},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	report, err := newTestRunner().RunTest(ctx, test, config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+
+	if report.Status != reporter.StatusFail {
+		t.Errorf("RunTest() status = %s, want %s", report.Status, reporter.StatusFail)
+	}
+	if *count != 1 {
+		t.Errorf("server received %d requests, want 1", *count)
+	}
+}
+
+func TestRunTestRateLimitCheck(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) > 3 {
+			w.WriteHeader(429)
+			return
+		}
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	test := &config.Test{
+		Name:           "login-rate-limit",
+		Request:        config.Request{Method: "POST", Path: "/login"},
+		Expected:       config.Expected{Status: []int{429}},
+		RateLimitCheck: &config.RateLimitCheck{Requests: 10, BlockWithin: 5},
+	}
+
+	report, err := newTestRunner().RunTest(context.Background(), test, config.Target{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+
+	if report.Status != reporter.StatusPass {
+		t.Errorf("RunTest() status = %s, want %s (errors: %v)", report.Status, reporter.StatusPass, report.ValidationResult.Errors)
+	}
+	if report.RateLimit == nil {
+		t.Fatal("RunTest() should attach the rate limit result")
+	}
+	if report.RateLimit.BlockedAt != 4 {
+		t.Errorf("BlockedAt = %d, want 4", report.RateLimit.BlockedAt)
+	}
+	if report.Response == nil || report.Response.StatusCode != 429 {
+		t.Error("report response should be the first blocked response")
+	}
+}
+
+// stubExecutor answers every request with a fixed status without sending it
+type stubExecutor struct {
+	status int
+	calls  int32
+}
+
+func (s *stubExecutor) ExecuteTest(ctx context.Context, test *config.Test, baseURL string, opts ...executor.RequestOption) (*executor.Response, error) {
+	atomic.AddInt32(&s.calls, 1)
+	return &executor.Response{URL: baseURL + test.Request.Path, StatusCode: s.status, Request: &test.Request}, nil
+}
+
+func (s *stubExecutor) ExecuteRateLimitCheck(ctx context.Context, test *config.Test, baseURL string, blockStatus []int) (*executor.RateLimitResult, error) {
+	return nil, nil
+}
+
+func TestRunTestWithCustomExecutor(t *testing.T) {
+	stub := &stubExecutor{status: 406}
+	testRunner := NewRunner(stub, validator.NewResponseValidator(), reporter.NewReporter("text", ""))
+
+	blocked := true
+	test := &config.Test{
+		Name:     "stubbed",
+		Request:  config.Request{Method: "GET", Path: "/?q=<script>"},
+		Expected: config.Expected{Blocked: &blocked},
+	}
+
+	report, err := testRunner.RunTest(context.Background(), test, config.Target{BaseURL: "http://waf.invalid", BlockStatus: []int{406}})
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+	if report.Status != reporter.StatusPass {
+		t.Errorf("RunTest() status = %s, want %s", report.Status, reporter.StatusPass)
+	}
+	if stub.calls != 1 {
+		t.Errorf("executor called %d times, want 1", stub.calls)
+	}
+}
+
+func TestRunTestDump(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Waf", "mock")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte("blocked by rule 942100"))
+	}))
+	defer server.Close()
+
+	test := func(name string, debug bool) *config.Test {
+		return &config.Test{
+			Name:     name,
+			Request:  config.Request{Method: "POST", Path: "/login?next=/", Headers: map[string]string{"X-Test": "1"}, Body: "user=' OR 1=1--"},
+			Expected: config.Expected{Status: []int{403}},
+			Debug:    debug,
+		}
+	}
+	target := config.Target{BaseURL: server.URL}
+	dir := t.TempDir()
+
+	report, err := newTestRunner().WithDump(DumpOptions{Dir: dir}).RunTest(context.Background(), test("not debugged", false), target)
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+	if report.Dump != nil {
+		t.Errorf("test without debug was dumped: %+v", report.Dump)
+	}
+
+	report, err = newTestRunner().WithDump(DumpOptions{Dir: dir}).RunTest(context.Background(), test("sqli/login", true), target)
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+	dump := report.Dump
+	if dump == nil {
+		t.Fatal("test with debug: true was not dumped")
+	}
+	for _, want := range []string{"POST /login?next=/ HTTP/1.1\r\n", "X-Test: 1\r\n", "Content-Length: 15\r\n", "\r\n\r\nuser=' OR 1=1--"} {
+		if !strings.Contains(dump.Request, want) {
+			t.Errorf("request dump lacks %q:\n%s", want, dump.Request)
+		}
+	}
+	for _, want := range []string{"HTTP/1.1 403 Forbidden\r\n", "X-Waf: mock\r\n", "\r\n\r\nblocked by rule 942100"} {
+		if !strings.Contains(dump.Response, want) {
+			t.Errorf("response dump lacks %q:\n%s", want, dump.Response)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "sqli_login.http"))
+	if err != nil {
+		t.Fatalf("dump file not written: %v", err)
+	}
+	if !strings.HasPrefix(string(data), dump.Request) || !strings.HasSuffix(string(data), dump.Response) {
+		t.Errorf("dump file = %q", data)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("dump directory holds %d files, want 1", len(files))
+	}
+
+	report, err = newTestRunner().WithDump(DumpOptions{All: true, MaxSize: 20}).RunTest(context.Background(), test("truncated", false), target)
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+	if report.Dump == nil || !report.Dump.Truncated || !strings.HasPrefix(report.Dump.Request, "POST /login?next=/ H\n[truncated, 20 of") {
+		t.Errorf("dump = %+v, want both sides cut to 20 bytes", report.Dump)
+	}
+}
+
+func TestRunTestMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 100) + "You have an error in your SQL syntax"))
+	}))
+	defer server.Close()
+
+	test := &config.Test{
+		Name:     "error leak",
+		Request:  config.Request{Method: "GET", Path: "/?id=1'"},
+		Expected: config.Expected{Status: []int{200}, Body: &config.BodyExpected{NotContains: []string{"SQL syntax"}}},
+	}
+
+	report, err := newTestRunner().RunTest(context.Background(), test, config.Target{BaseURL: server.URL, MaxBodySize: 100})
+	if err != nil {
+		t.Fatalf("RunTest() failed: %v", err)
+	}
+	if !report.Response.Truncated || len(report.Response.Body) != 100 {
+		t.Errorf("body = %d bytes, truncated = %v, want 100 and true", len(report.Response.Body), report.Response.Truncated)
+	}
+	if report.Status != reporter.StatusPass || len(report.ValidationResult.Warnings) != 1 {
+		t.Errorf("status = %s, warnings = %q, want a pass with a truncation warning", report.Status, report.ValidationResult.Warnings)
+	}
+}
@@ -0,0 +1,222 @@
+// Package transform implements the evasion encodings that can be applied
+// to attack payloads before they are sent, such as URL encoding or SQL
+// comment insertion. Transforms are chained by name.
+package transform
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"unicode"
+)
+
+// Transform is a named payload encoding. Apply may use rng for
+// randomised encodings; deterministic transforms ignore it.
+type Transform struct {
+	Name        string
+	Description string
+	Apply       func(payload string, rng *rand.Rand) string
+}
+
+var transforms = []Transform{
+	{"url", "percent-encode every byte except unreserved characters", urlEncode},
+	{"double-url", "percent-encode twice, so % becomes %25", doubleURLEncode},
+	{"unicode", "IIS-style %uXXXX for every non-alphanumeric character", unicodeEscape},
+	{"html", "decimal HTML entities for every non-alphanumeric character", htmlEncode},
+	{"hex", `\xHH escape for every byte`, hexEncode},
+	{"base64", "standard base64 encoding", base64Encode},
+	{"case", "randomise the case of every letter", randomCase},
+	{"sql-comments", "replace every whitespace character with /**/", sqlComments},
+	{"whitespace", "replace spaces with tab, newline, carriage return, vertical tab or form feed", whitespace},
+	{"null-byte", "prefix with a NUL byte", nullByte},
+	{"overlong-utf8", "two-byte overlong UTF-8 for every non-alphanumeric ASCII character", overlongUTF8},
+}
+
+// List returns every transform in a stable order.
+func List() []Transform {
+	return append([]Transform(nil), transforms...)
+}
+
+// Names returns the names of every transform.
+func Names() []string {
+	names := make([]string, len(transforms))
+	for i, t := range transforms {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Lookup returns the transform with the given name.
+func Lookup(name string) (Transform, bool) {
+	for _, t := range transforms {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Transform{}, false
+}
+
+// Validate checks that every transform in chain exists.
+func Validate(chain []string) error {
+	for _, name := range chain {
+		if _, ok := Lookup(name); !ok {
+			return fmt.Errorf("unknown transform %q (available: %s)", name, strings.Join(Names(), ", "))
+		}
+	}
+	return nil
+}
+
+// Apply runs payload through chain from left to right. A nil rng is
+// seeded from the payload.
+func Apply(chain []string, payload string, rng *rand.Rand) (string, error) {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(Seed(payload)))
+	}
+	for _, name := range chain {
+		t, ok := Lookup(name)
+		if !ok {
+			return "", fmt.Errorf("unknown transform %q", name)
+		}
+		payload = t.Apply(payload, rng)
+	}
+	return payload, nil
+}
+
+// Seed derives a stable random seed from the given strings, so that
+// randomised transforms give the same result on every run.
+func Seed(parts ...string) int64 {
+	h := fnv.New64a()
+	for _, part := range parts {
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+	return int64(h.Sum64())
+}
+
+func isAlphanumeric(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func isUnreserved(c byte) bool {
+	return isAlphanumeric(c) || c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func urlEncode(payload string, _ *rand.Rand) string {
+	var b strings.Builder
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		if isUnreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func doubleURLEncode(payload string, rng *rand.Rand) string {
+	return urlEncode(urlEncode(payload, rng), rng)
+}
+
+func unicodeEscape(payload string, _ *rand.Rand) string {
+	var b strings.Builder
+	for _, r := range payload {
+		if r < 0x80 && isAlphanumeric(byte(r)) {
+			b.WriteRune(r)
+			continue
+		}
+		if r > 0xffff {
+			// %u only covers the basic multilingual plane
+			b.WriteRune(r)
+			continue
+		}
+		fmt.Fprintf(&b, "%%u%04X", r)
+	}
+	return b.String()
+}
+
+func htmlEncode(payload string, _ *rand.Rand) string {
+	var b strings.Builder
+	for _, r := range payload {
+		if r < 0x80 && isAlphanumeric(byte(r)) {
+			b.WriteRune(r)
+			continue
+		}
+		fmt.Fprintf(&b, "&#%d;", r)
+	}
+	return b.String()
+}
+
+func hexEncode(payload string, _ *rand.Rand) string {
+	var b strings.Builder
+	for i := 0; i < len(payload); i++ {
+		fmt.Fprintf(&b, `\x%02x`, payload[i])
+	}
+	return b.String()
+}
+
+func base64Encode(payload string, _ *rand.Rand) string {
+	return base64.StdEncoding.EncodeToString([]byte(payload))
+}
+
+func randomCase(payload string, rng *rand.Rand) string {
+	b := []byte(payload)
+	for i, c := range b {
+		if rng.Intn(2) == 0 {
+			continue
+		}
+		switch {
+		case 'a' <= c && c <= 'z':
+			b[i] = c - 'a' + 'A'
+		case 'A' <= c && c <= 'Z':
+			b[i] = c - 'A' + 'a'
+		}
+	}
+	return string(b)
+}
+
+func sqlComments(payload string, _ *rand.Rand) string {
+	var b strings.Builder
+	for _, r := range payload {
+		if unicode.IsSpace(r) {
+			b.WriteString("/**/")
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var whitespaceAlternatives = []string{"\t", "\n", "\r", "\v", "\f"}
+
+func whitespace(payload string, rng *rand.Rand) string {
+	var b strings.Builder
+	for _, r := range payload {
+		if r == ' ' {
+			b.WriteString(whitespaceAlternatives[rng.Intn(len(whitespaceAlternatives))])
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func nullByte(payload string, _ *rand.Rand) string {
+	return "\x00" + payload
+}
+
+func overlongUTF8(payload string, _ *rand.Rand) string {
+	var b strings.Builder
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		if c >= 0x80 || isAlphanumeric(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte(0xc0 | c>>6)
+		b.WriteByte(0x80 | c&0x3f)
+	}
+	return b.String()
+}
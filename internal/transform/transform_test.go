@@ -0,0 +1,82 @@
+package transform
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		chain   []string
+		payload string
+		want    string
+	}{
+		{"no transforms", nil, "' OR 1=1", "' OR 1=1"},
+		{"url", []string{"url"}, "a b'<~>", "a%20b%27%3C~%3E"},
+		{"double-url", []string{"double-url"}, "'", "%2527"},
+		{"unicode", []string{"unicode"}, "<a é", "%u003Ca%u0020%u00E9"},
+		{"html", []string{"html"}, "<a>", "&#60;a&#62;"},
+		{"hex", []string{"hex"}, "a'", `\x61\x27`},
+		{"base64", []string{"base64"}, "<script>", "PHNjcmlwdD4="},
+		{"sql-comments", []string{"sql-comments"}, "1 UNION\tSELECT ", "1/**/UNION/**/SELECT/**/"},
+		{"null-byte", []string{"null-byte"}, "x", "\x00x"},
+		{"overlong-utf8", []string{"overlong-utf8"}, "../a", "\xc0\xae\xc0\xae\xc0\xafa"},
+		{"chain runs left to right", []string{"sql-comments", "url"}, "1 OR", "1%2F%2A%2A%2FOR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(tt.chain, tt.payload, nil)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRandomised(t *testing.T) {
+	payload := "select * from users where a = b"
+
+	cased, _ := Apply([]string{"case"}, payload, rand.New(rand.NewSource(1)))
+	if !strings.EqualFold(cased, payload) || cased == payload {
+		t.Errorf("case transform = %q", cased)
+	}
+	again, _ := Apply([]string{"case"}, payload, rand.New(rand.NewSource(1)))
+	if again != cased {
+		t.Errorf("case transform is not reproducible: %q != %q", again, cased)
+	}
+
+	spaced, _ := Apply([]string{"whitespace"}, payload, rand.New(rand.NewSource(1)))
+	if strings.Contains(spaced, " ") || len(spaced) != len(payload) {
+		t.Errorf("whitespace transform = %q", spaced)
+	}
+	if strings.Join(strings.Fields(spaced), " ") != payload {
+		t.Errorf("whitespace transform changed more than spaces: %q", spaced)
+	}
+}
+
+func TestApplyUnknown(t *testing.T) {
+	if _, err := Apply([]string{"url", "rot13"}, "x", nil); err == nil {
+		t.Error("Apply() expected error for unknown transform")
+	}
+	if err := Validate([]string{"rot13"}); err == nil {
+		t.Error("Validate() expected error for unknown transform")
+	}
+	if err := Validate(Names()); err != nil {
+		t.Errorf("Validate(Names()) error = %v", err)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	if Seed("a", "bc") == Seed("ab", "c") {
+		t.Error("Seed() should separate its parts")
+	}
+	if Seed("a") != Seed("a") {
+		t.Error("Seed() should be stable")
+	}
+}
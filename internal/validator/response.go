@@ -1,9 +1,16 @@
 package validator
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
+	"wafguard/internal/echo"
 	"wafguard/internal/logger"
 	"wafguard/internal/core/config"
 	"wafguard/internal/executor"
@@ -17,10 +24,26 @@ type ValidationResult struct {
 	Warnings []string
 }
 
-type ResponseValidator struct{}
+type ResponseValidator struct {
+	blockStatus []int
+}
 
 func NewResponseValidator() *ResponseValidator {
-	return &ResponseValidator{}
+	return &ResponseValidator{
+		blockStatus: config.DefaultBlockStatus,
+	}
+}
+
+// WithBlockStatus returns a validator that treats the given statuses as a
+// WAF block when checking `expected.blocked`. An empty list keeps the
+// current statuses.
+func (v *ResponseValidator) WithBlockStatus(statuses []int) *ResponseValidator {
+	if len(statuses) == 0 {
+		return v
+	}
+	return &ResponseValidator{
+		blockStatus: statuses,
+	}
 }
 
 func (v *ResponseValidator) Validate(response *executor.Response, expected *config.Expected, testName string) *ValidationResult {
@@ -38,6 +61,9 @@ func (v *ResponseValidator) Validate(response *executor.Response, expected *conf
 	v.validateStatusCode(response, expected, result)
 	v.validateHeaders(response, expected, result)
 	v.validateBody(response, expected, result)
+	v.validateUpstream(response, expected, result)
+	v.validateLatency(response, expected, result)
+	v.checkTruncated(response, expected, result)
 
 	if len(result.Errors) > 0 {
 		result.Passed = false
@@ -53,9 +79,81 @@ func (v *ResponseValidator) Validate(response *executor.Response, expected *conf
 	return result
 }
 
+// ValidateRateLimit checks that blocking started early enough during a
+// rate-limit check. Header and body expectations are applied to the first
+// blocked response.
+func (v *ResponseValidator) ValidateRateLimit(result *executor.RateLimitResult, test *config.Test) *ValidationResult {
+	validation := &ValidationResult{
+		Passed:   true,
+		Errors:   []string{},
+		Warnings: []string{},
+	}
+
+	check := test.RateLimitCheck
+	blockWithin := check.BlockWithin
+	if blockWithin == 0 {
+		blockWithin = check.Requests
+	}
+
+	switch {
+	case result.BlockedAt == 0:
+		validation.Errors = append(validation.Errors, fmt.Sprintf(
+			"No blocking observed after %d requests: expected status %v within %d requests",
+			result.RequestsSent,
+			executor.RateLimitBlockStatus(test, v.blockStatus),
+			blockWithin,
+		))
+	case result.BlockedAt > blockWithin:
+		validation.Errors = append(validation.Errors, fmt.Sprintf(
+			"Blocking started at request %d, expected no later than request %d",
+			result.BlockedAt,
+			blockWithin,
+		))
+	}
+
+	if result.BlockedAt > 0 && check.Window > 0 && result.BlockedAfter > check.Window {
+		validation.Errors = append(validation.Errors, fmt.Sprintf(
+			"Blocking started after %s, expected within %s",
+			result.BlockedAfter,
+			check.Window,
+		))
+	}
+
+	if result.BlockedAt > 0 && result.Response != nil {
+		v.validateHeaders(result.Response, &test.Expected, validation)
+		v.validateBody(result.Response, &test.Expected, validation)
+	}
+
+	if result.Errors > 0 {
+		validation.Warnings = append(validation.Warnings, fmt.Sprintf(
+			"%d of %d requests failed to execute",
+			result.Errors,
+			result.RequestsSent,
+		))
+	}
+
+	if len(validation.Errors) > 0 {
+		validation.Passed = false
+	}
+
+	logger.WithFields(logrus.Fields{
+		"test_name":  test.Name,
+		"passed":     validation.Passed,
+		"blocked_at": result.BlockedAt,
+	}).Info("Rate limit validation completed")
+
+	return validation
+}
+
 func (v *ResponseValidator) validateStatusCode(response *executor.Response, expected *config.Expected, result *ValidationResult) {
+	if expected.Blocked != nil {
+		v.validateBlocked(response, *expected.Blocked, result)
+	}
+
 	if len(expected.Status) == 0 {
-		result.Warnings = append(result.Warnings, "No expected status codes defined")
+		if expected.Blocked == nil {
+			result.Warnings = append(result.Warnings, "No expected status codes defined")
+		}
 		return
 	}
 
@@ -76,6 +174,35 @@ func (v *ResponseValidator) validateStatusCode(response *executor.Response, expe
 	))
 }
 
+func (v *ResponseValidator) validateBlocked(response *executor.Response, wantBlocked bool, result *ValidationResult) {
+	blocked := v.IsBlocked(response)
+
+	if wantBlocked && !blocked {
+		result.Errors = append(result.Errors, fmt.Sprintf(
+			"Expected request to be blocked (status one of %v), got %d",
+			v.blockStatus,
+			response.StatusCode,
+		))
+	}
+
+	if !wantBlocked && blocked {
+		result.Errors = append(result.Errors, fmt.Sprintf(
+			"Expected request to be allowed, got blocking status %d",
+			response.StatusCode,
+		))
+	}
+}
+
+// IsBlocked reports whether the response status is one of the block statuses.
+func (v *ResponseValidator) IsBlocked(response *executor.Response) bool {
+	for _, status := range v.blockStatus {
+		if response.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
 func (v *ResponseValidator) validateHeaders(response *executor.Response, expected *config.Expected, result *ValidationResult) {
 	if len(expected.Headers) == 0 {
 		return
@@ -158,6 +285,178 @@ func (v *ResponseValidator) validateBody(response *executor.Response, expected *
 	}
 }
 
+// validateUpstream compares the request echoed by the origin with the
+// request that was sent.
+func (v *ResponseValidator) validateUpstream(response *executor.Response, expected *config.Expected, result *ValidationResult) {
+	upstream := expected.Upstream
+	if upstream == nil {
+		return
+	}
+
+	if v.IsBlocked(response) {
+		result.Errors = append(result.Errors, fmt.Sprintf(
+			"Upstream checks need the request to reach the origin, got blocking status %d",
+			response.StatusCode,
+		))
+		return
+	}
+
+	var received echo.Request
+	if err := json.Unmarshal([]byte(response.Body), &received); err != nil || received.Method == "" {
+		result.Errors = append(result.Errors,
+			"Upstream checks need an echo of the request, run the origin with `wafguard serve echo`")
+		return
+	}
+	sent := response.Request
+	if sent == nil {
+		result.Errors = append(result.Errors, "Upstream checks need the sent request, which was not recorded")
+		return
+	}
+
+	if upstream.Path {
+		sentURI := response.URL
+		if u, err := url.Parse(response.URL); err == nil {
+			sentURI = u.RequestURI()
+		}
+		if received.URI != sentURI {
+			result.Errors = append(result.Errors, fmt.Sprintf(
+				"Upstream path was rewritten: sent '%s', origin received '%s'",
+				sentURI,
+				received.URI,
+			))
+		}
+	}
+
+	for _, name := range upstreamHeaders(upstream.Header, sent.Headers) {
+		sentValue, wasSent := "", false
+		for key, value := range sent.Headers {
+			if strings.EqualFold(key, name) {
+				sentValue, wasSent = value, true
+			}
+		}
+		values, wasReceived := received.Headers[http.CanonicalHeaderKey(name)]
+		if strings.EqualFold(name, "Host") {
+			// Servers take Host out of the header map
+			values, wasReceived = []string{received.Host}, received.Host != ""
+		}
+		receivedValue := strings.Join(values, ", ")
+
+		switch {
+		case wasSent && !wasReceived:
+			result.Errors = append(result.Errors, fmt.Sprintf("Upstream header %s was removed", name))
+		case !wasSent && wasReceived:
+			result.Errors = append(result.Errors, fmt.Sprintf(
+				"Upstream header %s was added: origin received '%s'",
+				name,
+				receivedValue,
+			))
+		case wasSent && sentValue != receivedValue:
+			result.Errors = append(result.Errors, fmt.Sprintf(
+				"Upstream header %s was rewritten: sent '%s', origin received '%s'",
+				name,
+				sentValue,
+				receivedValue,
+			))
+		}
+	}
+
+	if upstream.Body {
+		body, err := received.RawBody()
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Invalid echoed body: %v", err))
+			return
+		}
+		if !bytes.Equal(body, []byte(sent.Body)) {
+			result.Errors = append(result.Errors, fmt.Sprintf(
+				"Upstream body was rewritten: sent %d bytes, origin received %d bytes (first difference at byte %d)",
+				len(sent.Body),
+				len(body),
+				firstDifference([]byte(sent.Body), body),
+			))
+		}
+	}
+}
+
+// upstreamHeaders expands "*" in the header names of an upstream check to
+// every header the test sends.
+func upstreamHeaders(names []string, sent map[string]string) []string {
+	var expanded []string
+	for _, name := range names {
+		if name != "*" {
+			expanded = append(expanded, name)
+			continue
+		}
+		keys := make([]string, 0, len(sent))
+		for key := range sent {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		expanded = append(expanded, keys...)
+	}
+	return expanded
+}
+
+func firstDifference(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	if len(a) < len(b) {
+		return len(a)
+	}
+	return len(b)
+}
+
+// checkTruncated warns when body assertions ran against a truncated body,
+// since the part that was cut could change their outcome.
+func (v *ResponseValidator) checkTruncated(response *executor.Response, expected *config.Expected, result *ValidationResult) {
+	if !response.Truncated {
+		return
+	}
+	if expected.Body == nil && (expected.Upstream == nil || v.IsBlocked(response)) {
+		return
+	}
+
+	result.Warnings = append(result.Warnings, fmt.Sprintf(
+		"Body assertions ran against a response body truncated to %d bytes; raise maxBodySize to check all of it",
+		len(response.Body),
+	))
+}
+
+// validateLatency checks the response time and its phases against the
+// bounds of expected.latency.
+func (v *ResponseValidator) validateLatency(response *executor.Response, expected *config.Expected, result *ValidationResult) {
+	latency := expected.Latency
+	if latency == nil {
+		return
+	}
+
+	check := func(phase string, took, limit time.Duration) {
+		if limit > 0 && took > limit {
+			result.Errors = append(result.Errors, fmt.Sprintf(
+				"%s took %s, expected at most %s", phase, took.Round(time.Microsecond), limit))
+		}
+	}
+
+	check("Response", response.Duration, latency.Total)
+
+	if latency.DNS == 0 && latency.Connect == 0 && latency.TLS == 0 && latency.TTFB == 0 && latency.Transfer == 0 {
+		return
+	}
+	timings := response.Timings
+	if timings == nil {
+		result.Errors = append(result.Errors, "Latency checks on phases need timings, but none were recorded for the response")
+		return
+	}
+
+	check("DNS lookup", timings.DNS, latency.DNS)
+	check("Connect", timings.Connect, latency.Connect)
+	check("TLS handshake", timings.TLS, latency.TLS)
+	check("Time to first byte", timings.TTFB, latency.TTFB)
+	check("Transfer", timings.Transfer, latency.Transfer)
+}
+
 func (v *ResponseValidator) ValidateMultiple(responses []*executor.Response, expected []*config.Expected, testNames []string) []*ValidationResult {
 	if len(responses) != len(expected) || len(responses) != len(testNames) {
 		panic("mismatched lengths in ValidateMultiple")
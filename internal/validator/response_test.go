@@ -1,9 +1,15 @@
 package validator
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 	"wafguard/internal/core/config"
+	"wafguard/internal/echo"
 	"wafguard/internal/executor"
 )
 
@@ -82,6 +88,80 @@ func TestValidateStatusCode(t *testing.T) {
 	}
 }
 
+func TestValidateBlocked(t *testing.T) {
+	blocked := true
+	allowed := false
+
+	tests := []struct {
+		name        string
+		blockStatus []int
+		statusCode  int
+		expected    *config.Expected
+		wantPassed  bool
+		wantWarn    int
+	}{
+		{
+			name:       "blocked with default block status",
+			statusCode: 403,
+			expected:   &config.Expected{Blocked: &blocked},
+			wantPassed: true,
+		},
+		{
+			name:       "expected block but allowed",
+			statusCode: 200,
+			expected:   &config.Expected{Blocked: &blocked},
+			wantPassed: false,
+		},
+		{
+			name:       "expected allowed and allowed",
+			statusCode: 404,
+			expected:   &config.Expected{Blocked: &allowed},
+			wantPassed: true,
+		},
+		{
+			name:       "expected allowed but blocked",
+			statusCode: 403,
+			expected:   &config.Expected{Blocked: &allowed},
+			wantPassed: false,
+		},
+		{
+			name:        "custom block status",
+			blockStatus: []int{406, 429},
+			statusCode:  406,
+			expected:    &config.Expected{Blocked: &blocked},
+			wantPassed:  true,
+		},
+		{
+			name:        "default status is not a block with custom block status",
+			blockStatus: []int{406},
+			statusCode:  403,
+			expected:    &config.Expected{Blocked: &blocked},
+			wantPassed:  false,
+		},
+		{
+			name:       "blocked combined with status",
+			statusCode: 403,
+			expected:   &config.Expected{Blocked: &blocked, Status: []int{403}},
+			wantPassed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewResponseValidator().WithBlockStatus(tt.blockStatus)
+			result := validator.Validate(&executor.Response{StatusCode: tt.statusCode}, tt.expected, "test")
+
+			if result.Passed != tt.wantPassed {
+				t.Errorf("Validate() passed = %v, want %v (errors: %v)", result.Passed, tt.wantPassed, result.Errors)
+			}
+
+			if len(result.Warnings) != tt.wantWarn {
+				t.Errorf("Validate() warning count = %d, want %d", len(result.Warnings), tt.wantWarn)
+			}
+		})
+	}
+}
+
 func TestValidateHeaders(t *testing.T) {
 	validator := NewResponseValidator()
 
@@ -576,4 +656,313 @@ func TestValidateWithComplexRegex(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestValidateRateLimit(t *testing.T) {
+	validator := NewResponseValidator()
+
+	blockedResponse := &executor.Response{
+		StatusCode: 429,
+		Body:       "Too Many Requests",
+	}
+
+	tests := []struct {
+		name           string
+		result         *executor.RateLimitResult
+		check          *config.RateLimitCheck
+		body           *config.BodyExpected
+		wantPassed     bool
+		wantErrorCount int
+		wantWarnCount  int
+	}{
+		{
+			name:       "blocked within limit",
+			result:     &executor.RateLimitResult{RequestsSent: 50, BlockedAt: 50, Response: blockedResponse},
+			check:      &config.RateLimitCheck{Requests: 200, BlockWithin: 100},
+			wantPassed: true,
+		},
+		{
+			name:           "blocked too late",
+			result:         &executor.RateLimitResult{RequestsSent: 150, BlockedAt: 150, Response: blockedResponse},
+			check:          &config.RateLimitCheck{Requests: 200, BlockWithin: 100},
+			wantPassed:     false,
+			wantErrorCount: 1,
+		},
+		{
+			name:           "never blocked",
+			result:         &executor.RateLimitResult{RequestsSent: 200, Response: &executor.Response{StatusCode: 200}},
+			check:          &config.RateLimitCheck{Requests: 200},
+			wantPassed:     false,
+			wantErrorCount: 1,
+		},
+		{
+			name:           "blocked outside window",
+			result:         &executor.RateLimitResult{RequestsSent: 10, BlockedAt: 10, BlockedAfter: 90 * time.Second, Response: blockedResponse},
+			check:          &config.RateLimitCheck{Requests: 100, Window: time.Minute},
+			wantPassed:     false,
+			wantErrorCount: 1,
+		},
+		{
+			name:           "block page mismatch",
+			result:         &executor.RateLimitResult{RequestsSent: 10, BlockedAt: 10, Response: blockedResponse},
+			check:          &config.RateLimitCheck{Requests: 100},
+			body:           &config.BodyExpected{Contains: []string{"Access Denied"}},
+			wantPassed:     false,
+			wantErrorCount: 1,
+		},
+		{
+			name:          "request errors are warnings",
+			result:        &executor.RateLimitResult{RequestsSent: 10, BlockedAt: 8, Errors: 2, Response: blockedResponse},
+			check:         &config.RateLimitCheck{Requests: 100},
+			wantPassed:    true,
+			wantWarnCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := &config.Test{
+				Name:           tt.name,
+				Expected:       config.Expected{Status: []int{429}, Body: tt.body},
+				RateLimitCheck: tt.check,
+			}
+
+			result := validator.ValidateRateLimit(tt.result, test)
+
+			if result.Passed != tt.wantPassed {
+				t.Errorf("ValidateRateLimit() passed = %v, want %v (errors: %v)", result.Passed, tt.wantPassed, result.Errors)
+			}
+			if len(result.Errors) != tt.wantErrorCount {
+				t.Errorf("ValidateRateLimit() error count = %d, want %d", len(result.Errors), tt.wantErrorCount)
+			}
+			if len(result.Warnings) != tt.wantWarnCount {
+				t.Errorf("ValidateRateLimit() warning count = %d, want %d", len(result.Warnings), tt.wantWarnCount)
+			}
+		})
+	}
+}
+
+func TestValidateUpstream(t *testing.T) {
+	allowed := false
+	echoed := `{"method": "POST", "uri": "/search?q=a%20b", "host": "origin", "headers": {"X-Api-Key": ["k1"], "X-Forwarded-For": ["10.0.0.1"]}, "body": "q=1"}`
+	sent := &config.Request{
+		Method:  "POST",
+		Path:    "/search?q=a%20b",
+		Headers: map[string]string{"X-Api-Key": "k1"},
+		Body:    "q=1",
+	}
+
+	tests := []struct {
+		name       string
+		response   *executor.Response
+		upstream   *config.Upstream
+		wantErrors []string
+	}{
+		{
+			name:     "unchanged",
+			response: &executor.Response{URL: "http://waf/search?q=a%20b", StatusCode: 200, Body: echoed, Request: sent},
+			upstream: &config.Upstream{Path: true, Header: []string{"*"}, Body: true},
+		},
+		{
+			name:       "path rewritten",
+			response:   &executor.Response{URL: "http://waf/Search?q=a%20b", StatusCode: 200, Body: echoed, Request: sent},
+			upstream:   &config.Upstream{Path: true},
+			wantErrors: []string{"Upstream path was rewritten: sent '/Search?q=a%20b', origin received '/search?q=a%20b'"},
+		},
+		{
+			name:       "header added",
+			response:   &executor.Response{URL: "http://waf/", StatusCode: 200, Body: echoed, Request: sent},
+			upstream:   &config.Upstream{Header: []string{"x-forwarded-for"}},
+			wantErrors: []string{"Upstream header x-forwarded-for was added: origin received '10.0.0.1'"},
+		},
+		{
+			name: "header removed and rewritten, body rewritten",
+			response: &executor.Response{URL: "http://waf/", StatusCode: 200, Body: echoed, Request: &config.Request{
+				Method:  "POST",
+				Path:    "/",
+				Headers: map[string]string{"X-Api-Key": "k2", "X-Debug": "1"},
+				Body:    "q=2",
+			}},
+			upstream: &config.Upstream{Header: []string{"*"}, Body: true},
+			wantErrors: []string{
+				"Upstream header X-Api-Key was rewritten: sent 'k2', origin received 'k1'",
+				"Upstream header X-Debug was removed",
+				"Upstream body was rewritten: sent 3 bytes, origin received 3 bytes (first difference at byte 2)",
+			},
+		},
+		{
+			name:       "blocked",
+			response:   &executor.Response{URL: "http://waf/", StatusCode: 403, Body: "denied", Request: sent},
+			upstream:   &config.Upstream{Path: true},
+			wantErrors: []string{"Upstream checks need the request to reach the origin, got blocking status 403"},
+		},
+		{
+			name:       "not an echo",
+			response:   &executor.Response{URL: "http://waf/", StatusCode: 200, Body: "<html>", Request: sent},
+			upstream:   &config.Upstream{Body: true},
+			wantErrors: []string{"Upstream checks need an echo of the request, run the origin with `wafguard serve echo`"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResponseValidator().Validate(tt.response, &config.Expected{Blocked: &allowed, Upstream: tt.upstream}, "test")
+
+			// The blocked case also fails the blocked: false expectation
+			errors := result.Errors
+			if tt.response.StatusCode == 403 {
+				errors = errors[1:]
+			}
+			if strings.Join(errors, "\n") != strings.Join(tt.wantErrors, "\n") {
+				t.Errorf("Errors = %q, want %q", errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestValidateUpstreamThroughRewritingProxy(t *testing.T) {
+	origin := httptest.NewServer(echo.Handler())
+	defer origin.Close()
+
+	// A WAF that lowercases the path and strips a header before forwarding
+	waf := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequest(r.Method, origin.URL+strings.ToLower(r.URL.Path), r.Body)
+		for name, values := range r.Header {
+			if name != "X-Debug" {
+				req.Header[name] = values
+			}
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	defer waf.Close()
+
+	test := &config.Test{
+		Name: "upstream",
+		Request: config.Request{
+			Method:  "POST",
+			Path:    "/Login",
+			Headers: map[string]string{"X-Debug": "1", "Content-Type": "text/plain"},
+			Body:    "\x00binary\xff",
+		},
+	}
+	resp, err := executor.NewHTTPExecutor(0).ExecuteTest(context.Background(), test, waf.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := false
+	result := NewResponseValidator().Validate(resp, &config.Expected{
+		Blocked:  &allowed,
+		Upstream: &config.Upstream{Path: true, Header: []string{"*"}, Body: true},
+	}, "upstream")
+
+	want := []string{
+		"Upstream path was rewritten: sent '/Login', origin received '/login'",
+		"Upstream header X-Debug was removed",
+	}
+	if strings.Join(result.Errors, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Errors = %q, want %q", result.Errors, want)
+	}
+}
+
+func TestValidateLatency(t *testing.T) {
+	response := &executor.Response{
+		StatusCode: 200,
+		Duration:   120 * time.Millisecond,
+		Timings: &executor.Timings{
+			DNS:      5 * time.Millisecond,
+			Connect:  10 * time.Millisecond,
+			TTFB:     100 * time.Millisecond,
+			Transfer: 20 * time.Millisecond,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		response   *executor.Response
+		latency    *config.Latency
+		wantErrors []string
+	}{
+		{
+			name:     "within bounds",
+			response: response,
+			latency:  &config.Latency{Total: time.Second, DNS: 10 * time.Millisecond, TLS: time.Millisecond, TTFB: 100 * time.Millisecond},
+		},
+		{
+			name:     "phases exceeded",
+			response: response,
+			latency:  &config.Latency{Total: 100 * time.Millisecond, Connect: 5 * time.Millisecond, Transfer: 10 * time.Millisecond},
+			wantErrors: []string{
+				"Response took 120ms, expected at most 100ms",
+				"Connect took 10ms, expected at most 5ms",
+				"Transfer took 20ms, expected at most 10ms",
+			},
+		},
+		{
+			name:       "no timings",
+			response:   &executor.Response{StatusCode: 200, Duration: time.Millisecond},
+			latency:    &config.Latency{Total: time.Second, TTFB: time.Second},
+			wantErrors: []string{"Latency checks on phases need timings, but none were recorded for the response"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResponseValidator().Validate(tt.response, &config.Expected{Status: []int{200}, Latency: tt.latency}, "latency")
+			if strings.Join(result.Errors, "\n") != strings.Join(tt.wantErrors, "\n") {
+				t.Errorf("Errors = %q, want %q", result.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestValidateTruncatedBody(t *testing.T) {
+	truncated := &executor.Response{StatusCode: 200, Body: "<html><head>", Truncated: true}
+	warning := "Body assertions ran against a response body truncated to 12 bytes; raise maxBodySize to check all of it"
+
+	tests := []struct {
+		name        string
+		response    *executor.Response
+		expected    *config.Expected
+		wantWarning bool
+	}{
+		{
+			name:        "body assertion",
+			response:    truncated,
+			expected:    &config.Expected{Status: []int{200}, Body: &config.BodyExpected{NotContains: []string{"SQL syntax"}}},
+			wantWarning: true,
+		},
+		{
+			name:     "status only",
+			response: truncated,
+			expected: &config.Expected{Status: []int{200}},
+		},
+		{
+			name:     "complete body",
+			response: &executor.Response{StatusCode: 200, Body: "<html><head>"},
+			expected: &config.Expected{Status: []int{200}, Body: &config.BodyExpected{Contains: []string{"<html>"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewResponseValidator().Validate(tt.response, tt.expected, "truncated")
+			found := false
+			for _, w := range result.Warnings {
+				found = found || w == warning
+			}
+			if found != tt.wantWarning {
+				t.Errorf("Warnings = %q, want truncation warning: %v", result.Warnings, tt.wantWarning)
+			}
+			if !result.Passed {
+				t.Errorf("Errors = %q, truncation alone should not fail a test", result.Errors)
+			}
+		})
+	}
+}
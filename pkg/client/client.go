@@ -3,34 +3,62 @@ package client
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"time"
 	"wafguard/internal/core/config"
 	"wafguard/internal/executor"
 	"wafguard/internal/parser"
 	"wafguard/internal/reporter"
+	"wafguard/internal/runner"
 	"wafguard/internal/validator"
 )
 
 // Client represents a Sentinel testing client
 type Client struct {
-	parser    *parser.Parser
-	executor  *executor.HTTPExecutor
-	validator *validator.ResponseValidator
-	reporter  *reporter.Reporter
+	parser       *parser.Parser
+	executor     executor.Executor
+	validator    *validator.ResponseValidator
+	reporter     *reporter.Reporter
+	rateLimiters *executor.RateLimiters
+	maxFailures  int
+	maxBodySize  int64
 }
 
 // Config represents the client configuration
 type Config struct {
 	Timeout     time.Duration
 	OutputFile  string
-	Format      string // "json" or "text"
+	Format      string // "json", "text" or "har"
 	Concurrent  int
+	FailFast    bool // stop after the first failing test
+	MaxFailures int  // stop after this many failing tests; 0 means no limit
+	// RPS caps requests per second per target host for every suite,
+	// overriding spec.target.rateLimit. 0 means suites use their own limit.
+	RPS   float64
+	Burst int
+	// MaxBodySize caps how many bytes of each response body are kept,
+	// overriding spec.target.maxBodySize. 0 means suites use their own.
+	MaxBodySize int64
+	// HTTPClient, if set, sends the requests instead of a client of the
+	// executor's own. Timeout still applies if the client has none.
+	HTTPClient *http.Client
+	// Transport, if set, replaces the transport of the client, e.g. to
+	// route requests through a proxy or serve them in-process.
+	Transport http.RoundTripper
 }
 
+// errFailureLimitReached is the cancellation cause used when a run stops
+// because MaxFailures (or FailFast) was reached.
+var errFailureLimitReached = errors.New("failure limit reached")
+
 // TestResult represents the result of running a test
 type TestResult struct {
 	TestName string
 	Passed   bool
+	Skipped  bool
+	Flaky    bool // passed only after one or more retries
+	Attempts int
 	Duration time.Duration
 	Errors   []string
 	Warnings []string
@@ -42,6 +70,10 @@ type SuiteResult struct {
 	TotalTests   int
 	PassedTests  int
 	FailedTests  int
+	FlakyTests   int
+	SkippedTests int
+	Interrupted  bool
+	StoppedEarly bool
 	Duration     time.Duration
 	TestResults  []TestResult
 }
@@ -57,12 +89,31 @@ func NewClient(cfg Config) *Client {
 	if cfg.Concurrent < 1 {
 		cfg.Concurrent = 1
 	}
+	if cfg.FailFast {
+		cfg.MaxFailures = 1
+	}
+
+	rateLimiters := executor.NewRateLimiters()
+	if cfg.RPS > 0 {
+		rateLimiters.SetDefault(config.RateLimit{RPS: cfg.RPS, Burst: cfg.Burst})
+	}
+
+	opts := []executor.Option{executor.WithRateLimiters(rateLimiters)}
+	if cfg.HTTPClient != nil {
+		opts = append(opts, executor.WithHTTPClient(cfg.HTTPClient))
+	}
+	if cfg.Transport != nil {
+		opts = append(opts, executor.WithTransport(cfg.Transport))
+	}
 
 	return &Client{
-		parser:    parser.NewParser(),
-		executor:  executor.NewHTTPExecutor(cfg.Timeout),
-		validator: validator.NewResponseValidator(),
-		reporter:  reporter.NewReporter(cfg.Format, cfg.OutputFile),
+		parser:       parser.NewParser(),
+		executor:     executor.NewHTTPExecutor(cfg.Timeout, opts...),
+		validator:    validator.NewResponseValidator(),
+		reporter:     reporter.NewReporter(cfg.Format, cfg.OutputFile),
+		rateLimiters: rateLimiters,
+		maxFailures:  cfg.MaxFailures,
+		maxBodySize:  cfg.MaxBodySize,
 	}
 }
 
@@ -78,38 +129,70 @@ func (c *Client) ValidateDirectory(dir string) error {
 	return err
 }
 
-// RunTestFile executes tests from a single YAML file
+// RunTestFile executes tests from a single YAML file.
+//
+// Deprecated: Use RunTestWithContext, which can be cancelled.
 func (c *Client) RunTestFile(filename string) (*SuiteResult, error) {
+	return c.RunTestWithContext(context.Background(), filename)
+}
+
+// RunTestDirectory executes all tests from YAML files in a directory.
+//
+// Deprecated: Use RunTestDirectoryWithContext, which can be cancelled.
+func (c *Client) RunTestDirectory(dir string) (*SuiteResult, error) {
+	return c.RunTestDirectoryWithContext(context.Background(), dir)
+}
+
+// RunTestWithContext executes tests from a single YAML file. If ctx is
+// cancelled mid-run, in-flight requests are aborted and the partial result
+// is returned with Interrupted set and the remaining tests marked as
+// skipped.
+func (c *Client) RunTestWithContext(ctx context.Context, filename string) (*SuiteResult, error) {
 	sentinelTest, err := c.parser.ParseFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.runTests(sentinelTest)
+	return c.runTests(ctx, sentinelTest)
 }
 
-// RunTestDirectory executes all tests from YAML files in a directory
-func (c *Client) RunTestDirectory(dir string) (*SuiteResult, error) {
+// RunTestDirectoryWithContext executes all tests from YAML files in a
+// directory, stopping early when ctx is cancelled
+func (c *Client) RunTestDirectoryWithContext(ctx context.Context, dir string) (*SuiteResult, error) {
+	combinedTest, err := c.combineDirectory(dir)
+	if err != nil {
+		return nil, err
+	}
+	if combinedTest == nil {
+		return &SuiteResult{
+			SuiteName: "Empty Directory",
+		}, nil
+	}
+
+	return c.runTests(ctx, combinedTest)
+}
+
+// combineDirectory parses a directory and merges its tests into a single
+// suite. It returns nil when the directory holds no test files.
+func (c *Client) combineDirectory(dir string) (*config.SentinelTest, error) {
 	sentinelTests, err := c.parser.ParseDirectory(dir)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(sentinelTests) == 0 {
+		return nil, nil
+	}
+
 	// Combine all tests into a single suite
 	var allTests []config.Test
 	for _, sentinelTest := range sentinelTests {
 		allTests = append(allTests, sentinelTest.Spec.Tests...)
 	}
 
-	if len(sentinelTests) == 0 {
-		return &SuiteResult{
-			SuiteName: "Empty Directory",
-		}, nil
-	}
-
 	// Use the first test's target configuration
 	// In a real implementation, you might want to handle multiple targets differently
-	combinedTest := &config.SentinelTest{
+	return &config.SentinelTest{
 		Metadata: config.Metadata{
 			Name: "Combined Tests",
 		},
@@ -117,119 +200,106 @@ func (c *Client) RunTestDirectory(dir string) (*SuiteResult, error) {
 			Target: sentinelTests[0].Spec.Target,
 			Tests:  allTests,
 		},
-	}
-
-	return c.runTests(combinedTest)
+	}, nil
 }
 
-// RunTestWithContext executes tests with a context for cancellation
-func (c *Client) RunTestWithContext(ctx context.Context, filename string) (*SuiteResult, error) {
-	sentinelTest, err := c.parser.ParseFile(filename)
-	if err != nil {
+// runTests executes the tests of a suite until ctx is cancelled
+func (c *Client) runTests(ctx context.Context, sentinelTest *config.SentinelTest) (*SuiteResult, error) {
+	start := time.Now()
+	var testResults []TestResult
+
+	target := sentinelTest.Spec.Target
+	if c.maxBodySize > 0 {
+		target.MaxBodySize = c.maxBodySize
+	}
+	if err := c.rateLimiters.Configure(target); err != nil {
 		return nil, err
 	}
 
-	return c.runTestsWithContext(ctx, sentinelTest)
-}
-
-// runTests executes the actual test logic
-func (c *Client) runTests(sentinelTest *config.SentinelTest) (*SuiteResult, error) {
-	start := time.Now()
-	var testResults []TestResult
+	runCtx, cancelRun := context.WithCancelCause(ctx)
+	defer cancelRun(nil)
+	failures := 0
+	testRunner := runner.NewRunner(c.executor, c.validator, c.reporter)
 
 	for _, test := range sentinelTest.Spec.Tests {
-		testStart := time.Now()
-
-		response, err := c.executor.ExecuteTest(&test, sentinelTest.Spec.Target.BaseURL)
-		if err != nil {
+		if runCtx.Err() != nil {
 			testResults = append(testResults, TestResult{
 				TestName: test.Name,
-				Passed:   false,
-				Duration: time.Since(testStart),
-				Errors:   []string{err.Error()},
+				Skipped:  true,
 			})
 			continue
 		}
 
-		validation := c.validator.Validate(response, &test.Expected, test.Name)
-
-		testResults = append(testResults, TestResult{
-			TestName: test.Name,
-			Passed:   validation.Passed,
-			Duration: time.Since(testStart),
-			Errors:   validation.Errors,
-			Warnings: validation.Warnings,
-		})
-	}
-
-	// Calculate summary
-	passed := 0
-	for _, result := range testResults {
-		if result.Passed {
-			passed++
-		}
-	}
-
-	return &SuiteResult{
-		SuiteName:   sentinelTest.Metadata.Name,
-		TotalTests:  len(testResults),
-		PassedTests: passed,
-		FailedTests: len(testResults) - passed,
-		Duration:    time.Since(start),
-		TestResults: testResults,
-	}, nil
-}
-
-// runTestsWithContext executes tests with context support
-func (c *Client) runTestsWithContext(ctx context.Context, sentinelTest *config.SentinelTest) (*SuiteResult, error) {
-	start := time.Now()
-	var testResults []TestResult
-
-	for _, test := range sentinelTest.Spec.Tests {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
-
 		testStart := time.Now()
 
-		response, err := c.executor.ExecuteTestWithContext(ctx, &test, sentinelTest.Spec.Target.BaseURL)
+		report, err := testRunner.RunTest(runCtx, &test, target)
 		if err != nil {
+			if runCtx.Err() != nil {
+				testResults = append(testResults, TestResult{
+					TestName: test.Name,
+					Skipped:  true,
+					Duration: time.Since(testStart),
+				})
+				continue
+			}
 			testResults = append(testResults, TestResult{
 				TestName: test.Name,
 				Passed:   false,
 				Duration: time.Since(testStart),
 				Errors:   []string{err.Error()},
 			})
+			failures++
+			c.checkFailureLimit(failures, cancelRun)
 			continue
 		}
 
-		validation := c.validator.Validate(response, &test.Expected, test.Name)
-
 		testResults = append(testResults, TestResult{
 			TestName: test.Name,
-			Passed:   validation.Passed,
+			Passed:   report.ValidationResult.Passed,
+			Flaky:    report.Status == reporter.StatusFlaky,
+			Attempts: len(report.Attempts),
 			Duration: time.Since(testStart),
-			Errors:   validation.Errors,
-			Warnings: validation.Warnings,
+			Errors:   report.ValidationResult.Errors,
+			Warnings: report.ValidationResult.Warnings,
 		})
+		if !report.ValidationResult.Passed {
+			failures++
+			c.checkFailureLimit(failures, cancelRun)
+		}
 	}
 
 	// Calculate summary
 	passed := 0
+	flaky := 0
+	skipped := 0
 	for _, result := range testResults {
 		if result.Passed {
 			passed++
+			if result.Flaky {
+				flaky++
+			}
+		} else if result.Skipped {
+			skipped++
 		}
 	}
 
 	return &SuiteResult{
-		SuiteName:   sentinelTest.Metadata.Name,
-		TotalTests:  len(testResults),
-		PassedTests: passed,
-		FailedTests: len(testResults) - passed,
-		Duration:    time.Since(start),
-		TestResults: testResults,
+		SuiteName:    sentinelTest.Metadata.Name,
+		TotalTests:   len(testResults),
+		PassedTests:  passed,
+		FailedTests:  len(testResults) - passed - skipped,
+		FlakyTests:   flaky,
+		SkippedTests: skipped,
+		Interrupted:  ctx.Err() != nil,
+		StoppedEarly: errors.Is(context.Cause(runCtx), errFailureLimitReached),
+		Duration:     time.Since(start),
+		TestResults:  testResults,
 	}, nil
-}
\ No newline at end of file
+}
+
+// checkFailureLimit cancels the run once failures reaches MaxFailures
+func (c *Client) checkFailureLimit(failures int, cancel context.CancelCauseFunc) {
+	if c.maxFailures > 0 && failures >= c.maxFailures {
+		cancel(errFailureLimitReached)
+	}
+}
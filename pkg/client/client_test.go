@@ -0,0 +1,292 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSuite writes a suite to dir whose tests request the given paths
+// from baseURL, each expecting a 200.
+func writeSuite(t *testing.T, dir, name, baseURL string, paths ...string) string {
+	t.Helper()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "apiVersion: sentinel-test/v1\nkind: SentinelTest\nmetadata:\n  name: %s\nspec:\n  target:\n    baseUrl: %s\n  tests:\n", name, baseURL)
+	for i, path := range paths {
+		fmt.Fprintf(&b, "    - name: %s-%d\n      request:\n        method: GET\n        path: %s\n      expected:\n        status: [200]\n", name, i+1, path)
+	}
+
+	file := filepath.Join(dir, name+".yaml")
+	if err := os.WriteFile(file, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// roundTripFunc serves requests in-process.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// countingTransport answers every request with a 200 and counts them.
+func countingTransport(count *int) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		*count++
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Proto:      "HTTP/1.1",
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	})
+}
+
+// statusServer answers /fail with 500 and every other path with 200.
+func statusServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestRunTestWithContextInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The second request interrupts the run and hangs until it is aborted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/interrupt" {
+			cancel()
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	file := writeSuite(t, t.TempDir(), "interrupted", server.URL, "/ok", "/interrupt", "/ok")
+	result, err := NewClient(Config{}).RunTestWithContext(ctx, file)
+	if err != nil {
+		t.Fatalf("RunTestWithContext() failed: %v", err)
+	}
+
+	if !result.Interrupted || result.StoppedEarly {
+		t.Errorf("Interrupted = %v, StoppedEarly = %v, want true and false", result.Interrupted, result.StoppedEarly)
+	}
+	if result.TotalTests != 3 || result.PassedTests != 1 || result.FailedTests != 0 || result.SkippedTests != 2 {
+		t.Errorf("total/passed/failed/skipped = %d/%d/%d/%d, want 3/1/0/2",
+			result.TotalTests, result.PassedTests, result.FailedTests, result.SkippedTests)
+	}
+	for i, want := range []bool{false, true, true} {
+		if result.TestResults[i].Skipped != want {
+			t.Errorf("test %d Skipped = %v, want %v", i+1, result.TestResults[i].Skipped, want)
+		}
+	}
+}
+
+func TestRunTestWithContextCancelledBeforeStart(t *testing.T) {
+	server := statusServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	file := writeSuite(t, t.TempDir(), "never-ran", server.URL, "/ok", "/ok")
+	result, err := NewClient(Config{}).RunTestWithContext(ctx, file)
+	if err != nil {
+		t.Fatalf("RunTestWithContext() failed: %v", err)
+	}
+
+	if !result.Interrupted || result.SkippedTests != 2 || result.PassedTests != 0 || result.FailedTests != 0 {
+		t.Errorf("Interrupted = %v, passed/failed/skipped = %d/%d/%d, want every test skipped",
+			result.Interrupted, result.PassedTests, result.FailedTests, result.SkippedTests)
+	}
+}
+
+func TestRunTestWithContextFailureLimit(t *testing.T) {
+	server := statusServer()
+	defer server.Close()
+
+	file := writeSuite(t, t.TempDir(), "failing", server.URL, "/ok", "/fail", "/fail", "/ok", "/fail")
+
+	tests := []struct {
+		name             string
+		cfg              Config
+		wantFailed       int
+		wantSkipped      int
+		wantStoppedEarly bool
+	}{
+		{name: "no limit", cfg: Config{}, wantFailed: 3},
+		{name: "fail fast", cfg: Config{FailFast: true}, wantFailed: 1, wantSkipped: 3, wantStoppedEarly: true},
+		{name: "max failures", cfg: Config{MaxFailures: 2}, wantFailed: 2, wantSkipped: 2, wantStoppedEarly: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewClient(tt.cfg).RunTestWithContext(context.Background(), file)
+			if err != nil {
+				t.Fatalf("RunTestWithContext() failed: %v", err)
+			}
+
+			if result.FailedTests != tt.wantFailed || result.SkippedTests != tt.wantSkipped {
+				t.Errorf("failed/skipped = %d/%d, want %d/%d", result.FailedTests, result.SkippedTests, tt.wantFailed, tt.wantSkipped)
+			}
+			if result.StoppedEarly != tt.wantStoppedEarly || result.Interrupted {
+				t.Errorf("StoppedEarly = %v, Interrupted = %v, want %v and false", result.StoppedEarly, result.Interrupted, tt.wantStoppedEarly)
+			}
+		})
+	}
+}
+
+func TestRunTestWithContextFailureLimitCountsExecutionErrors(t *testing.T) {
+	server := statusServer()
+	server.Close()
+
+	// Nothing listens on the target any more, so every request fails
+	file := writeSuite(t, t.TempDir(), "unreachable", server.URL, "/ok", "/ok", "/ok")
+	result, err := NewClient(Config{FailFast: true}).RunTestWithContext(context.Background(), file)
+	if err != nil {
+		t.Fatalf("RunTestWithContext() failed: %v", err)
+	}
+
+	if !result.StoppedEarly || result.FailedTests != 1 || result.SkippedTests != 2 {
+		t.Errorf("StoppedEarly = %v, failed/skipped = %d/%d, want true and 1/2", result.StoppedEarly, result.FailedTests, result.SkippedTests)
+	}
+	if len(result.TestResults[0].Errors) == 0 {
+		t.Error("failed test has no errors")
+	}
+}
+
+func TestNewClientTransportOptions(t *testing.T) {
+	// No server listens on the target, so only an in-process transport can
+	// make the tests pass
+	file := writeSuite(t, t.TempDir(), "in-process", "http://waf.invalid", "/a", "/b")
+
+	var transportRequests, clientRequests int
+	tests := []struct {
+		name          string
+		cfg           func() Config
+		wantTransport int
+		wantClient    int
+	}{
+		{
+			name:          "transport",
+			cfg:           func() Config { return Config{Transport: countingTransport(&transportRequests)} },
+			wantTransport: 2,
+		},
+		{
+			name:       "http client",
+			cfg:        func() Config { return Config{HTTPClient: &http.Client{Transport: countingTransport(&clientRequests)}} },
+			wantClient: 2,
+		},
+		{
+			name: "transport replaces the http client's",
+			cfg: func() Config {
+				return Config{
+					HTTPClient: &http.Client{Transport: countingTransport(&clientRequests)},
+					Transport:  countingTransport(&transportRequests),
+				}
+			},
+			wantTransport: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transportRequests, clientRequests = 0, 0
+
+			cfg := tt.cfg()
+			result, err := NewClient(cfg).RunTestWithContext(context.Background(), file)
+			if err != nil {
+				t.Fatalf("RunTestWithContext() failed: %v", err)
+			}
+
+			if result.PassedTests != 2 {
+				t.Errorf("PassedTests = %d, want 2: %+v", result.PassedTests, result.TestResults)
+			}
+			if transportRequests != tt.wantTransport || clientRequests != tt.wantClient {
+				t.Errorf("transport/client requests = %d/%d, want %d/%d", transportRequests, clientRequests, tt.wantTransport, tt.wantClient)
+			}
+			if cfg.HTTPClient != nil && cfg.HTTPClient.Timeout != 0 {
+				t.Errorf("caller's client was modified: timeout = %v", cfg.HTTPClient.Timeout)
+			}
+		})
+	}
+}
+
+func TestRunTestDirectoryWithContext(t *testing.T) {
+	server := statusServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeSuite(t, dir, "first", server.URL, "/ok", "/fail")
+	writeSuite(t, dir, "second", server.URL, "/ok")
+
+	result, err := NewClient(Config{}).RunTestDirectoryWithContext(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("RunTestDirectoryWithContext() failed: %v", err)
+	}
+	if result.TotalTests != 3 || result.PassedTests != 2 || result.FailedTests != 1 {
+		t.Errorf("total/passed/failed = %d/%d/%d, want 3/2/1", result.TotalTests, result.PassedTests, result.FailedTests)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err = NewClient(Config{}).RunTestDirectoryWithContext(ctx, dir)
+	if err != nil {
+		t.Fatalf("RunTestDirectoryWithContext() failed: %v", err)
+	}
+	if !result.Interrupted || result.SkippedTests != 3 {
+		t.Errorf("Interrupted = %v, SkippedTests = %d, want true and 3", result.Interrupted, result.SkippedTests)
+	}
+
+	result, err = NewClient(Config{}).RunTestDirectoryWithContext(context.Background(), t.TempDir())
+	if err != nil {
+		t.Fatalf("RunTestDirectoryWithContext() failed on an empty directory: %v", err)
+	}
+	if result.TotalTests != 0 {
+		t.Errorf("TotalTests = %d for an empty directory", result.TotalTests)
+	}
+}
+
+func TestDeprecatedRunMethods(t *testing.T) {
+	server := statusServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	file := writeSuite(t, dir, "suite", server.URL, "/ok", "/fail")
+	client := NewClient(Config{})
+
+	fileResult, err := client.RunTestFile(file)
+	if err != nil {
+		t.Fatalf("RunTestFile() failed: %v", err)
+	}
+	dirResult, err := client.RunTestDirectory(dir)
+	if err != nil {
+		t.Fatalf("RunTestDirectory() failed: %v", err)
+	}
+
+	for name, result := range map[string]*SuiteResult{"RunTestFile": fileResult, "RunTestDirectory": dirResult} {
+		if result.TotalTests != 2 || result.PassedTests != 1 || result.FailedTests != 1 || result.Interrupted {
+			t.Errorf("%s() total/passed/failed = %d/%d/%d, Interrupted = %v, want 2/1/1 and false",
+				name, result.TotalTests, result.PassedTests, result.FailedTests, result.Interrupted)
+		}
+	}
+
+	if _, err := client.RunTestFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("RunTestFile() accepted a missing file")
+	}
+}
@@ -14,6 +14,7 @@ type SentinelTestConfig struct {
 // Metadata contains test metadata
 type Metadata struct {
 	Name        string `yaml:"name" json:"name"`
+	Version     string `yaml:"version,omitempty" json:"version,omitempty"`
 	Description string `yaml:"description,omitempty" json:"description,omitempty"`
 }
 
@@ -25,15 +26,54 @@ type Spec struct {
 
 // Target defines the target endpoint configuration
 type Target struct {
-	BaseURL string        `yaml:"baseUrl" json:"baseUrl"`
-	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	BaseURL     string        `yaml:"baseUrl" json:"baseUrl"`
+	Timeout     time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Retry       *Retry        `yaml:"retry,omitempty" json:"retry,omitempty"`
+	RateLimit   *RateLimit    `yaml:"rateLimit,omitempty" json:"rateLimit,omitempty"`
+	BlockStatus []int         `yaml:"blockStatus,omitempty" json:"blockStatus,omitempty"`
+	MaxBodySize int64         `yaml:"maxBodySize,omitempty" json:"maxBodySize,omitempty"`
+}
+
+// RateLimit defines the maximum request rate sent to a target host
+type RateLimit struct {
+	RPS   float64 `yaml:"rps" json:"rps"`
+	Burst int     `yaml:"burst,omitempty" json:"burst,omitempty"`
 }
 
 // Test defines a single test case
 type Test struct {
-	Name     string   `yaml:"name" json:"name"`
-	Request  Request  `yaml:"request" json:"request"`
-	Expected Expected `yaml:"expected" json:"expected"`
+	Name           string          `yaml:"name" json:"name"`
+	Tags           []string        `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Request        Request         `yaml:"request" json:"request"`
+	Expected       Expected        `yaml:"expected" json:"expected"`
+	Retry          *Retry          `yaml:"retry,omitempty" json:"retry,omitempty"`
+	RateLimitCheck *RateLimitCheck `yaml:"rateLimitCheck,omitempty" json:"rateLimitCheck,omitempty"`
+	Inject         *Inject         `yaml:"inject,omitempty" json:"inject,omitempty"`
+	Transforms     []string        `yaml:"transforms,omitempty" json:"transforms,omitempty"`
+	Debug          bool            `yaml:"debug,omitempty" json:"debug,omitempty"`
+}
+
+// Inject expands a test into one test per payload and location
+type Inject struct {
+	Payloads  []string `yaml:"payloads" json:"payloads"`
+	Locations []string `yaml:"locations" json:"locations"`
+}
+
+// RateLimitCheck defines a rate-based rule verification test
+type RateLimitCheck struct {
+	Requests    int           `yaml:"requests" json:"requests"`
+	RPS         float64       `yaml:"rps,omitempty" json:"rps,omitempty"`
+	Concurrency int           `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	BlockWithin int           `yaml:"blockWithin,omitempty" json:"blockWithin,omitempty"`
+	Window      time.Duration `yaml:"window,omitempty" json:"window,omitempty"`
+}
+
+// Retry defines how failed test attempts are retried
+type Retry struct {
+	Retries             int           `yaml:"retries" json:"retries"`
+	Backoff             time.Duration `yaml:"backoff,omitempty" json:"backoff,omitempty"`
+	MaxBackoff          time.Duration `yaml:"maxBackoff,omitempty" json:"maxBackoff,omitempty"`
+	OnValidationFailure bool          `yaml:"onValidationFailure,omitempty" json:"onValidationFailure,omitempty"`
 }
 
 // Request defines the HTTP request configuration
@@ -46,9 +86,29 @@ type Request struct {
 
 // Expected defines the expected response validation criteria
 type Expected struct {
-	Status  []int             `yaml:"status" json:"status"`
-	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
-	Body    *BodyExpected     `yaml:"body,omitempty" json:"body,omitempty"`
+	Status   []int             `yaml:"status,omitempty" json:"status,omitempty"`
+	Blocked  *bool             `yaml:"blocked,omitempty" json:"blocked,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Body     *BodyExpected     `yaml:"body,omitempty" json:"body,omitempty"`
+	Upstream *Upstream         `yaml:"upstream,omitempty" json:"upstream,omitempty"`
+	Latency  *Latency          `yaml:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// Upstream defines which parts of an allowed request must reach the origin unchanged
+type Upstream struct {
+	Path   bool     `yaml:"path,omitempty" json:"path,omitempty"`
+	Header []string `yaml:"header,omitempty" json:"header,omitempty"`
+	Body   bool     `yaml:"body,omitempty" json:"body,omitempty"`
+}
+
+// Latency defines upper bounds on the response time and its phases
+type Latency struct {
+	Total    time.Duration `yaml:"total,omitempty" json:"total,omitempty"`
+	DNS      time.Duration `yaml:"dns,omitempty" json:"dns,omitempty"`
+	Connect  time.Duration `yaml:"connect,omitempty" json:"connect,omitempty"`
+	TLS      time.Duration `yaml:"tls,omitempty" json:"tls,omitempty"`
+	TTFB     time.Duration `yaml:"ttfb,omitempty" json:"ttfb,omitempty"`
+	Transfer time.Duration `yaml:"transfer,omitempty" json:"transfer,omitempty"`
 }
 
 // BodyExpected defines body validation criteria
@@ -64,6 +124,7 @@ type TestResult struct {
 	TestName string        `json:"test_name"`
 	Status   string        `json:"status"`
 	Passed   bool          `json:"passed"`
+	Attempts int           `json:"attempts,omitempty"`
 	Duration time.Duration `json:"duration"`
 	Errors   []string      `json:"errors,omitempty"`
 	Warnings []string      `json:"warnings,omitempty"`
@@ -71,21 +132,30 @@ type TestResult struct {
 
 // SuiteResult represents the result of a test suite execution
 type SuiteResult struct {
-	SuiteName   string        `json:"suite_name"`
-	TotalTests  int           `json:"total_tests"`
-	PassedTests int           `json:"passed_tests"`
-	FailedTests int           `json:"failed_tests"`
-	Duration    time.Duration `json:"duration"`
-	Tests       []TestResult  `json:"tests"`
-	Timestamp   time.Time     `json:"timestamp"`
+	SuiteName    string        `json:"suite_name"`
+	TotalTests   int           `json:"total_tests"`
+	PassedTests  int           `json:"passed_tests"`
+	FailedTests  int           `json:"failed_tests"`
+	FlakyTests   int           `json:"flaky_tests"`
+	SkippedTests int           `json:"skipped_tests"`
+	Interrupted  bool          `json:"interrupted"`
+	StoppedEarly bool          `json:"stopped_early"`
+	Duration     time.Duration `json:"duration"`
+	Tests        []TestResult  `json:"tests"`
+	Timestamp    time.Time     `json:"timestamp"`
 }
 
 // ClientConfig represents configuration for the Sentinel testing client
 type ClientConfig struct {
-	Timeout    time.Duration `json:"timeout,omitempty"`
-	OutputFile string        `json:"output_file,omitempty"`
-	Format     string        `json:"format,omitempty"` // "json" or "text"
-	Concurrent int           `json:"concurrent,omitempty"`
-	LogLevel   string        `json:"log_level,omitempty"`
-	LogFormat  string        `json:"log_format,omitempty"`
+	Timeout     time.Duration `json:"timeout,omitempty"`
+	OutputFile  string        `json:"output_file,omitempty"`
+	Format      string        `json:"format,omitempty"` // "json", "text" or "har"
+	Concurrent  int           `json:"concurrent,omitempty"`
+	FailFast    bool          `json:"fail_fast,omitempty"`
+	MaxFailures int           `json:"max_failures,omitempty"`
+	RPS         float64       `json:"rps,omitempty"`
+	Burst       int           `json:"burst,omitempty"`
+	MaxBodySize int64         `json:"max_body_size,omitempty"`
+	LogLevel    string        `json:"log_level,omitempty"`
+	LogFormat   string        `json:"log_format,omitempty"`
 }
\ No newline at end of file